@@ -1,17 +1,14 @@
 package main
 
 import (
-	"archive/tar"
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/blang/semver"
-	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/mattermost/mattermost-marketplace/internal/bundle"
 	"github.com/mattermost/mattermost-marketplace/internal/model"
 )
 
@@ -123,25 +120,14 @@ var addCmd = &cobra.Command{
 			return errors.Wrapf(err, "failed downloading bundle data")
 		}
 
-		manifestData, err := getFromTarFile(tar.NewReader(bytes.NewReader(bundleData)), "plugin.json")
+		manifest, err := bundle.GetManifestFromTarFile(bundleData)
 		if err != nil {
-			return errors.Wrap(err, "failed to read manifest from plugin bundle for release")
-		}
-
-		var manifest mattermostModel.Manifest
-		err = json.Unmarshal(manifestData, &manifest)
-		if err != nil {
-			return errors.Wrap(err, "failed to read manifest from plugin bundle for release")
-		}
-
-		err = manifest.IsValid()
-		if err != nil {
-			return errors.Wrap(err, "manifest is invalid")
+			return errors.Wrap(err, "failed to read manifest for release")
 		}
 
 		var iconData string
 		if manifest.IconPath != "" {
-			iconData, err = getIconDataFromTarFile(bundleData, manifest.IconPath)
+			iconData, err = bundle.GetIconDataFromTarFile(bundleData, manifest.IconPath)
 			if err != nil {
 				return errors.Wrap(err, "failed to get icon")
 			}
@@ -162,7 +148,7 @@ var addCmd = &cobra.Command{
 			ReleaseNotesURL: manifest.ReleaseNotesURL,
 			Labels:          labels,
 			Signature:       signature,
-			Manifest:        &manifest,
+			Manifest:        manifest,
 			Enterprise:      enterprise,
 			UpdatedAt:       time.Now().In(time.UTC),
 		}
@@ -56,12 +56,12 @@ var migrateCmd = &cobra.Command{
 				// Migrate community label to flag
 				var newLabels []model.Label
 				for _, l := range modified.Labels {
-					switch l {
-					case model.EnterpriseLabel:
+					switch l.Name {
+					case model.EnterpriseLabel.Name:
 						// Just drop it
-					case model.CommunityLabel:
+					case model.CommunityLabel.Name:
 						modified.AuthorType = model.Community
-					case model.BetaLabel:
+					case model.BetaLabel.Name:
 						modified.ReleaseStage = model.Beta
 					default:
 						// Keep other labels
@@ -1,30 +1,24 @@
 package main
 
 import (
-	"archive/tar"
-	"bytes"
-	"compress/gzip"
 	"context"
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
-	"path"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/blang/semver"
 	"github.com/google/go-github/v28/github"
-	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"golang.org/x/oauth2"
 
+	"github.com/mattermost/mattermost-marketplace/internal/bundle"
 	"github.com/mattermost/mattermost-marketplace/internal/model"
 )
 
@@ -318,26 +312,14 @@ func getReleasePlugin(release *github.RepositoryRelease, repository *github.Repo
 			return nil, errors.Wrapf(err, "failed download bundle data for release %s", releaseName)
 		}
 
-		manifestData, err := getFromTarFile(tar.NewReader(bytes.NewReader(bundleData)), "plugin.json")
+		plugin.Manifest, err = bundle.GetManifestFromTarFile(bundleData)
 		if err != nil {
-			return nil, errors.Wrapf(err, "failed to read manifest from plugin bundle for release %s", releaseName)
-		}
-
-		var manifest mattermostModel.Manifest
-		err = json.Unmarshal(manifestData, &manifest)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to read manifest from plugin bundle for release %s", releaseName)
-		}
-		plugin.Manifest = &manifest
-
-		err = plugin.Manifest.IsValid()
-		if err != nil {
-			return nil, errors.Wrapf(err, "invalid manifest for release %s", releaseName)
+			return nil, errors.Wrapf(err, "failed to read manifest for release %s", releaseName)
 		}
 
 		if plugin.Manifest.IconPath != "" {
 			var iconData string
-			iconData, err = getIconDataFromTarFile(bundleData, plugin.Manifest.IconPath)
+			iconData, err = bundle.GetIconDataFromTarFile(bundleData, plugin.Manifest.IconPath)
 			if err != nil {
 				return nil, errors.Wrapf(err, "failed to set icon for release %s", releaseName)
 			}
@@ -370,35 +352,6 @@ func getReleasePlugin(release *github.RepositoryRelease, repository *github.Repo
 	return plugin, nil
 }
 
-func getFromTarFile(reader *tar.Reader, filepath string) ([]byte, error) {
-	for {
-		hdr, err := reader.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to read tar file")
-		}
-
-		// Match the filepath, assuming the tar file contains a leading folder matching the
-		// plugin id.
-		matched, err := path.Match(fmt.Sprintf("*/%s", filepath), hdr.Name)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to match file %s in tar file", filepath)
-		} else if !matched {
-			continue
-		}
-
-		data, err := ioutil.ReadAll(reader)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to read %s in tar file", filepath)
-		}
-		return data, nil
-	}
-
-	return nil, errors.Errorf("failed to find %s in tar file", filepath)
-}
-
 func downloadSignature(url string) (string, error) {
 	logger.Debugf("fetching signature file from %s", url)
 
@@ -430,26 +383,7 @@ func downloadBundleData(url string) ([]byte, error) {
 		return nil, errors.Errorf("received %d status code while downloading plugin bundle from %v", resp.StatusCode, url)
 	}
 
-	gzBundleReader, err := gzip.NewReader(resp.Body)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to read gzipped plugin bundle")
-	}
-
-	bundleData, err := ioutil.ReadAll(gzBundleReader)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to read plugin bundle")
-	}
-
-	return bundleData, nil
-}
-
-func getIconDataFromTarFile(file []byte, path string) (string, error) {
-	iconData, err := getFromTarFile(tar.NewReader(bytes.NewReader(file)), path)
-	if err != nil {
-		return "", errors.Wrapf(err, "failed to read icon data from plugin bundle for path %s", path)
-	}
-
-	return fmt.Sprintf("data:image/svg+xml;base64,%s", base64.StdEncoding.EncodeToString(iconData)), nil
+	return bundle.Decompress(resp.Body, bundle.MaxDecompressedSize)
 }
 
 // InitCommand parses the log level flag
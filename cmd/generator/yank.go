@@ -0,0 +1,98 @@
+package main
+
+import (
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+func init() {
+	generatorCmd.AddCommand(yankCmd)
+
+	yankCmd.Flags().String("reason", "", "Why the release is withdrawn, shown to anyone requesting it")
+	yankCmd.Flags().Bool("undo", false, "Restore a yanked release")
+}
+
+var yankCmd = &cobra.Command{
+	Use:   "yank [id] [version]",
+	Short: "Withdraw a plugin release in the plugins.json database",
+	Long: "The generator commands allows withdrawing a specific plugin release, such as for a security issue, by using this command.\n\n" +
+		"A yanked release is never selected as the latest version of a plugin and is omitted from listings, " +
+		"but is still served with a warning when requested explicitly.",
+	Example: `  generator yank com.github.matterpoll.matterpoll 1.5.1 --reason "Fails to start on Mattermost 6.0"
+  generator yank com.github.matterpoll.matterpoll 1.5.1 --undo`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		reason, err := command.Flags().GetString("reason")
+		if err != nil {
+			return err
+		}
+
+		undo, err := command.Flags().GetBool("undo")
+		if err != nil {
+			return err
+		}
+
+		if undo && reason != "" {
+			return errors.New("can't give a reason when restoring a release")
+		}
+		if !undo && reason == "" {
+			return errors.New("you must give a reason for yanking the release")
+		}
+
+		id := args[0]
+		version, err := semver.ParseTolerant(args[1])
+		if err != nil {
+			return errors.Wrapf(err, "%v is an invalid version. Something like 2.3.4 is expected", args[1])
+		}
+
+		dbFile, err := command.Flags().GetString("database")
+		if err != nil {
+			return err
+		}
+
+		plugins, err := pluginsFromDatabase(dbFile)
+		if err != nil {
+			return errors.Wrap(err, "failed to read plugins from database")
+		}
+
+		found := false
+		for _, plugin := range plugins {
+			if plugin.Manifest.Id != id {
+				continue
+			}
+
+			pluginVersion, err := semver.Parse(plugin.Manifest.Version)
+			if err != nil || !pluginVersion.EQ(version) {
+				continue
+			}
+
+			found = true
+			if undo {
+				plugin.Yanked = nil
+			} else {
+				plugin.Yanked = &model.Yank{
+					Reason:   reason,
+					YankedAt: time.Now().In(time.UTC),
+				}
+			}
+		}
+
+		if !found {
+			return errors.Errorf("version %s of plugin %s not found in database", version, id)
+		}
+
+		err = pluginsToDatabase(dbFile, plugins)
+		if err != nil {
+			return errors.Wrap(err, "failed to write plugins database")
+		}
+
+		return nil
+	},
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/mattermost/mattermost-marketplace/internal/api"
+	"github.com/mattermost/mattermost-marketplace/internal/model"
 	"github.com/mattermost/mattermost-marketplace/internal/store"
 )
 
@@ -19,6 +20,9 @@ var (
 
 	//go:embed plugins.json
 	database []byte
+
+	//go:embed advisories.json
+	advisoriesDatabase []byte
 )
 
 var logger *logrus.Logger
@@ -31,7 +35,7 @@ func main() {
 }
 
 func newStaticStore(logger logrus.FieldLogger) (*store.StaticStore, error) {
-	staticStore, err := store.NewStaticFromReader(bytes.NewReader(database), logger)
+	staticStore, err := store.NewStaticFromReader(bytes.NewReader(database), model.DefaultLabelDefinitions, logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to initialize store")
 	}
@@ -39,6 +43,15 @@ func newStaticStore(logger logrus.FieldLogger) (*store.StaticStore, error) {
 	return staticStore, nil
 }
 
+func newStaticAdvisories() (*store.StaticAdvisories, error) {
+	advisories, err := store.NewStaticAdvisoriesFromReader(bytes.NewReader(advisoriesDatabase))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to initialize advisories")
+	}
+
+	return advisories, nil
+}
+
 func listenAndServe() error {
 	logger = logrus.New()
 
@@ -58,10 +71,17 @@ func listenAndServe() error {
 		apiStore = store.NewMerged(logger, apiStore, upstreamStore)
 	}
 
+	advisories, err := newStaticAdvisories()
+	if err != nil {
+		return err
+	}
+
 	router := mux.NewRouter()
 	api.Register(router, &api.Context{
-		Store:  apiStore,
-		Logger: logger,
+		Store:      apiStore,
+		Labels:     model.DefaultLabelDefinitions,
+		Advisories: advisories,
+		Logger:     logger,
 	})
 
 	algnhsa.ListenAndServe(router, &algnhsa.Options{
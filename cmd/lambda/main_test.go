@@ -10,3 +10,8 @@ func TestNewStaticStore(t *testing.T) {
 	_, err := newStaticStore(logger)
 	require.NoError(t, err)
 }
+
+func TestNewStaticAdvisories(t *testing.T) {
+	_, err := newStaticAdvisories()
+	require.NoError(t, err)
+}
@@ -2,10 +2,15 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"path"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -15,9 +20,16 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/mattermost/mattermost-marketplace/internal/api"
+	"github.com/mattermost/mattermost-marketplace/internal/bundle"
+	marketplacemodel "github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/stats"
 	"github.com/mattermost/mattermost-marketplace/internal/store"
+	"github.com/mattermost/mattermost-marketplace/internal/webhook"
 )
 
+// requestTimeout bounds reading and responding to each request, other than bundle transfers.
+const requestTimeout = 10 * time.Second
+
 var (
 	instanceID string
 
@@ -28,10 +40,27 @@ var (
 func init() {
 	instanceID = model.NewId()
 
-	serverCmd.PersistentFlags().String("database", "plugins.json", "The read-only JSON file backing the server.")
+	serverCmd.PersistentFlags().String("database", "plugins.json", "The JSON file backing the server, written only through the admin API.")
+	serverCmd.PersistentFlags().String("advisories", "advisories.json", "The JSON file listing security advisories affecting plugins, if it exists.")
 	serverCmd.PersistentFlags().String("listen", ":8085", "The interface and port on which to listen.")
 	serverCmd.PersistentFlags().String("upstream", upstreamURL, "An upstream marketplace server with which to merge results.")
+	serverCmd.PersistentFlags().Duration("upstream-timeout", 10*time.Second, "How long to wait for each request to the upstream marketplace server.")
 	serverCmd.PersistentFlags().Bool("debug", false, "Whether to output debug logs.")
+	serverCmd.PersistentFlags().String("stats-file", "", "A local file in which to persist download statistics, instead of only counting in memory. Each download is appended to the file, which is never compacted.")
+	serverCmd.PersistentFlags().StringSlice("author-type", nil, "Only serve plugins by one of these author types.")
+	serverCmd.PersistentFlags().StringSlice("release-stage", nil, "Only serve plugins in one of these release stages.")
+	serverCmd.PersistentFlags().StringSlice("hosting", nil, "Only serve plugins available for one of these hosting types.")
+	serverCmd.PersistentFlags().StringSlice("label", nil, "Only serve plugins with one of these labels.")
+	serverCmd.PersistentFlags().StringSlice("admin-token", nil, "A bearer token authorizing changes through the admin API, which is disabled otherwise. Defaults to the comma-separated $MARKETPLACE_ADMIN_TOKENS.")
+	serverCmd.PersistentFlags().String("api-keys", "", "A JSON file describing private plugins and the API keys granting access to them.")
+	serverCmd.PersistentFlags().String("labels", "", "A JSON file defining the labels assigned to plugins, in place of the defaults.")
+	serverCmd.PersistentFlags().String("tenants", "", "A JSON file describing tenants served their own catalog, resolved by host, path prefix or API key.")
+	serverCmd.PersistentFlags().String("bundle-dir", "", "A local directory in which to store bundles uploaded through the admin API, to be served by the marketplace itself.")
+	serverCmd.PersistentFlags().Duration("bundle-timeout", 10*time.Minute, "How long to allow for uploading or downloading a bundle served by the marketplace itself, in place of the 10 second limit on other requests.")
+	serverCmd.PersistentFlags().String("public-url", "", "The base URL at which clients reach the marketplace, from which the download URLs of uploaded bundles and the links in release feeds are built. Required with --bundle-dir.")
+	serverCmd.PersistentFlags().StringSlice("webhook-url", nil, "A URL to notify whenever the catalog changes.")
+	serverCmd.PersistentFlags().String("webhook-secret", "", "The secret with which to sign webhook payloads.")
+	serverCmd.PersistentFlags().Duration("webhook-interval", time.Minute, "How often to check the catalog for changes to announce.")
 }
 
 var serverCmd = &cobra.Command{
@@ -45,24 +74,39 @@ var serverCmd = &cobra.Command{
 			logger.SetLevel(logrus.DebugLevel)
 		}
 
+		labels := marketplacemodel.DefaultLabelDefinitions
+		labelsFile, _ := command.Flags().GetString("labels")
+		if labelsFile != "" {
+			var err error
+			labels, err = loadLabelDefinitions(labelsFile)
+			if err != nil {
+				return errors.Wrap(err, "failed to load labels")
+			}
+
+			logger.WithField("labels", len(labels)).Info("Assigning configured labels")
+		}
+
 		database, _ := command.Flags().GetString("database")
-		databaseFile, err := os.Open(database)
+		databaseStore, err := store.NewReloadable(func() (store.Store, error) {
+			return store.NewFile(database, labels, logger)
+		})
 		if err != nil {
-			return errors.Wrapf(err, "failed to open %s", database)
+			return errors.Wrap(err, "failed to initialize store")
 		}
-		defer databaseFile.Close()
 
-		var apiStore store.Store
-
-		apiStore, err = store.NewStaticFromReader(databaseFile, logger)
+		advisoriesFile, _ := command.Flags().GetString("advisories")
+		advisories, err := store.NewAdvisoriesFile(advisoriesFile)
 		if err != nil {
-			return errors.Wrap(err, "failed to initialize store")
+			return errors.Wrap(err, "failed to initialize advisories")
 		}
 
+		var apiStore store.Store = databaseStore
+
 		upstreamURL, _ := command.Flags().GetString("upstream")
 		if upstreamURL != "" {
 			var upstreamStore *store.Proxy
-			upstreamStore, err = store.NewProxy(upstreamURL, logger)
+			upstreamTimeout, _ := command.Flags().GetDuration("upstream-timeout")
+			upstreamStore, err = store.NewProxy(upstreamURL, logger, api.WithTimeout(upstreamTimeout))
 			if err != nil {
 				return errors.Wrap(err, "failed to initialize upstream store")
 			}
@@ -72,25 +116,176 @@ var serverCmd = &cobra.Command{
 			apiStore = store.NewMerged(logger, apiStore, upstreamStore)
 		}
 
+		restrictions, err := restrictionsFromFlags(command)
+		if err != nil {
+			return err
+		}
+		if restrictions != nil {
+			logger.WithFields(logrus.Fields{
+				"author_types":   restrictions.AuthorTypes,
+				"release_stages": restrictions.ReleaseStages,
+				"hosting":        restrictions.Hosting,
+				"labels":         restrictions.Labels,
+			}).Info("Restricting served plugins")
+
+			apiStore = store.NewRestricted(apiStore, restrictions)
+		}
+
+		var tenants []*tenant
+		tenantsFile, _ := command.Flags().GetString("tenants")
+		if tenantsFile != "" {
+			tenants, err = loadTenants(tenantsFile, apiStore, labels)
+			if err != nil {
+				return errors.Wrap(err, "failed to load tenants")
+			}
+
+			logger.WithField("tenants", len(tenants)).Info("Serving tenant catalogs")
+		}
+
+		tenantStores := make(map[string]store.Store, len(tenants))
+		for _, tenant := range tenants {
+			tenantStores[tenant.ID] = tenant.store
+		}
+
+		// Without API keys, every plugin is public.
+		publicStore := func(s store.Store) store.Store { return s }
+
+		var apiKeys []*api.APIKey
+		apiKeysFile, _ := command.Flags().GetString("api-keys")
+		if apiKeysFile != "" {
+			var privatePlugins []string
+			privatePlugins, apiKeys, err = loadAPIKeys(apiKeysFile, apiStore, tenantStores)
+			if err != nil {
+				return errors.Wrap(err, "failed to load API keys")
+			}
+
+			publicStore = func(s store.Store) store.Store {
+				return store.NewScoped(s, &store.Visibility{Private: privatePlugins}, logger)
+			}
+			apiStore = publicStore(apiStore)
+
+			logger.WithField("api_keys", len(apiKeys)).Info("Hiding private plugins from callers without an API key")
+		}
+
+		var apiStats api.Stats = stats.NewMemory()
+		statsFile, _ := command.Flags().GetString("stats-file")
+		if statsFile != "" {
+			fileStats, err := stats.NewFile(statsFile, logger)
+			if err != nil {
+				return errors.Wrap(err, "failed to initialize stats")
+			}
+			defer fileStats.Close()
+
+			logger.WithField("stats_file", statsFile).Info("Persisting download statistics")
+
+			apiStats = fileStats
+		}
+
+		adminTokens, _ := command.Flags().GetStringSlice("admin-token")
+		if len(adminTokens) == 0 && os.Getenv("MARKETPLACE_ADMIN_TOKENS") != "" {
+			adminTokens = strings.Split(os.Getenv("MARKETPLACE_ADMIN_TOKENS"), ",")
+		}
+		if len(adminTokens) > 0 {
+			logger.WithField("database", database).Info("Enabling admin API")
+		}
+
+		var bundles api.Bundles
+		var bundleTimeout time.Duration
+		bundleDir, _ := command.Flags().GetString("bundle-dir")
+		publicURL, _ := command.Flags().GetString("public-url")
+		if bundleDir != "" {
+			if publicURL == "" {
+				return errors.New("a public URL must be given with --public-url to serve uploaded bundles")
+			}
+
+			bundles, err = bundle.NewDirectory(bundleDir)
+			if err != nil {
+				return errors.Wrap(err, "failed to initialize bundle directory")
+			}
+
+			bundleTimeout, _ = command.Flags().GetDuration("bundle-timeout")
+			if bundleTimeout <= 0 {
+				return errors.New("bundle timeout must be positive")
+			}
+
+			logger.WithField("bundle_dir", bundleDir).WithField("public_url", publicURL).Info("Serving uploaded bundles")
+		}
+
 		logger := logger.WithField("instance", instanceID)
 		logger.Info("Starting Plugin Marketplace")
 
 		router := mux.NewRouter()
 
+		// Tenants are registered first, since the routes of the default catalog match any host.
+		for _, tenant := range tenants {
+			tenantContext := &api.Context{
+				Store:      publicStore(tenant.store),
+				Stats:      apiStats,
+				Labels:     labels,
+				Advisories: advisories,
+				APIKeys:    apiKeys,
+				Logger:     logger.WithField("tenant", tenant.ID),
+			}
+
+			for _, host := range tenant.Hosts {
+				api.Register(router.Host(host).Subrouter(), tenantContext)
+			}
+			if tenant.PathPrefix != "" {
+				api.Register(router.PathPrefix(tenant.PathPrefix).Subrouter(), tenantContext)
+			}
+		}
+
 		api.Register(router, &api.Context{
-			Store:  apiStore,
-			Logger: logger,
+			Store:       apiStore,
+			Stats:       apiStats,
+			Labels:      labels,
+			Advisories:  advisories,
+			AdminStore:  databaseStore,
+			AdminTokens: adminTokens,
+			Bundles:     bundles,
+			PublicURL:   publicURL,
+			APIKeys:     apiKeys,
+			Logger:      logger,
 		})
 
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		webhookURLs, _ := command.Flags().GetStringSlice("webhook-url")
+		if len(webhookURLs) > 0 {
+			webhookSecret, _ := command.Flags().GetString("webhook-secret")
+			webhookInterval, _ := command.Flags().GetDuration("webhook-interval")
+			if webhookInterval <= 0 {
+				return errors.New("webhook interval must be positive")
+			}
+
+			logger.WithFields(logrus.Fields{
+				"webhook_urls": webhookURLs,
+				"interval":     webhookInterval,
+			}).Info("Announcing catalog changes")
+
+			sender := webhook.NewSender(webhookURLs, webhookSecret, logger)
+			go webhook.NewWatcher(apiStore, sender, logger).Run(ctx, webhookInterval)
+		}
+
 		listen, _ := command.Flags().GetString("listen")
 		srv := &http.Server{
-			Addr:           listen,
-			Handler:        router,
-			ReadTimeout:    10 * time.Second,
-			WriteTimeout:   10 * time.Second,
-			IdleTimeout:    time.Second * 60,
-			MaxHeaderBytes: 1 << 20,
-			ErrorLog:       log.New(&logrusWriter{logger}, "", 0),
+			Addr:              listen,
+			Handler:           router,
+			ReadHeaderTimeout: requestTimeout,
+			ReadTimeout:       requestTimeout,
+			WriteTimeout:      requestTimeout,
+			IdleTimeout:       time.Second * 60,
+			MaxHeaderBytes:    1 << 20,
+			ErrorLog:          log.New(&logrusWriter{logger}, "", 0),
+		}
+
+		// Bundles may take far longer to transfer than other requests, whose handlers remain
+		// bounded by the usual timeout.
+		if bundles != nil {
+			srv.Handler = withRequestTimeout(router, requestTimeout)
+			srv.ReadTimeout = bundleTimeout
+			srv.WriteTimeout = bundleTimeout
 		}
 
 		go func() {
@@ -104,15 +299,45 @@ var serverCmd = &cobra.Command{
 		c := make(chan os.Signal, 1)
 		// We'll accept graceful shutdowns when quit via SIGINT (Ctrl+C)
 		// SIGKILL, SIGQUIT or SIGTERM (Ctrl+/) will not be caught.
-		signal.Notify(c, os.Interrupt)
+		// SIGHUP reloads the database and advisories instead.
+		signal.Notify(c, os.Interrupt, syscall.SIGHUP)
 
 		// Block until we receive our signal.
-		<-c
+		for sig := range c {
+			if sig != syscall.SIGHUP {
+				break
+			}
+
+			logger.WithField("database", database).Info("Reloading database")
+			err = databaseStore.Reload()
+			if err != nil {
+				logger.WithError(err).Error("Failed to reload database")
+			}
+
+			logger.WithField("advisories", advisoriesFile).Info("Reloading advisories")
+			err = advisories.Reload()
+			if err != nil {
+				logger.WithError(err).Error("Failed to reload advisories")
+			}
+
+			for _, tenant := range tenants {
+				if tenant.overlay == nil {
+					continue
+				}
+
+				logger.WithField("tenant", tenant.ID).WithField("database", tenant.Database).Info("Reloading tenant database")
+				err = tenant.overlay.Reload()
+				if err != nil {
+					logger.WithError(err).WithField("tenant", tenant.ID).Error("Failed to reload tenant database")
+				}
+			}
+		}
 		logger.Info("Shutting down")
+		cancel()
 
-		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-		defer cancel()
-		err = srv.Shutdown(ctx)
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer shutdownCancel()
+		err = srv.Shutdown(shutdownCtx)
 		if err != nil {
 			logger.WithField("err", err).Error("Failed to shutdown")
 		}
@@ -120,3 +345,159 @@ var serverCmd = &cobra.Command{
 		return nil
 	},
 }
+
+// isBundleTransfer reports whether the given request uploads or downloads a bundle served by the
+// marketplace itself.
+func isBundleTransfer(r *http.Request) bool {
+	return (r.Method == http.MethodPost && r.URL.Path == "/api/v1/admin/bundles") ||
+		(r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/v1/bundles/"))
+}
+
+// withRequestTimeout bounds the handling of each request other than bundle transfers to the given
+// timeout, responding with 503 Service Unavailable once exceeded.
+func withRequestTimeout(handler http.Handler, timeout time.Duration) http.Handler {
+	bounded := http.TimeoutHandler(handler, timeout, "")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isBundleTransfer(r) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		bounded.ServeHTTP(w, r)
+	})
+}
+
+// loadLabelDefinitions reads the label definitions from the given file.
+func loadLabelDefinitions(filename string) ([]*marketplacemodel.LabelDefinition, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open %s", filename)
+	}
+	defer file.Close()
+
+	return marketplacemodel.LabelDefinitionsFromReader(file)
+}
+
+// restrictionsFromFlags returns the facets to which every response is restricted, or nil if the
+// server should serve all plugins.
+func restrictionsFromFlags(command *cobra.Command) (*marketplacemodel.PluginFilter, error) {
+	restrictions := &marketplacemodel.PluginFilter{}
+
+	authorTypes, _ := command.Flags().GetStringSlice("author-type")
+	for _, value := range authorTypes {
+		authorType := marketplacemodel.AuthorType(strings.ToLower(value))
+		if !authorType.IsValid() {
+			return nil, errors.Errorf("unsupported author type %s", value)
+		}
+		restrictions.AuthorTypes = append(restrictions.AuthorTypes, authorType)
+	}
+
+	releaseStages, _ := command.Flags().GetStringSlice("release-stage")
+	for _, value := range releaseStages {
+		releaseStage := marketplacemodel.ReleaseStage(strings.ToLower(value))
+		if !releaseStage.IsValid() {
+			return nil, errors.Errorf("unsupported release stage %s", value)
+		}
+		restrictions.ReleaseStages = append(restrictions.ReleaseStages, releaseStage)
+	}
+
+	hosting, _ := command.Flags().GetStringSlice("hosting")
+	for _, value := range hosting {
+		hostingType := marketplacemodel.HostingType(strings.ToLower(value))
+		if !hostingType.IsValid() {
+			return nil, errors.Errorf("unsupported hosting %s", value)
+		}
+		restrictions.Hosting = append(restrictions.Hosting, hostingType)
+	}
+
+	restrictions.Labels, _ = command.Flags().GetStringSlice("label")
+
+	if len(restrictions.AuthorTypes) == 0 && len(restrictions.ReleaseStages) == 0 && len(restrictions.Hosting) == 0 && len(restrictions.Labels) == 0 {
+		return nil, nil
+	}
+
+	return restrictions, nil
+}
+
+// apiKeysConfig describes the private plugins served by the marketplace, and the API keys granting
+// access to them. Plugins are identified by patterns matching their id, using the syntax of
+// path.Match.
+type apiKeysConfig struct {
+	PrivatePlugins []string `json:"private_plugins"`
+	Keys           []struct {
+		ID      string   `json:"id"`
+		Secret  string   `json:"secret"`
+		Plugins []string `json:"plugins"` // The private plugins visible to callers with the key
+		Tenant  string   `json:"tenant"`  // The tenant whose catalog is served to callers, if any
+	} `json:"keys"`
+}
+
+// validatePatterns checks that each of the given plugin id patterns is well formed.
+func validatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.Wrapf(err, "invalid plugin pattern %s", pattern)
+		}
+	}
+
+	return nil
+}
+
+// loadAPIKeys reads the API keys from the given file, returning the patterns identifying private
+// plugins alongside a key for each configured key. Each key serves the private plugins it grants
+// from the store of its tenant, if any, or the given store otherwise.
+func loadAPIKeys(filename string, apiStore store.Store, tenantStores map[string]store.Store) ([]string, []*api.APIKey, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read %s", filename)
+	}
+
+	var config apiKeysConfig
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to parse %s", filename)
+	}
+
+	err = validatePatterns(config.PrivatePlugins)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seen := make(map[string]bool)
+	apiKeys := make([]*api.APIKey, 0, len(config.Keys))
+	for _, key := range config.Keys {
+		if key.ID == "" || key.Secret == "" {
+			return nil, nil, errors.New("every API key must have an id and secret")
+		}
+		if seen[key.ID] {
+			return nil, nil, errors.Errorf("duplicate API key %s", key.ID)
+		}
+		seen[key.ID] = true
+
+		err = validatePatterns(key.Plugins)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid API key %s", key.ID)
+		}
+
+		keyStore := apiStore
+		if key.Tenant != "" {
+			tenantStore, ok := tenantStores[key.Tenant]
+			if !ok {
+				return nil, nil, errors.Errorf("API key %s references unknown tenant %s", key.ID, key.Tenant)
+			}
+			keyStore = tenantStore
+		}
+
+		apiKeys = append(apiKeys, &api.APIKey{
+			ID:     key.ID,
+			Secret: key.Secret,
+			Store: store.NewScoped(keyStore, &store.Visibility{
+				Private: config.PrivatePlugins,
+				Granted: key.Plugins,
+			}, logger),
+		})
+	}
+
+	return config.PrivatePlugins, apiKeys, nil
+}
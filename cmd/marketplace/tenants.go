@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+
+	marketplacemodel "github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/store"
+)
+
+// tenantsConfig describes the tenants served their own catalog by a single marketplace, as read
+// from the file given by --tenants.
+type tenantsConfig struct {
+	Tenants []*tenantConfig `json:"tenants"`
+}
+
+// tenantConfig describes a single tenant, and how its catalog is composed from the shared
+// catalog served to every other caller.
+//
+// A tenant is resolved by any of its hosts, by requests prefixed with its path prefix, or by an
+// API key naming the tenant.
+type tenantConfig struct {
+	ID         string   `json:"id"`
+	Hosts      []string `json:"hosts"`
+	PathPrefix string   `json:"path_prefix"`
+
+	// Database is a JSON file of plugins only served to the tenant, merged over the shared
+	// catalog such that the tenant's releases are preferred.
+	Database string `json:"database"`
+
+	// Plugins are patterns matching the ids of the plugins approved for the tenant, using the
+	// syntax of path.Match. Every plugin is approved if empty.
+	Plugins []string `json:"plugins"`
+
+	// Pins are the versions served in place of the latest compatible version, keyed by plugin id.
+	Pins map[string]string `json:"pins"`
+}
+
+// tenant is a tenant alongside the store composing its catalog.
+type tenant struct {
+	*tenantConfig
+
+	store   store.Store
+	overlay *store.Reloadable // The tenant's own database, if any
+}
+
+// validate checks that the tenant is well formed.
+func (c *tenantConfig) validate() error {
+	if c.ID == "" {
+		return errors.New("every tenant must have an id")
+	}
+
+	if c.PathPrefix != "" {
+		if !strings.HasPrefix(c.PathPrefix, "/") || strings.HasSuffix(c.PathPrefix, "/") {
+			return errors.Errorf("path prefix %s must start but not end with /", c.PathPrefix)
+		}
+		if c.PathPrefix == "/api" || strings.HasPrefix(c.PathPrefix, "/api/") {
+			return errors.Errorf("path prefix %s conflicts with the API", c.PathPrefix)
+		}
+	}
+
+	err := validatePatterns(c.Plugins)
+	if err != nil {
+		return err
+	}
+
+	for pluginID, version := range c.Pins {
+		_, err = semver.Parse(version)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse pinned version %s of plugin %s", version, pluginID)
+		}
+	}
+
+	return nil
+}
+
+// loadTenants reads the tenants from the given file, composing the catalog of each from the given
+// shared store and labelling the plugins of any tenant database with the given definitions.
+func loadTenants(filename string, sharedStore store.Store, labels []*marketplacemodel.LabelDefinition) ([]*tenant, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", filename)
+	}
+
+	var config tenantsConfig
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", filename)
+	}
+
+	seen := make(map[string]bool)
+	tenants := make([]*tenant, 0, len(config.Tenants))
+	for _, tenantConfig := range config.Tenants {
+		err = tenantConfig.validate()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid tenant %s", tenantConfig.ID)
+		}
+		if seen[tenantConfig.ID] {
+			return nil, errors.Errorf("duplicate tenant %s", tenantConfig.ID)
+		}
+		seen[tenantConfig.ID] = true
+
+		tenant, err := newTenant(tenantConfig, sharedStore, labels)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to initialize tenant %s", tenantConfig.ID)
+		}
+
+		tenants = append(tenants, tenant)
+	}
+
+	return tenants, nil
+}
+
+// newTenant composes the catalog of the given tenant: its own database merged over the shared
+// store, with its pins applied, restricted to its approved plugins.
+func newTenant(config *tenantConfig, sharedStore store.Store, labels []*marketplacemodel.LabelDefinition) (*tenant, error) {
+	result := &tenant{
+		tenantConfig: config,
+		store:        sharedStore,
+	}
+
+	if config.Database != "" {
+		overlay, err := store.NewReloadable(func() (store.Store, error) {
+			return store.NewFile(config.Database, labels, logger)
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to initialize tenant database")
+		}
+
+		result.overlay = overlay
+		result.store = store.NewMerged(logger, sharedStore, overlay)
+	}
+
+	if len(config.Pins) > 0 {
+		result.store = store.NewPinned(result.store, config.Pins, logger)
+	}
+
+	if len(config.Plugins) > 0 {
+		result.store = store.NewScoped(result.store, &store.Visibility{
+			Private: []string{"*"},
+			Granted: config.Plugins,
+		}, logger)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// maxAdminBodySize is the largest request body accepted by the admin API, accommodating
+// embedded icons.
+const maxAdminBodySize = 10 << 20
+
+// initAdmin registers the admin endpoints on the given router, if enabled.
+func initAdmin(apiRouter *mux.Router, context *Context) {
+	if context.AdminStore == nil || len(context.AdminTokens) == 0 {
+		return
+	}
+
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, requireAdminToken(handler))
+	}
+
+	pluginsRouter := apiRouter.PathPrefix("/admin/plugins").Subrouter()
+	pluginsRouter.Handle("", addContext(handlePostAdminPlugin)).Methods(http.MethodPost)
+	pluginsRouter.Handle("/{plugin_id}", addContext(handleDeleteAdminPlugin)).Methods(http.MethodDelete)
+	pluginsRouter.Handle("/{plugin_id}/versions/{version}", addContext(handlePutAdminPlugin)).Methods(http.MethodPut)
+	pluginsRouter.Handle("/{plugin_id}/versions/{version}", addContext(handleDeleteAdminPlugin)).Methods(http.MethodDelete)
+
+	if context.Bundles != nil {
+		apiRouter.Handle("/admin/bundles", addContext(handlePostAdminBundle)).Methods(http.MethodPost)
+	}
+}
+
+// requireAdminToken wraps the given handler, rejecting requests without one of the configured
+// bearer tokens.
+func requireAdminToken(handler contextHandlerFunc) contextHandlerFunc {
+	return func(c *Context, w http.ResponseWriter, r *http.Request) {
+		// Only the bearer scheme is accepted, rather than a bare token.
+		token := ""
+		if authorization := r.Header.Get("Authorization"); strings.HasPrefix(authorization, "Bearer ") {
+			token = strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+		}
+
+		authorized := false
+		for _, adminToken := range c.AdminTokens {
+			if token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1 {
+				authorized = true
+			}
+		}
+
+		if !authorized {
+			c.Logger.Warn("rejected admin request without a valid token")
+			w.Header().Set("WWW-Authenticate", `Bearer realm="marketplace"`)
+			outputError(c, w, newUnauthorizedError("a valid bearer token is required"))
+			return
+		}
+
+		handler(c, w, r)
+	}
+}
+
+// parseAdminPlugin decodes and validates the plugin in the body of an admin request.
+//
+// Any error returned is an *Error identifying the body as the offending parameter.
+func parseAdminPlugin(w http.ResponseWriter, r *http.Request) (*model.Plugin, error) {
+	var plugin model.Plugin
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAdminBodySize)).Decode(&plugin)
+	if err != nil {
+		return nil, newInvalidParameterError("body", errors.Wrap(err, "failed to parse plugin"))
+	}
+
+	err = plugin.Validate()
+	if err != nil {
+		return nil, newInvalidParameterError("body", err)
+	}
+
+	// Record when the release was added to the catalog, unless given.
+	if plugin.UpdatedAt.IsZero() {
+		plugin.UpdatedAt = time.Now().UTC()
+	}
+
+	return &plugin, nil
+}
+
+// adminStoreError translates the given error from a writable store into an *Error.
+func adminStoreError(err error) error {
+	switch {
+	case errors.Is(err, model.ErrPluginExists):
+		return newConflictError("%s", err.Error())
+	case errors.Is(err, model.ErrPluginNotFound):
+		return newNotFoundError("%s", err.Error())
+	default:
+		return err
+	}
+}
+
+// handlePostAdminPlugin responds to POST /api/v1/admin/plugins, adding a new version of a plugin
+// to the catalog.
+func handlePostAdminPlugin(c *Context, w http.ResponseWriter, r *http.Request) {
+	plugin, err := parseAdminPlugin(w, r)
+	if err != nil {
+		c.Logger.WithError(err).Warn("failed to parse plugin")
+		outputError(c, w, err)
+		return
+	}
+
+	err = c.AdminStore.AddPlugin(plugin)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to add plugin")
+		outputError(c, w, adminStoreError(err))
+		return
+	}
+
+	c.Logger.WithField("plugin", plugin.Manifest.Id).WithField("version", plugin.Manifest.Version).Info("added plugin")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/plugins/%s/versions/%s", url.PathEscape(plugin.Manifest.Id), url.PathEscape(plugin.Manifest.Version)))
+	w.WriteHeader(http.StatusCreated)
+	outputJSON(c, w, plugin)
+}
+
+// handlePutAdminPlugin responds to PUT /api/v1/admin/plugins/{plugin_id}/versions/{version},
+// replacing an existing version of a plugin in the catalog.
+func handlePutAdminPlugin(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	plugin, err := parseAdminPlugin(w, r)
+	if err != nil {
+		c.Logger.WithError(err).Warn("failed to parse plugin")
+		outputError(c, w, err)
+		return
+	}
+
+	if plugin.Manifest.Id != vars["plugin_id"] {
+		err = newInvalidParameterError("plugin_id", errors.Errorf("manifest id %s does not match %s", plugin.Manifest.Id, vars["plugin_id"]))
+		c.Logger.WithError(err).Warn("failed to parse plugin")
+		outputError(c, w, err)
+		return
+	}
+	if plugin.Manifest.Version != vars["version"] {
+		err = newInvalidParameterError("version", errors.Errorf("manifest version %s does not match %s", plugin.Manifest.Version, vars["version"]))
+		c.Logger.WithError(err).Warn("failed to parse plugin")
+		outputError(c, w, err)
+		return
+	}
+
+	err = c.AdminStore.UpdatePlugin(plugin)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to update plugin")
+		outputError(c, w, adminStoreError(err))
+		return
+	}
+
+	c.Logger.WithField("plugin", plugin.Manifest.Id).WithField("version", plugin.Manifest.Version).Info("updated plugin")
+
+	w.Header().Set("Content-Type", "application/json")
+	outputJSON(c, w, plugin)
+}
+
+// handleDeleteAdminPlugin responds to DELETE /api/v1/admin/plugins/{plugin_id}, removing every
+// version of a plugin from the catalog, and to DELETE
+// /api/v1/admin/plugins/{plugin_id}/versions/{version}, removing a single version.
+func handleDeleteAdminPlugin(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	err := c.AdminStore.DeletePlugin(vars["plugin_id"], vars["version"])
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to delete plugin")
+		outputError(c, w, adminStoreError(err))
+		return
+	}
+
+	c.Logger.WithField("plugin", vars["plugin_id"]).WithField("version", vars["version"]).Info("deleted plugin")
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,174 @@
+package api_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/api"
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/store"
+	"github.com/mattermost/mattermost-marketplace/internal/testlib"
+)
+
+func TestAdmin(t *testing.T) {
+	newPlugin := func(id, version string) *model.Plugin {
+		return &model.Plugin{
+			DownloadURL: "https://example.com/" + id + "-" + version + ".tar.gz",
+			Manifest:    &mattermostModel.Manifest{Id: id, Name: id, Version: version},
+		}
+	}
+
+	setup := func(t *testing.T, adminTokens ...string) *api.Client {
+		logger := testlib.MakeLogger(t)
+
+		path := filepath.Join(t.TempDir(), "plugins.json")
+		var buf bytes.Buffer
+		require.NoError(t, model.PluginsToWriter(&buf, []*model.Plugin{newPlugin("demo", "1.0.0")}))
+		require.NoError(t, ioutil.WriteFile(path, buf.Bytes(), 0600))
+
+		fileStore, err := store.NewFile(path, model.DefaultLabelDefinitions, logger)
+		require.NoError(t, err)
+
+		router := mux.NewRouter()
+		api.Register(router, &api.Context{
+			Store:       fileStore,
+			AdminStore:  fileStore,
+			AdminTokens: adminTokens,
+			Logger:      logger,
+		})
+		ts := httptest.NewServer(router)
+		t.Cleanup(ts.Close)
+
+		client := api.NewClient(ts.URL)
+		client.Token = "token"
+
+		return client
+	}
+
+	requireError := func(t *testing.T, err error, statusCode int) *api.Error {
+		require.Error(t, err)
+
+		var apiErr *api.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, statusCode, apiErr.StatusCode)
+
+		return apiErr
+	}
+
+	t.Run("disabled without tokens", func(t *testing.T) {
+		client := setup(t)
+
+		_, err := client.AddPlugin(newPlugin("demo", "2.0.0"))
+		requireError(t, err, http.StatusNotFound)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		client := setup(t, "other-token")
+
+		_, err := client.AddPlugin(newPlugin("demo", "2.0.0"))
+		requireError(t, err, http.StatusUnauthorized)
+
+		client.Token = ""
+		err = client.DeletePlugin("demo", "1.0.0")
+		requireError(t, err, http.StatusUnauthorized)
+
+		plugins, err := client.GetPlugins(&api.GetPluginsRequest{PerPage: -1, ReturnAllVersions: true})
+		require.NoError(t, err)
+		require.Len(t, plugins, 1)
+	})
+
+	t.Run("bearer scheme required", func(t *testing.T) {
+		client := setup(t, "token")
+
+		for _, authorization := range []string{"token", "Basic token", "Bearertoken"} {
+			request, err := http.NewRequest(http.MethodDelete, client.Address+"/api/v1/admin/plugins/demo/versions/1.0.0", nil)
+			require.NoError(t, err)
+			request.Header.Set("Authorization", authorization)
+
+			resp, err := http.DefaultClient.Do(request)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, http.StatusUnauthorized, resp.StatusCode, authorization)
+			require.Equal(t, `Bearer realm="marketplace"`, resp.Header.Get("WWW-Authenticate"))
+		}
+
+		plugins, err := client.GetPlugins(&api.GetPluginsRequest{PerPage: -1, ReturnAllVersions: true})
+		require.NoError(t, err)
+		require.Len(t, plugins, 1)
+	})
+
+	t.Run("add, update and delete plugins", func(t *testing.T) {
+		client := setup(t, "other-token", "token")
+
+		added, err := client.AddPlugin(newPlugin("demo", "2.0.0"))
+		require.NoError(t, err)
+		require.Equal(t, "2.0.0", added.Manifest.Version)
+		require.False(t, added.UpdatedAt.IsZero())
+
+		plugin, err := client.GetPluginVersion(&api.GetPluginsRequest{}, "demo", "2.0.0")
+		require.NoError(t, err)
+		require.Equal(t, added.DownloadURL, plugin.DownloadURL)
+
+		_, err = client.AddPlugin(newPlugin("demo", "2.0.0"))
+		requireError(t, err, http.StatusConflict)
+
+		updated := newPlugin("demo", "2.0.0")
+		updated.ReleaseStage = model.Beta
+		_, err = client.UpdatePlugin(updated)
+		require.NoError(t, err)
+
+		plugin, err = client.GetPluginVersion(&api.GetPluginsRequest{}, "demo", "2.0.0")
+		require.NoError(t, err)
+		require.Equal(t, model.Beta, plugin.ReleaseStage)
+
+		_, err = client.UpdatePlugin(newPlugin("demo", "3.0.0"))
+		requireError(t, err, http.StatusNotFound)
+
+		require.NoError(t, client.DeletePlugin("demo", "2.0.0"))
+		requireError(t, client.DeletePlugin("demo", "2.0.0"), http.StatusNotFound)
+
+		require.NoError(t, client.DeletePlugin("demo", ""))
+		plugins, err := client.GetPlugins(&api.GetPluginsRequest{PerPage: -1, ReturnAllVersions: true})
+		require.NoError(t, err)
+		require.Empty(t, plugins)
+	})
+
+	t.Run("invalid plugins", func(t *testing.T) {
+		client := setup(t, "token")
+
+		apiErr := requireError(t, func() error { _, err := client.AddPlugin(newPlugin("demo", "")); return err }(), http.StatusBadRequest)
+		require.Equal(t, "body", apiErr.Parameter)
+
+		_, err := client.AddPlugin(newPlugin("invalid id!", "1.0.0"))
+		requireError(t, err, http.StatusBadRequest)
+
+		req, err := http.NewRequest(http.MethodPost, client.Address+"/api/v1/admin/plugins", strings.NewReader("not json"))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("update must match path", func(t *testing.T) {
+		client := setup(t, "token")
+
+		req, err := http.NewRequest(http.MethodPut, client.Address+"/api/v1/admin/plugins/demo/versions/2.0.0", strings.NewReader(`{"manifest":{"id":"demo","name":"demo","version":"1.0.0"}}`))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// initAdvisories registers advisory endpoints on the given router.
+func initAdvisories(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	advisoriesRouter := apiRouter.PathPrefix("/advisories").Subrouter()
+	advisoriesRouter.Handle("", addContext(handleGetAdvisories)).Methods(http.MethodGet)
+}
+
+// ParseAdvisoryFilter parses an advisory filter from the given url.
+func ParseAdvisoryFilter(u *url.URL) (*model.AdvisoryFilter, error) {
+	filter := &model.AdvisoryFilter{
+		PluginID: strings.TrimSpace(u.Query().Get("plugin_id")),
+		Version:  strings.TrimSpace(u.Query().Get("version")),
+		Severity: model.Severity(strings.ToLower(strings.TrimSpace(u.Query().Get("severity")))),
+		CVE:      strings.TrimSpace(u.Query().Get("cve")),
+	}
+
+	if filter.Version != "" {
+		if filter.PluginID == "" {
+			return nil, newInvalidParameterError("version", errors.New("version may only be given alongside plugin_id"))
+		}
+
+		version, err := semver.ParseTolerant(filter.Version)
+		if err != nil {
+			return nil, newInvalidParameterError("version", err)
+		}
+		filter.Version = version.String()
+	}
+
+	if filter.Severity != "" && !filter.Severity.IsValid() {
+		return nil, newInvalidParameterError("severity", errors.Errorf("unsupported severity %s", filter.Severity))
+	}
+
+	return filter, nil
+}
+
+// handleGetAdvisories responds to GET /api/v1/advisories, returning the security advisories
+// matching the given filter. Advisories for plugins hidden from the caller are omitted.
+func handleGetAdvisories(c *Context, w http.ResponseWriter, r *http.Request) {
+	filter, err := ParseAdvisoryFilter(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Warn("failed to parse advisory filter")
+		outputError(c, w, err)
+		return
+	}
+
+	advisories := []*model.Advisory{}
+	if c.Advisories != nil {
+		advisories, err = c.Advisories.GetAdvisories(filter)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query advisories")
+			outputError(c, w, err)
+			return
+		}
+	}
+
+	response := make([]*model.Advisory, 0, len(advisories))
+	for _, advisory := range advisories {
+		if pluginVisible(c, advisory.PluginID) {
+			response = append(response, advisory)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	outputJSON(c, w, response)
+}
+
+// addAdvisories annotates the given plugins with the advisories affecting their versions, if
+// advisories are configured. Advisories already attached, such as by an upstream marketplace, are
+// retained.
+//
+// Failing to get the advisories is logged rather than failing the request, leaving the plugins
+// unannotated.
+func addAdvisories(c *Context, plugins []*model.Plugin) {
+	if c.Advisories == nil || len(plugins) == 0 {
+		return
+	}
+
+	advisories, err := c.Advisories.GetAdvisories(&model.AdvisoryFilter{})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get advisories")
+		return
+	}
+
+	byPluginID := make(map[string][]*model.Advisory)
+	for _, advisory := range advisories {
+		byPluginID[advisory.PluginID] = append(byPluginID[advisory.PluginID], advisory)
+	}
+
+	for _, plugin := range plugins {
+		attached := make(map[string]bool, len(plugin.Advisories))
+		for _, advisory := range plugin.Advisories {
+			attached[advisory.ID] = true
+		}
+
+		// Build a new slice, since the existing one may be shared with the store.
+		affected := append([]*model.Advisory(nil), plugin.Advisories...)
+		for _, advisory := range byPluginID[plugin.Manifest.Id] {
+			if !attached[advisory.ID] && advisory.Affects(plugin.Manifest.Id, plugin.Manifest.Version) {
+				affected = append(affected, advisory)
+			}
+		}
+		plugin.Advisories = affected
+	}
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/url"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// GetAdvisoriesRequest describes the parameters to request a list of security advisories.
+type GetAdvisoriesRequest struct {
+	PluginID string
+	Version  string // Only honoured alongside PluginID
+	Severity model.Severity
+	CVE      string
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetAdvisoriesRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	if request.PluginID != "" {
+		q.Add("plugin_id", request.PluginID)
+	}
+	if request.Version != "" {
+		q.Add("version", request.Version)
+	}
+	if request.Severity != "" {
+		q.Add("severity", string(request.Severity))
+	}
+	if request.CVE != "" {
+		q.Add("cve", request.CVE)
+	}
+	u.RawQuery = q.Encode()
+}
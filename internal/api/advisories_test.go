@@ -0,0 +1,152 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/api"
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/store"
+	"github.com/mattermost/mattermost-marketplace/internal/testlib"
+)
+
+func TestAdvisories(t *testing.T) {
+	newPlugin := func(id, version string) *model.Plugin {
+		return &model.Plugin{
+			Manifest: &mattermostModel.Manifest{
+				Id:      id,
+				Name:    id,
+				Version: version,
+			},
+		}
+	}
+
+	jiraWebhooks := &model.Advisory{
+		ID:               "MMSA-2021-0001",
+		CVE:              "CVE-2021-1234",
+		PluginID:         "jira",
+		AffectedVersions: []string{">=2.0.0 <2.4.1"},
+		FixedVersion:     "2.4.1",
+		Severity:         model.SeverityCritical,
+		Description:      "Webhook requests are not authenticated.",
+	}
+	jiraXSS := &model.Advisory{
+		ID:               "MMSA-2021-0002",
+		PluginID:         "jira",
+		AffectedVersions: []string{"<2.1.0"},
+		FixedVersion:     "2.1.0",
+		Severity:         model.SeverityLow,
+		Description:      "Issue titles are not escaped.",
+	}
+	internal := &model.Advisory{
+		ID:               "MMSA-2021-0003",
+		PluginID:         "com.acme.internal",
+		AffectedVersions: []string{"<1.0.1"},
+		Severity:         model.SeverityHigh,
+		Description:      "Leaks credentials.",
+	}
+
+	logger := testlib.MakeLogger(t)
+	staticStore, err := store.NewStatic([]*model.Plugin{
+		newPlugin("jira", "2.0.0"),
+		newPlugin("jira", "2.4.1"),
+		newPlugin("github", "1.0.0"),
+		newPlugin("com.acme.internal", "1.0.0"),
+	}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+
+	advisories, err := store.NewStaticAdvisories([]*model.Advisory{jiraWebhooks, jiraXSS, internal})
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      store.NewScoped(staticStore, &store.Visibility{Private: []string{"com.acme.*"}}, logger),
+		Advisories: advisories,
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := api.NewClient(ts.URL)
+
+	t.Run("list advisories", func(t *testing.T) {
+		testCases := []struct {
+			Description string
+			Request     *api.GetAdvisoriesRequest
+			Expected    []*model.Advisory
+		}{
+			{"all visible", &api.GetAdvisoriesRequest{}, []*model.Advisory{jiraWebhooks, jiraXSS}},
+			{"by plugin", &api.GetAdvisoriesRequest{PluginID: "jira"}, []*model.Advisory{jiraWebhooks, jiraXSS}},
+			{"by affected version", &api.GetAdvisoriesRequest{PluginID: "jira", Version: "v2.3.0"}, []*model.Advisory{jiraWebhooks}},
+			{"by fixed version", &api.GetAdvisoriesRequest{PluginID: "jira", Version: "2.4.1"}, []*model.Advisory{}},
+			{"by minimum severity", &api.GetAdvisoriesRequest{Severity: model.SeverityHigh}, []*model.Advisory{jiraWebhooks}},
+			{"by cve", &api.GetAdvisoriesRequest{CVE: "cve-2021-1234"}, []*model.Advisory{jiraWebhooks}},
+			{"private plugin", &api.GetAdvisoriesRequest{PluginID: "com.acme.internal"}, []*model.Advisory{}},
+		}
+
+		for _, testCase := range testCases {
+			testCase := testCase
+			t.Run(testCase.Description, func(t *testing.T) {
+				result, err := client.GetAdvisories(testCase.Request)
+				require.NoError(t, err)
+				require.Equal(t, testCase.Expected, result)
+			})
+		}
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		for _, request := range []*api.GetAdvisoriesRequest{
+			{Severity: "urgent"},
+			{Version: "2.0.0"},
+			{PluginID: "jira", Version: "latest"},
+		} {
+			_, err := client.GetAdvisories(request)
+			require.Error(t, err)
+
+			var apiErr *api.Error
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+		}
+	})
+
+	t.Run("plugins carry the advisories affecting them", func(t *testing.T) {
+		plugins, err := client.GetPlugin(&api.GetPluginsRequest{PerPage: -1, ReturnAllVersions: true}, "jira")
+		require.NoError(t, err)
+		require.Len(t, plugins, 2)
+
+		advisoriesByVersion := make(map[string][]*model.Advisory)
+		for _, plugin := range plugins {
+			advisoriesByVersion[plugin.Manifest.Version] = plugin.Advisories
+		}
+		require.Equal(t, map[string][]*model.Advisory{
+			"2.0.0": {jiraWebhooks, jiraXSS},
+			"2.4.1": nil,
+		}, advisoriesByVersion)
+
+		plugin, err := client.GetPluginVersion(&api.GetPluginsRequest{}, "jira", "2.0.0")
+		require.NoError(t, err)
+		require.Equal(t, []*model.Advisory{jiraWebhooks, jiraXSS}, plugin.Advisories)
+
+		plugins, err = client.GetPlugins(&api.GetPluginsRequest{PerPage: -1})
+		require.NoError(t, err)
+		for _, plugin := range plugins {
+			require.Empty(t, plugin.Advisories, plugin.Manifest.Id)
+		}
+	})
+
+	t.Run("no advisories configured", func(t *testing.T) {
+		router := mux.NewRouter()
+		api.Register(router, &api.Context{Store: staticStore, Logger: logger})
+		ts := httptest.NewServer(router)
+		defer ts.Close()
+
+		result, err := api.NewClient(ts.URL).GetAdvisories(&api.GetAdvisoriesRequest{})
+		require.NoError(t, err)
+		require.Empty(t, result)
+	})
+}
@@ -1,6 +1,10 @@
 package api
 
-import "github.com/gorilla/mux"
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
 
 // Register registers the API endpoints on the given router.
 func Register(rootRouter *mux.Router, context *Context) {
@@ -8,5 +12,26 @@ func Register(rootRouter *mux.Router, context *Context) {
 
 	initPlugins(apiRouter, context)
 	initLabels(apiRouter, context)
+	initAdvisories(apiRouter, context)
+	initFeeds(apiRouter, context)
+	initBundles(apiRouter, context)
+	initAdmin(apiRouter, context)
 	initHealthCheck(apiRouter, context)
+
+	apiRouter.NotFoundHandler = newContextHandler(context, handleNotFound)
+	apiRouter.MethodNotAllowedHandler = newContextHandler(context, handleMethodNotAllowed)
+}
+
+// handleNotFound responds to requests for an unknown API endpoint.
+func handleNotFound(c *Context, w http.ResponseWriter, r *http.Request) {
+	outputError(c, w, newNotFoundError("no endpoint matches %s", r.URL.Path))
+}
+
+// handleMethodNotAllowed responds to requests using an unsupported method for a known API endpoint.
+func handleMethodNotAllowed(c *Context, w http.ResponseWriter, r *http.Request) {
+	outputError(c, w, &Error{
+		StatusCode: http.StatusMethodNotAllowed,
+		Code:       ErrorCodeMethodNotAllowed,
+		Message:    "method " + r.Method + " is not allowed for " + r.URL.Path,
+	})
 }
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Headers authenticating a request with an API key, either by presenting the key itself, or by
+// identifying the key and signing the request with it.
+const (
+	HeaderAPIKey           = "X-Marketplace-Key"
+	HeaderAPIKeyID         = "X-Marketplace-Key-Id"
+	HeaderRequestTimestamp = "X-Marketplace-Timestamp" // Seconds since the Unix epoch
+	HeaderRequestSignature = "X-Marketplace-Signature" // sha256= followed by the hex encoded HMAC
+)
+
+// maxRequestSignatureAge is how far the timestamp of a signed request may differ from the
+// current time, limiting the window in which a captured request may be replayed.
+const maxRequestSignatureAge = 5 * time.Minute
+
+// APIKey authenticates callers of the API, whose requests are served from a store reflecting the
+// visibility granted to the key.
+type APIKey struct {
+	ID     string // Identifies the key in logs and signed requests
+	Secret string // Presented by callers as is, or used to sign their requests
+	Store  Store  // Serves requests authenticated by the key
+}
+
+// requestSignature computes the signature of a request with the given method, URI and timestamp.
+func requestSignature(secret, timestamp, method, requestURI string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(timestamp + "\n" + method + "\n" + requestURI))
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest authenticates the given request with the identified API key, signing its method and
+// URI as of the given time.
+func SignRequest(r *http.Request, keyID, secret string, now time.Time) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	r.Header.Set(HeaderAPIKeyID, keyID)
+	r.Header.Set(HeaderRequestTimestamp, timestamp)
+	r.Header.Set(HeaderRequestSignature, requestSignature(secret, timestamp, r.Method, r.URL.RequestURI()))
+}
+
+// authenticateAPIKey returns the API key with which the given request was authenticated, or nil
+// if the request is anonymous. An error is returned if the request presents an unknown key or an
+// invalid signature.
+func authenticateAPIKey(keys []*APIKey, r *http.Request, now time.Time) (*APIKey, error) {
+	if secret := r.Header.Get(HeaderAPIKey); secret != "" {
+		for _, key := range keys {
+			if key.Secret != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(key.Secret)) == 1 {
+				return key, nil
+			}
+		}
+
+		return nil, errors.New("unknown API key")
+	}
+
+	keyID := r.Header.Get(HeaderAPIKeyID)
+	if keyID == "" {
+		return nil, nil
+	}
+
+	var key *APIKey
+	for _, candidate := range keys {
+		if candidate.ID == keyID {
+			key = candidate
+			break
+		}
+	}
+	if key == nil || key.Secret == "" {
+		return nil, errors.Errorf("unknown API key %s", keyID)
+	}
+
+	timestamp := r.Header.Get(HeaderRequestTimestamp)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse request timestamp %s", timestamp)
+	}
+	age := now.Sub(time.Unix(seconds, 0))
+	if age > maxRequestSignatureAge || age < -maxRequestSignatureAge {
+		return nil, errors.Errorf("request timestamp %s is outside the permitted window", timestamp)
+	}
+
+	expected := requestSignature(key.Secret, timestamp, r.Method, r.URL.RequestURI())
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get(HeaderRequestSignature))) {
+		return nil, errors.Errorf("invalid signature for API key %s", keyID)
+	}
+
+	return key, nil
+}
+
+// pluginVisibility is optionally implemented by a Store hiding some plugins from its callers.
+type pluginVisibility interface {
+	PluginVisible(pluginID string) bool
+}
+
+// pluginVisible reports whether the plugin with the given id may be seen through the context's
+// store, allowing resources keyed by plugin id but not served from the store to be hidden alike.
+func pluginVisible(c *Context, pluginID string) bool {
+	visibility, ok := c.Store.(pluginVisibility)
+	if !ok {
+		return true
+	}
+
+	return visibility.PluginVisible(pluginID)
+}
@@ -0,0 +1,147 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/api"
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/stats"
+	"github.com/mattermost/mattermost-marketplace/internal/store"
+	"github.com/mattermost/mattermost-marketplace/internal/testlib"
+)
+
+func TestAPIKeys(t *testing.T) {
+	newPlugin := func(id string) *model.Plugin {
+		return &model.Plugin{
+			DownloadURL: "https://example.com/" + id + ".tar.gz",
+			Manifest:    &mattermostModel.Manifest{Id: id, Name: id, Version: "1.0.0"},
+		}
+	}
+
+	logger := testlib.MakeLogger(t)
+	staticStore, err := store.NewStatic([]*model.Plugin{newPlugin("public"), newPlugin("com.acme.internal")}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+
+	private := []string{"com.acme.*"}
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store: store.NewScoped(staticStore, &store.Visibility{Private: private}, logger),
+		Stats: stats.NewMemory(),
+		APIKeys: []*api.APIKey{
+			{
+				ID:     "acme",
+				Secret: "acme-secret",
+				Store:  store.NewScoped(staticStore, &store.Visibility{Private: private, Granted: []string{"com.acme.*"}}, logger),
+			},
+			{
+				ID:     "other",
+				Secret: "other-secret",
+				Store:  store.NewScoped(staticStore, &store.Visibility{Private: private}, logger),
+			},
+		},
+		Logger: logger,
+	})
+	ts := httptest.NewServer(router)
+	t.Cleanup(ts.Close)
+
+	getPluginIDs := func(t *testing.T, client *api.Client) []string {
+		plugins, err := client.GetPlugins(&api.GetPluginsRequest{PerPage: -1})
+		require.NoError(t, err)
+
+		ids := []string{}
+		for _, plugin := range plugins {
+			ids = append(ids, plugin.Manifest.Id)
+		}
+
+		return ids
+	}
+
+	requireUnauthorized := func(t *testing.T, err error) {
+		require.Error(t, err)
+
+		var apiErr *api.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	}
+
+	t.Run("anonymous callers only see public plugins", func(t *testing.T) {
+		client := api.NewClient(ts.URL)
+		require.Equal(t, []string{"public"}, getPluginIDs(t, client))
+
+		_, err := client.GetPlugin(&api.GetPluginsRequest{}, "com.acme.internal")
+		require.Error(t, err)
+
+		_, err = client.GetPluginStats("com.acme.internal")
+		require.Error(t, err)
+	})
+
+	t.Run("keys reveal the plugins they grant", func(t *testing.T) {
+		client := api.NewClient(ts.URL)
+		client.APIKey = "acme-secret"
+		require.ElementsMatch(t, []string{"public", "com.acme.internal"}, getPluginIDs(t, client))
+
+		_, err := client.GetPluginStats("com.acme.internal")
+		require.NoError(t, err)
+
+		client.APIKey = "other-secret"
+		require.Equal(t, []string{"public"}, getPluginIDs(t, client))
+	})
+
+	t.Run("signed requests", func(t *testing.T) {
+		client := api.NewClient(ts.URL)
+		client.APIKeyID = "acme"
+		client.APIKey = "acme-secret"
+		require.ElementsMatch(t, []string{"public", "com.acme.internal"}, getPluginIDs(t, client))
+
+		client.APIKey = "wrong-secret"
+		_, err := client.GetPlugins(&api.GetPluginsRequest{PerPage: -1})
+		requireUnauthorized(t, err)
+
+		client.APIKeyID = "unknown"
+		_, err = client.GetPlugins(&api.GetPluginsRequest{PerPage: -1})
+		requireUnauthorized(t, err)
+	})
+
+	t.Run("stale signatures are rejected", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/plugins", nil)
+		require.NoError(t, err)
+		api.SignRequest(req, "acme", "acme-secret", time.Now().Add(-time.Hour))
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("unknown keys are rejected", func(t *testing.T) {
+		client := api.NewClient(ts.URL)
+		client.APIKey = "unknown-secret"
+
+		_, err := client.GetPlugins(&api.GetPluginsRequest{PerPage: -1})
+		requireUnauthorized(t, err)
+	})
+
+	t.Run("authenticated listings are privately cached", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/api/v1/plugins")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, "public, max-age=300", resp.Header.Get("Cache-Control"))
+		publicETag := resp.Header.Get("ETag")
+
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/plugins", nil)
+		require.NoError(t, err)
+		req.Header.Set(api.HeaderAPIKey, "acme-secret")
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, "private, max-age=300", resp.Header.Get("Cache-Control"))
+		require.NotEqual(t, publicETag, resp.Header.Get("ETag"))
+	})
+}
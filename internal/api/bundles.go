@@ -0,0 +1,237 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-marketplace/internal/bundle"
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+const (
+	// maxBundleUploadSize is the largest multipart body accepted when uploading a bundle.
+	maxBundleUploadSize = 256 << 20
+
+	// maxBundleUploadMemory is the portion of an uploaded bundle held in memory while parsing,
+	// with the remainder buffered on disk.
+	maxBundleUploadMemory = 32 << 20
+
+	// maxBundleDecompressedSize is the largest uncompressed tar file read from an uploaded bundle,
+	// since a small upload may otherwise decompress without bound.
+	maxBundleDecompressedSize = 512 << 20
+)
+
+// initBundles registers the bundle endpoints on the given router, if enabled.
+func initBundles(apiRouter *mux.Router, context *Context) {
+	if context.Bundles == nil {
+		return
+	}
+
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	apiRouter.Handle("/bundles/{name}", addContext(handleGetBundle)).Methods(http.MethodGet)
+}
+
+// bundleName is the name under which the bundle of the given version of a plugin is stored,
+// matching the naming of bundles published by the generator.
+func bundleName(pluginID, version string) string {
+	return fmt.Sprintf("%s-%s.tar.gz", pluginID, version)
+}
+
+// bundleVisible reports whether the bundle with the given name may be seen through the context's
+// store. Since both plugin ids and versions may contain hyphens, the bundle is hidden if any
+// plugin whose bundle could be so named is hidden.
+func bundleVisible(c *Context, name string) bool {
+	base := strings.TrimSuffix(name, ".tar.gz")
+	for i, r := range base {
+		if r != '-' {
+			continue
+		}
+
+		if _, err := semver.Parse(base[i+1:]); err != nil {
+			continue
+		}
+
+		if !pluginVisible(c, base[:i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// handleGetBundle responds to GET /api/v1/bundles/{name}, serving a bundle uploaded through the
+// admin API.
+func handleGetBundle(c *Context, w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	if !bundleVisible(c, name) {
+		outputError(c, w, newNotFoundError("bundle %s not found", name))
+		return
+	}
+
+	file, modTime, err := c.Bundles.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		outputError(c, w, newNotFoundError("bundle %s not found", name))
+		return
+	} else if err != nil {
+		c.Logger.WithError(err).Errorf("failed to open bundle %s", name)
+		outputError(c, w, err)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", "application/gzip")
+	http.ServeContent(w, r, name, modTime, file)
+}
+
+// readFormFile reads the entirety of the named file from a parsed multipart form.
+//
+// Any error returned is an *Error identifying the offending field.
+func readFormFile(r *http.Request, name string) ([]byte, error) {
+	file, _, err := r.FormFile(name)
+	if err != nil {
+		return nil, newInvalidParameterError(name, errors.Wrapf(err, "failed to read %s", name))
+	}
+	defer file.Close()
+
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, newInvalidParameterError(name, errors.Wrapf(err, "failed to read %s", name))
+	}
+	if len(data) == 0 {
+		return nil, newInvalidParameterError(name, errors.Errorf("%s must not be empty", name))
+	}
+
+	return data, nil
+}
+
+// parseAdminBundle reads the uploaded bundle and signature of a plugin, describing the plugin as
+// the generator would given the same bundle. The download URL of the plugin is the marketplace
+// itself, at the given public URL.
+//
+// Any error returned is an *Error identifying the offending field.
+func parseAdminBundle(w http.ResponseWriter, r *http.Request, publicURL string) (*model.Plugin, []byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBundleUploadSize)
+	err := r.ParseMultipartForm(maxBundleUploadMemory)
+	if err != nil {
+		return nil, nil, newInvalidParameterError("body", errors.Wrap(err, "failed to parse multipart form"))
+	}
+
+	bundleData, err := readFormFile(r, "bundle")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signature, err := readFormFile(r, "signature")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tarData, err := bundle.Decompress(bytes.NewReader(bundleData), maxBundleDecompressedSize)
+	if err != nil {
+		return nil, nil, newInvalidParameterError("bundle", err)
+	}
+
+	manifest, err := bundle.GetManifestFromTarFile(tarData)
+	if err != nil {
+		return nil, nil, newInvalidParameterError("bundle", err)
+	}
+
+	var iconData string
+	if manifest.IconPath != "" {
+		iconData, err = bundle.GetIconDataFromTarFile(tarData, manifest.IconPath)
+		if err != nil {
+			return nil, nil, newInvalidParameterError("bundle", err)
+		}
+	}
+
+	plugin := &model.Plugin{
+		HomepageURL:     manifest.HomepageURL,
+		IconData:        iconData,
+		DownloadURL:     strings.TrimRight(publicURL, "/") + "/api/v1/bundles/" + url.PathEscape(bundleName(manifest.Id, manifest.Version)),
+		ReleaseNotesURL: manifest.ReleaseNotesURL,
+		Labels:          []model.Label{},
+		Signature:       base64.StdEncoding.EncodeToString(signature),
+		Manifest:        manifest,
+		UpdatedAt:       time.Now().UTC(),
+	}
+
+	err = plugin.Validate()
+	if err != nil {
+		return nil, nil, newInvalidParameterError("bundle", err)
+	}
+
+	return plugin, bundleData, nil
+}
+
+// handlePostAdminBundle responds to POST /api/v1/admin/bundles, adding the plugin described by an
+// uploaded bundle and signature to the catalog, and storing the bundle to be served by the
+// marketplace.
+//
+// The bundle is stored before the catalog entry is added, so a plugin is never listed without its
+// bundle. An existing bundle is never replaced, and if the entry cannot be added, the newly stored
+// bundle is removed again.
+func handlePostAdminBundle(c *Context, w http.ResponseWriter, r *http.Request) {
+	if c.PublicURL == "" {
+		c.Logger.Error("failed to accept bundle without a configured public URL")
+		outputError(c, w, errors.New("no public URL is configured from which to serve bundles"))
+		return
+	}
+
+	plugin, bundleData, err := parseAdminBundle(w, r, c.PublicURL)
+	if r.MultipartForm != nil {
+		defer r.MultipartForm.RemoveAll()
+	}
+	if err != nil {
+		c.Logger.WithError(err).Warn("failed to parse bundle")
+		outputError(c, w, err)
+		return
+	}
+
+	name := bundleName(plugin.Manifest.Id, plugin.Manifest.Version)
+	logger := c.Logger.WithField("plugin", plugin.Manifest.Id).WithField("version", plugin.Manifest.Version)
+
+	err = c.Bundles.Put(name, bundleData)
+	if errors.Is(err, os.ErrExist) {
+		outputError(c, w, newConflictError("bundle %s already exists", name))
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("failed to store bundle")
+		outputError(c, w, err)
+		return
+	}
+
+	err = c.AdminStore.AddPlugin(plugin)
+	if err != nil {
+		logger.WithError(err).Error("failed to add plugin")
+
+		deleteErr := c.Bundles.Delete(name)
+		if deleteErr != nil {
+			logger.WithError(deleteErr).Error("failed to remove bundle of a plugin not added")
+		}
+
+		outputError(c, w, adminStoreError(err))
+		return
+	}
+
+	logger.Info("added plugin from uploaded bundle")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/plugins/%s/versions/%s", url.PathEscape(plugin.Manifest.Id), url.PathEscape(plugin.Manifest.Version)))
+	w.WriteHeader(http.StatusCreated)
+	outputJSON(c, w, plugin)
+}
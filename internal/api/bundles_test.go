@@ -0,0 +1,256 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/api"
+	"github.com/mattermost/mattermost-marketplace/internal/bundle"
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/store"
+	"github.com/mattermost/mattermost-marketplace/internal/testlib"
+)
+
+func TestBundles(t *testing.T) {
+	const manifest = `{"id": "com.example.demo", "name": "Demo", "version": "1.2.3", "homepage_url": "https://example.com", "icon_path": "assets/icon.svg"}`
+
+	setup := func(t *testing.T, options ...api.ClientOption) (*api.Client, string) {
+		logger := testlib.MakeLogger(t)
+
+		path := filepath.Join(t.TempDir(), "plugins.json")
+		require.NoError(t, ioutil.WriteFile(path, []byte("[]"), 0600))
+
+		fileStore, err := store.NewFile(path, model.DefaultLabelDefinitions, logger)
+		require.NoError(t, err)
+
+		bundleDir := filepath.Join(t.TempDir(), "bundles")
+		bundles, err := bundle.NewDirectory(bundleDir)
+		require.NoError(t, err)
+
+		router := mux.NewRouter()
+		context := &api.Context{
+			Store:       fileStore,
+			AdminStore:  fileStore,
+			AdminTokens: []string{"token"},
+			Bundles:     bundles,
+			Logger:      logger,
+		}
+		api.Register(router, context)
+		ts := httptest.NewServer(router)
+		t.Cleanup(ts.Close)
+		context.PublicURL = ts.URL
+
+		client := api.NewClient(ts.URL, options...)
+		client.Token = "token"
+
+		return client, bundleDir
+	}
+
+	requireStatus := func(t *testing.T, err error, statusCode int) {
+		require.Error(t, err)
+
+		var apiErr *api.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, statusCode, apiErr.StatusCode)
+	}
+
+	t.Run("upload and download", func(t *testing.T) {
+		client, bundleDir := setup(t)
+
+		bundleData := testlib.MakeBundle(t, "com.example.demo", map[string]string{
+			"plugin.json":     manifest,
+			"assets/icon.svg": "<svg/>",
+		})
+
+		plugin, err := client.UploadBundle(bytes.NewReader(bundleData), strings.NewReader("signature"))
+		require.NoError(t, err)
+		require.Equal(t, "com.example.demo", plugin.Manifest.Id)
+		require.Equal(t, "https://example.com", plugin.HomepageURL)
+		require.Equal(t, client.Address+"/api/v1/bundles/com.example.demo-1.2.3.tar.gz", plugin.DownloadURL)
+		require.Equal(t, base64.StdEncoding.EncodeToString([]byte("signature")), plugin.Signature)
+		require.Equal(t, "data:image/svg+xml;base64,PHN2Zy8+", plugin.IconData)
+
+		stored, err := ioutil.ReadFile(filepath.Join(bundleDir, "com.example.demo-1.2.3.tar.gz"))
+		require.NoError(t, err)
+		require.Equal(t, bundleData, stored)
+
+		listed, err := client.GetPluginVersion(&api.GetPluginsRequest{}, "com.example.demo", "1.2.3")
+		require.NoError(t, err)
+		require.Equal(t, plugin.DownloadURL, listed.DownloadURL)
+
+		resp, err := http.Get(client.Address + "/api/v1/plugins/com.example.demo/download")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "application/gzip", resp.Header.Get("Content-Type"))
+
+		downloaded, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, bundleData, downloaded)
+
+		_, err = client.UploadBundle(bytes.NewReader(bundleData), strings.NewReader("signature"))
+		requireStatus(t, err, http.StatusConflict)
+	})
+
+	t.Run("download URL ignores request headers", func(t *testing.T) {
+		client, _ := setup(t, api.WithTransport(spoofingTransport{}))
+
+		bundleData := testlib.MakeBundle(t, "com.example.demo", map[string]string{
+			"plugin.json":     manifest,
+			"assets/icon.svg": "<svg/>",
+		})
+
+		plugin, err := client.UploadBundle(bytes.NewReader(bundleData), strings.NewReader("signature"))
+		require.NoError(t, err)
+		require.Equal(t, client.Address+"/api/v1/bundles/com.example.demo-1.2.3.tar.gz", plugin.DownloadURL)
+	})
+
+	t.Run("version already in catalog", func(t *testing.T) {
+		client, bundleDir := setup(t)
+
+		_, err := client.AddPlugin(&model.Plugin{
+			DownloadURL: "https://example.com/com.example.demo-1.2.3.tar.gz",
+			Manifest:    &mattermostModel.Manifest{Id: "com.example.demo", Name: "Demo", Version: "1.2.3"},
+		})
+		require.NoError(t, err)
+
+		bundleData := testlib.MakeBundle(t, "com.example.demo", map[string]string{
+			"plugin.json":     manifest,
+			"assets/icon.svg": "<svg/>",
+		})
+		_, err = client.UploadBundle(bytes.NewReader(bundleData), strings.NewReader("signature"))
+		requireStatus(t, err, http.StatusConflict)
+
+		entries, err := ioutil.ReadDir(bundleDir)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+
+	t.Run("concurrent uploads", func(t *testing.T) {
+		client, bundleDir := setup(t)
+
+		bundleData := testlib.MakeBundle(t, "com.example.demo", map[string]string{
+			"plugin.json":     manifest,
+			"assets/icon.svg": "<svg/>",
+		})
+
+		errs := make(chan error, 5)
+		for i := 0; i < cap(errs); i++ {
+			go func() {
+				_, err := client.UploadBundle(bytes.NewReader(bundleData), strings.NewReader("signature"))
+				errs <- err
+			}()
+		}
+
+		var created int
+		for i := 0; i < cap(errs); i++ {
+			err := <-errs
+			if err == nil {
+				created++
+				continue
+			}
+			requireStatus(t, err, http.StatusConflict)
+		}
+		require.Equal(t, 1, created)
+
+		stored, err := ioutil.ReadFile(filepath.Join(bundleDir, "com.example.demo-1.2.3.tar.gz"))
+		require.NoError(t, err)
+		require.Equal(t, bundleData, stored)
+	})
+
+	t.Run("no public URL", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		path := filepath.Join(t.TempDir(), "plugins.json")
+		require.NoError(t, ioutil.WriteFile(path, []byte("[]"), 0600))
+		fileStore, err := store.NewFile(path, model.DefaultLabelDefinitions, logger)
+		require.NoError(t, err)
+		bundles, err := bundle.NewDirectory(t.TempDir())
+		require.NoError(t, err)
+
+		router := mux.NewRouter()
+		api.Register(router, &api.Context{
+			Store:       fileStore,
+			AdminStore:  fileStore,
+			AdminTokens: []string{"token"},
+			Bundles:     bundles,
+			Logger:      logger,
+		})
+		ts := httptest.NewServer(router)
+		defer ts.Close()
+
+		client := api.NewClient(ts.URL)
+		client.Token = "token"
+
+		bundleData := testlib.MakeBundle(t, "com.example.demo", map[string]string{
+			"plugin.json":     manifest,
+			"assets/icon.svg": "<svg/>",
+		})
+		_, err = client.UploadBundle(bytes.NewReader(bundleData), strings.NewReader("signature"))
+		requireStatus(t, err, http.StatusInternalServerError)
+
+		plugins, err := client.GetPlugins(&api.GetPluginsRequest{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Empty(t, plugins)
+	})
+
+	t.Run("unknown bundle", func(t *testing.T) {
+		client, _ := setup(t)
+
+		resp, err := http.Get(client.Address + "/api/v1/bundles/missing.tar.gz")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("invalid uploads", func(t *testing.T) {
+		client, bundleDir := setup(t)
+
+		_, err := client.UploadBundle(strings.NewReader("not a bundle"), strings.NewReader("signature"))
+		requireStatus(t, err, http.StatusBadRequest)
+
+		validBundle := testlib.MakeBundle(t, "com.example.demo", map[string]string{"plugin.json": manifest, "assets/icon.svg": "<svg/>"})
+		_, err = client.UploadBundle(bytes.NewReader(validBundle), strings.NewReader(""))
+		requireStatus(t, err, http.StatusBadRequest)
+
+		invalidManifest := testlib.MakeBundle(t, "com.example.demo", map[string]string{"plugin.json": `{"id": "com.example.demo"}`})
+		_, err = client.UploadBundle(bytes.NewReader(invalidManifest), strings.NewReader("signature"))
+		requireStatus(t, err, http.StatusBadRequest)
+
+		missingIcon := testlib.MakeBundle(t, "com.example.demo", map[string]string{"plugin.json": manifest})
+		_, err = client.UploadBundle(bytes.NewReader(missingIcon), strings.NewReader("signature"))
+		requireStatus(t, err, http.StatusBadRequest)
+
+		client.Token = "wrong"
+		_, err = client.UploadBundle(bytes.NewReader(validBundle), strings.NewReader("signature"))
+		requireStatus(t, err, http.StatusUnauthorized)
+
+		entries, err := ioutil.ReadDir(bundleDir)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+
+		client.Token = "token"
+		plugins, err := client.GetPlugins(&api.GetPluginsRequest{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Empty(t, plugins)
+	})
+}
+
+// spoofingTransport sends requests claiming to have been received through another host.
+type spoofingTransport struct{}
+
+func (spoofingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r.Host = "attacker.example.com"
+	r.Header.Set("X-Forwarded-Proto", "https")
+
+	return http.DefaultTransport.RoundTrip(r)
+}
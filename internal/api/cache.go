@@ -0,0 +1,206 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// pluginsCacheControl allows browsers and CDNs to reuse a listing for a few minutes, after
+// which it must be revalidated using the ETag.
+const pluginsCacheControl = "public, max-age=300"
+
+// privatePluginsCacheControl allows only the client itself to reuse a listing served to an
+// authenticated caller, which may include plugins hidden from others.
+const privatePluginsCacheControl = "private, max-age=300"
+
+// pluginsCacheControlFor returns the Cache-Control header for a listing served in the given
+// context.
+func pluginsCacheControlFor(c *Context) string {
+	if c.APIKeyID != "" {
+		return privatePluginsCacheControl
+	}
+
+	return pluginsCacheControl
+}
+
+// revisioner is optionally implemented by a Store able to identify the revision of its catalog.
+type revisioner interface {
+	Revision() string
+}
+
+// storeRevision returns the revision of the catalog backing the store, if known.
+func storeRevision(store Store) string {
+	r, ok := store.(revisioner)
+	if !ok {
+		return ""
+	}
+
+	return r.Revision()
+}
+
+// catalogRevision returns the revision of the catalog from which responses to the given filter are
+// derived, if known, qualified by the revision of any advisories attached to its plugins.
+//
+// Responses including download counts may change without the catalog changing, and so cannot be
+// identified by its revision.
+func catalogRevision(c *Context, filter *model.PluginFilter) string {
+	if includesDownloadCounts(c, filter) {
+		return ""
+	}
+
+	revision := storeRevision(c.Store)
+	if revision != "" && c.Advisories != nil {
+		revision += " " + c.Advisories.Revision()
+	}
+
+	return revision
+}
+
+// normalizeFilter returns a canonical encoding of the given filter, such that filters yielding the
+// same results encode identically.
+func normalizeFilter(filter *model.PluginFilter) []byte {
+	normalized := *filter
+	normalized.Filter = strings.ToLower(strings.TrimSpace(normalized.Filter))
+	normalized.PluginID = strings.TrimSpace(normalized.PluginID)
+
+	data, _ := json.Marshal(normalized)
+	return data
+}
+
+// computeETag derives a strong entity tag from the given parts.
+func computeETag(parts ...[]byte) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		_, _ = hash.Write(part)
+		_, _ = hash.Write([]byte{0})
+	}
+
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// etagMatches reports whether the If-None-Match header value matches the given entity tag, using
+// the weak comparison mandated by RFC 7232.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+
+		if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// notModified reports whether the client already holds the representation identified by the
+// given entity tag and modification time.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return etagMatches(ifNoneMatch, etag)
+	}
+
+	if lastModified.IsZero() {
+		return false
+	}
+
+	ifModifiedSince, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	return !lastModified.Truncate(time.Second).After(ifModifiedSince)
+}
+
+// lastModified returns the time at which the most recently updated of the given plugins was added.
+func lastModified(plugins []*model.Plugin) time.Time {
+	var result time.Time
+	for _, plugin := range plugins {
+		if plugin.UpdatedAt.After(result) {
+			result = plugin.UpdatedAt
+		}
+	}
+
+	return result
+}
+
+// surrogateKeys returns the keys under which a CDN should index a response containing the given
+// plugins, allowing it to be purged whenever any one of those plugins changes.
+func surrogateKeys(plugins []*model.Plugin) string {
+	keys := []string{"plugins"}
+	seen := make(map[string]bool)
+	for _, plugin := range plugins {
+		if plugin.Manifest == nil || seen[plugin.Manifest.Id] {
+			continue
+		}
+		seen[plugin.Manifest.Id] = true
+
+		keys = append(keys, "plugin:"+plugin.Manifest.Id)
+	}
+
+	return strings.Join(keys, " ")
+}
+
+// outputCachedPlugins writes the given response as JSON alongside caching headers describing the
+// plugins it contains, responding with 304 Not Modified instead if the client's cached copy is
+// still current. The variant distinguishes differently shaped responses to the same filter.
+//
+// If the store cannot identify its revision, the entity tag is derived from the encoded response.
+func outputCachedPlugins(c *Context, w http.ResponseWriter, r *http.Request, filter *model.PluginFilter, variant string, response interface{}, plugins []*model.Plugin) {
+	var body bytes.Buffer
+	err := json.NewEncoder(&body).Encode(response)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to encode result")
+		outputError(c, w, err)
+		return
+	}
+
+	outputCached(c, w, r, filter, variant, "application/json", body.Bytes(), plugins)
+}
+
+// outputCached writes the given encoded body alongside caching headers describing the plugins it
+// contains, responding with 304 Not Modified instead if the client's cached copy is still current.
+func outputCached(c *Context, w http.ResponseWriter, r *http.Request, filter *model.PluginFilter, variant, contentType string, body []byte, plugins []*model.Plugin) {
+	etag := ""
+	if revision := catalogRevision(c, filter); revision != "" {
+		etag = computeETag([]byte(revision), normalizeFilter(filter), []byte(variant))
+	} else {
+		etag = computeETag(normalizeFilter(filter), []byte(variant), body)
+	}
+	// Download counts change without updating the plugins, so cannot be validated by time.
+	var modified time.Time
+	if !includesDownloadCounts(c, filter) {
+		modified = lastModified(plugins)
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", pluginsCacheControlFor(c))
+	w.Header().Set("Surrogate-Key", surrogateKeys(plugins))
+	if !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, modified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	_, err := w.Write(body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to write result")
+	}
+}
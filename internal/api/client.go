@@ -1,11 +1,19 @@
 package api
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math/rand"
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -13,17 +21,50 @@ import (
 )
 
 // Client is the programmatic interface to the Plugin Marketplace API.
+//
+// Idempotent requests failing with a network error or a server error are retried with a jittered
+// exponential backoff. Every method has a variant accepting a context, bounding the request
+// including any retries.
 type Client struct {
-	Address    string
+	Address string
+	Token   string // Sent as a bearer token with every request, if set
+
+	// APIKey authenticates every request, if set, granting access to private plugins. If APIKeyID
+	// is also set, requests are signed with the key rather than presenting it.
+	APIKey   string
+	APIKeyID string
+
 	httpClient *http.Client
+	userAgent  string
+	headers    http.Header
+	basePath   string
+	cache      ResponseCache
+
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
 }
 
 // NewClient creates a client to the Plugin Marketplace at the given address.
-func NewClient(address string) *Client {
-	return &Client{
-		Address:    address,
-		httpClient: &http.Client{},
+func NewClient(address string, options ...ClientOption) *Client {
+	c := &Client{
+		Address: address,
+		httpClient: &http.Client{
+			Transport: defaultTransport,
+			Timeout:   defaultClientTimeout,
+		},
+		userAgent:  defaultUserAgent,
+		headers:    make(http.Header),
+		maxRetries: defaultMaxRetries,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+	}
+
+	for _, option := range options {
+		option(c)
 	}
+
+	return c
 }
 
 // closeBody ensures the Body of an http.Response is properly closed.
@@ -35,15 +76,160 @@ func closeBody(r *http.Response) {
 }
 
 func (c *Client) buildURL(urlPath string, args ...interface{}) string {
-	return fmt.Sprintf("%s/%s", strings.TrimRight(c.Address, "/"), strings.TrimLeft(fmt.Sprintf(urlPath, args...), "/"))
+	address := strings.TrimRight(c.Address, "/")
+	if c.basePath != "" {
+		address += "/" + c.basePath
+	}
+
+	return fmt.Sprintf("%s/%s", address, strings.TrimLeft(fmt.Sprintf(urlPath, args...), "/"))
+}
+
+func (c *Client) doGet(ctx context.Context, u string) (*http.Response, error) {
+	return c.doRequest(ctx, http.MethodGet, u, nil)
+}
+
+func (c *Client) doPost(ctx context.Context, u string, body interface{}) (*http.Response, error) {
+	return c.doRequest(ctx, http.MethodPost, u, body)
+}
+
+// doRequest sends a request with the given body, if any, encoded as JSON.
+func (c *Client) doRequest(ctx context.Context, method, u string, body interface{}) (*http.Response, error) {
+	if body == nil {
+		return c.do(ctx, method, u, "", nil)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode request body")
+	}
+
+	return c.do(ctx, method, u, "application/json", data)
+}
+
+// do sends a request with the given encoded body, if any, retrying idempotent requests that fail
+// with a network error or a server error. The response to the last attempt is returned, served
+// from the cache if the server reports that the cached response is current.
+func (c *Client) do(ctx context.Context, method, u, contentType string, body []byte) (*http.Response, error) {
+	maxRetries := c.maxRetries
+	if !isIdempotent(method) {
+		maxRetries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := c.newRequest(ctx, method, u, contentType, body)
+		if err != nil {
+			return nil, err
+		}
+
+		key := c.revalidate(req)
+
+		resp, err := c.httpClient.Do(req)
+		if attempt >= maxRetries || !shouldRetry(resp, err) || ctx.Err() != nil {
+			if err != nil {
+				return nil, err
+			}
+
+			return c.fromCache(key, resp)
+		}
+		if resp != nil {
+			closeBody(resp)
+		}
+
+		timer := time.NewTimer(c.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// newRequest creates a single attempt of a request, authenticated anew such that signatures are
+// current.
+func (c *Client) newRequest(ctx context.Context, method, u, contentType string, body []byte) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	for name, values := range c.headers {
+		req.Header[name] = values
+	}
+	c.authenticate(req)
+
+	return req, nil
+}
+
+// isIdempotent reports whether a request with the given method may safely be sent more than once.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// shouldRetry reports whether a request yielding the given response or error may succeed if
+// retried.
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	return resp.StatusCode >= http.StatusInternalServerError && resp.StatusCode != http.StatusNotImplemented
+}
+
+// backoff returns the delay before retrying a request after the given number of failed attempts,
+// doubling with each attempt up to the maximum. Half of the delay is randomized so that clients
+// failing together do not retry together.
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.minBackoff
+	for i := 0; i < attempt && delay < c.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > c.maxBackoff {
+		delay = c.maxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
 }
 
-func (c *Client) doGet(u string) (*http.Response, error) {
-	return c.httpClient.Get(u)
+// authenticate adds the configured credentials to the given request.
+func (c *Client) authenticate(req *http.Request) {
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	if c.APIKey != "" && c.APIKeyID != "" {
+		SignRequest(req, c.APIKeyID, c.APIKey, time.Now())
+	} else if c.APIKey != "" {
+		req.Header.Set(HeaderAPIKey, c.APIKey)
+	}
 }
 
 // GetPlugins fetches the list of plugins from the configured server.
 func (c *Client) GetPlugins(request *GetPluginsRequest) ([]*model.Plugin, error) {
+	return c.GetPluginsWithContext(context.Background(), request)
+}
+
+// GetPluginsWithContext is GetPlugins bounded by the given context.
+func (c *Client) GetPluginsWithContext(ctx context.Context, request *GetPluginsRequest) ([]*model.Plugin, error) {
 	u, err := url.Parse(c.buildURL("/api/v1/plugins"))
 	if err != nil {
 		return nil, err
@@ -51,7 +237,7 @@ func (c *Client) GetPlugins(request *GetPluginsRequest) ([]*model.Plugin, error)
 
 	request.ApplyToURL(u)
 
-	resp, err := c.doGet(u.String())
+	resp, err := c.doGet(ctx, u.String())
 	if err != nil {
 		return nil, err
 	}
@@ -61,20 +247,96 @@ func (c *Client) GetPlugins(request *GetPluginsRequest) ([]*model.Plugin, error)
 	case http.StatusOK:
 		return model.PluginsFromReader(resp.Body)
 	default:
-		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+		return nil, errorFromResponse(resp)
 	}
 }
 
-// GetPlugin fetches all the specified version of a single plugin
-func (c *Client) GetPlugin(request *GetPluginsRequest, pluginid string) ([]*model.Plugin, error) {
-	u, err := url.Parse(c.buildURL("/api/v1/plugin/" + pluginid))
+// GetPluginsPage fetches a page of plugins from the configured server, alongside the total number
+// of matching plugins and the cursor from which to fetch the next page.
+//
+// If the server does not support returning the total, it is reported as -1.
+func (c *Client) GetPluginsPage(request *GetPluginsRequest) (*PluginsResponse, error) {
+	return c.GetPluginsPageWithContext(context.Background(), request)
+}
+
+// GetPluginsPageWithContext is GetPluginsPage bounded by the given context.
+func (c *Client) GetPluginsPageWithContext(ctx context.Context, request *GetPluginsRequest) (*PluginsResponse, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/plugins"))
 	if err != nil {
 		return nil, err
 	}
 
 	request.ApplyToURL(u)
+	q := u.Query()
+	q.Set("envelope", "true")
+	u.RawQuery = q.Encode()
 
-	resp, err := c.doGet(u.String())
+	resp, err := c.doGet(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return pluginsResponseFromReader(resp)
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// pluginsResponseFromReader decodes a PluginsResponse from the given response, tolerating servers
+// predating the envelope that respond with a bare list of plugins.
+func pluginsResponseFromReader(resp *http.Response) (*PluginsResponse, error) {
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read response")
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] == '[' {
+		plugins, err := model.PluginsFromReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		total := -1
+		if totalHeader := resp.Header.Get("X-Total-Count"); totalHeader != "" {
+			total, err = strconv.Atoi(totalHeader)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse X-Total-Count header")
+			}
+		}
+
+		return &PluginsResponse{
+			Plugins: plugins,
+			Total:   total,
+		}, nil
+	}
+
+	var response PluginsResponse
+	err = json.Unmarshal(data, &response)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse response")
+	}
+
+	return &response, nil
+}
+
+// GetPlugin fetches the versions of a single plugin compatible with the given request.
+func (c *Client) GetPlugin(request *GetPluginsRequest, pluginID string) ([]*model.Plugin, error) {
+	return c.GetPluginWithContext(context.Background(), request, pluginID)
+}
+
+// GetPluginWithContext is GetPlugin bounded by the given context.
+func (c *Client) GetPluginWithContext(ctx context.Context, request *GetPluginsRequest, pluginID string) ([]*model.Plugin, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/plugins/%s", url.PathEscape(pluginID)))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(ctx, u.String())
 	if err != nil {
 		return nil, err
 	}
@@ -84,6 +346,371 @@ func (c *Client) GetPlugin(request *GetPluginsRequest, pluginid string) ([]*mode
 	case http.StatusOK:
 		return model.PluginsFromReader(resp.Body)
 	default:
-		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// GetPluginVersion fetches the given version of a single plugin, if compatible with the given request.
+func (c *Client) GetPluginVersion(request *GetPluginsRequest, pluginID, version string) (*model.Plugin, error) {
+	return c.GetPluginVersionWithContext(context.Background(), request, pluginID, version)
+}
+
+// GetPluginVersionWithContext is GetPluginVersion bounded by the given context.
+func (c *Client) GetPluginVersionWithContext(ctx context.Context, request *GetPluginsRequest, pluginID, version string) (*model.Plugin, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/plugins/%s/versions/%s", url.PathEscape(pluginID), url.PathEscape(version)))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return model.PluginFromReader(resp.Body)
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// GetPluginCompatibility explains which versions of a single plugin are compatible with the given
+// request, and why.
+func (c *Client) GetPluginCompatibility(request *GetPluginsRequest, pluginID string) (*model.PluginCompatibility, error) {
+	return c.GetPluginCompatibilityWithContext(context.Background(), request, pluginID)
+}
+
+// GetPluginCompatibilityWithContext is GetPluginCompatibility bounded by the given context.
+func (c *Client) GetPluginCompatibilityWithContext(ctx context.Context, request *GetPluginsRequest, pluginID string) (*model.PluginCompatibility, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/plugins/%s/compatibility", url.PathEscape(pluginID)))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var compatibility model.PluginCompatibility
+		err = json.NewDecoder(resp.Body).Decode(&compatibility)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse response")
+		}
+
+		return &compatibility, nil
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// GetPluginIcon fetches the icon of the latest version of a plugin compatible with the given
+// request, returning the icon alongside its content type.
+func (c *Client) GetPluginIcon(request *GetPluginsRequest, pluginID string) ([]byte, string, error) {
+	return c.GetPluginIconWithContext(context.Background(), request, pluginID)
+}
+
+// GetPluginIconWithContext is GetPluginIcon bounded by the given context.
+func (c *Client) GetPluginIconWithContext(ctx context.Context, request *GetPluginsRequest, pluginID string) ([]byte, string, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/plugins/%s/icon", url.PathEscape(pluginID)))
+	if err != nil {
+		return nil, "", err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(ctx, u.String())
+	if err != nil {
+		return nil, "", err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		icon, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, "", errors.Wrap(err, "failed to read icon")
+		}
+
+		return icon, resp.Header.Get("Content-Type"), nil
+	default:
+		return nil, "", errorFromResponse(resp)
+	}
+}
+
+// GetPluginStats fetches the download statistics of a plugin.
+func (c *Client) GetPluginStats(pluginID string) (*model.PluginStats, error) {
+	return c.GetPluginStatsWithContext(context.Background(), pluginID)
+}
+
+// GetPluginStatsWithContext is GetPluginStats bounded by the given context.
+func (c *Client) GetPluginStatsWithContext(ctx context.Context, pluginID string) (*model.PluginStats, error) {
+	resp, err := c.doGet(ctx, c.buildURL("/api/v1/plugins/%s/stats", url.PathEscape(pluginID)))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var stats model.PluginStats
+		err = json.NewDecoder(resp.Body).Decode(&stats)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse response")
+		}
+
+		return &stats, nil
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// GetPluginUpdates fetches the newest compatible version of each of the given installed plugins.
+func (c *Client) GetPluginUpdates(request *PluginUpdatesRequest) (*PluginUpdatesResponse, error) {
+	return c.GetPluginUpdatesWithContext(context.Background(), request)
+}
+
+// GetPluginUpdatesWithContext is GetPluginUpdates bounded by the given context.
+func (c *Client) GetPluginUpdatesWithContext(ctx context.Context, request *PluginUpdatesRequest) (*PluginUpdatesResponse, error) {
+	resp, err := c.doPost(ctx, c.buildURL("/api/v1/plugins/updates"), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var response PluginUpdatesResponse
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse response")
+		}
+
+		return &response, nil
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// GetLabels fetches the labels assigned to plugins by the configured server.
+func (c *Client) GetLabels() ([]model.Label, error) {
+	return c.GetLabelsWithContext(context.Background())
+}
+
+// GetLabelsWithContext is GetLabels bounded by the given context.
+func (c *Client) GetLabelsWithContext(ctx context.Context) ([]model.Label, error) {
+	resp, err := c.doGet(ctx, c.buildURL("/api/v1/labels"))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var labels []model.Label
+		err = json.NewDecoder(resp.Body).Decode(&labels)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse response")
+		}
+
+		return labels, nil
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// GetAdvisories fetches the security advisories matching the given request from the configured
+// server.
+func (c *Client) GetAdvisories(request *GetAdvisoriesRequest) ([]*model.Advisory, error) {
+	return c.GetAdvisoriesWithContext(context.Background(), request)
+}
+
+// GetAdvisoriesWithContext is GetAdvisories bounded by the given context.
+func (c *Client) GetAdvisoriesWithContext(ctx context.Context, request *GetAdvisoriesRequest) ([]*model.Advisory, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/advisories"))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var advisories []*model.Advisory
+		err = json.NewDecoder(resp.Body).Decode(&advisories)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse response")
+		}
+
+		return advisories, nil
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// HealthCheck reports the health of the configured server, failing unless the server is reachable
+// and reports itself healthy.
+func (c *Client) HealthCheck() (*HealthCheckResponse, error) {
+	return c.HealthCheckWithContext(context.Background())
+}
+
+// HealthCheckWithContext is HealthCheck bounded by the given context.
+func (c *Client) HealthCheckWithContext(ctx context.Context) (*HealthCheckResponse, error) {
+	resp, err := c.doGet(ctx, c.buildURL("/api/v1/health"))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var health HealthCheckResponse
+		err = json.NewDecoder(resp.Body).Decode(&health)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse response")
+		}
+		if health.Status != HealthStatusPass {
+			return &health, errors.Errorf("server reported status %s", health.Status)
+		}
+
+		return &health, nil
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// AddPlugin adds a new version of a plugin to the catalog through the admin API.
+func (c *Client) AddPlugin(plugin *model.Plugin) (*model.Plugin, error) {
+	return c.AddPluginWithContext(context.Background(), plugin)
+}
+
+// AddPluginWithContext is AddPlugin bounded by the given context.
+func (c *Client) AddPluginWithContext(ctx context.Context, plugin *model.Plugin) (*model.Plugin, error) {
+	resp, err := c.doPost(ctx, c.buildURL("/api/v1/admin/plugins"), plugin)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		return model.PluginFromReader(resp.Body)
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// UpdatePlugin replaces an existing version of a plugin in the catalog through the admin API.
+func (c *Client) UpdatePlugin(plugin *model.Plugin) (*model.Plugin, error) {
+	return c.UpdatePluginWithContext(context.Background(), plugin)
+}
+
+// UpdatePluginWithContext is UpdatePlugin bounded by the given context.
+func (c *Client) UpdatePluginWithContext(ctx context.Context, plugin *model.Plugin) (*model.Plugin, error) {
+	if plugin.Manifest == nil {
+		return nil, errors.New("plugin is missing a manifest")
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodPut, c.buildURL("/api/v1/admin/plugins/%s/versions/%s", url.PathEscape(plugin.Manifest.Id), url.PathEscape(plugin.Manifest.Version)), plugin)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return model.PluginFromReader(resp.Body)
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// DeletePlugin removes a version of a plugin from the catalog through the admin API, or every
+// version if the given version is empty.
+func (c *Client) DeletePlugin(pluginID, version string) error {
+	return c.DeletePluginWithContext(context.Background(), pluginID, version)
+}
+
+// DeletePluginWithContext is DeletePlugin bounded by the given context.
+func (c *Client) DeletePluginWithContext(ctx context.Context, pluginID, version string) error {
+	u := c.buildURL("/api/v1/admin/plugins/%s", url.PathEscape(pluginID))
+	if version != "" {
+		u = c.buildURL("/api/v1/admin/plugins/%s/versions/%s", url.PathEscape(pluginID), url.PathEscape(version))
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodDelete, u, nil)
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return nil
+	default:
+		return errorFromResponse(resp)
+	}
+}
+
+// UploadBundle adds the plugin described by the given gzipped bundle and its signature to the
+// catalog through the admin API, to be served by the marketplace itself.
+func (c *Client) UploadBundle(bundle, signature io.Reader) (*model.Plugin, error) {
+	return c.UploadBundleWithContext(context.Background(), bundle, signature)
+}
+
+// UploadBundleWithContext is UploadBundle bounded by the given context.
+func (c *Client) UploadBundleWithContext(ctx context.Context, bundle, signature io.Reader) (*model.Plugin, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	for _, file := range []struct {
+		field, filename string
+		reader          io.Reader
+	}{
+		{"bundle", "plugin.tar.gz", bundle},
+		{"signature", "plugin.tar.gz.sig", signature},
+	} {
+		part, err := writer.CreateFormFile(file.field, file.filename)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create %s part", file.field)
+		}
+
+		_, err = io.Copy(part, file.reader)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to write %s", file.field)
+		}
+	}
+
+	err := writer.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode request body")
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, c.buildURL("/api/v1/admin/bundles"), writer.FormDataContentType(), body.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		return model.PluginFromReader(resp.Body)
+	default:
+		return nil, errorFromResponse(resp)
 	}
 }
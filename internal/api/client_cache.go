@@ -0,0 +1,206 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// CachedResponse is a response retained by a ResponseCache, to be reused whenever the server
+// reports that it has not been modified.
+type CachedResponse struct {
+	ETag   string      `json:"etag"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// ResponseCache retains responses carrying an ETag, keyed by request. Failing to retain a response
+// does not fail the request.
+type ResponseCache interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, response *CachedResponse) error
+}
+
+// WithCache retains responses to GET requests in the given cache, revalidating them with
+// If-None-Match and reusing them whenever the server responds 304 Not Modified.
+func WithCache(cache ResponseCache) ClientOption {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// MemoryCache is a ResponseCache held in memory for the lifetime of the process.
+type MemoryCache struct {
+	lock      sync.RWMutex
+	responses map[string]*CachedResponse
+}
+
+// NewMemoryCache creates an empty in-memory response cache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		responses: make(map[string]*CachedResponse),
+	}
+}
+
+// Get returns the response cached under the given key, if any.
+func (cache *MemoryCache) Get(key string) (*CachedResponse, bool) {
+	cache.lock.RLock()
+	defer cache.lock.RUnlock()
+
+	response, ok := cache.responses[key]
+	return response, ok
+}
+
+// Set caches the given response under the given key.
+func (cache *MemoryCache) Set(key string, response *CachedResponse) error {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	cache.responses[key] = response
+	return nil
+}
+
+// DiskCache is a ResponseCache persisted as files in a local directory, allowing responses to be
+// reused across runs of a process.
+type DiskCache struct {
+	path string
+}
+
+// NewDiskCache creates a response cache persisted in the given directory, creating it if needed.
+func NewDiskCache(path string) (*DiskCache, error) {
+	err := os.MkdirAll(path, 0700)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create cache directory %s", path)
+	}
+
+	return &DiskCache{path: path}, nil
+}
+
+// filename returns the file in which the response for the given key is cached.
+func (cache *DiskCache) filename(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(cache.path, hex.EncodeToString(hash[:])+".json")
+}
+
+// Get returns the response cached under the given key, if any. A cached response that cannot be
+// read is treated as absent.
+func (cache *DiskCache) Get(key string) (*CachedResponse, bool) {
+	data, err := ioutil.ReadFile(cache.filename(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var response CachedResponse
+	err = json.Unmarshal(data, &response)
+	if err != nil {
+		return nil, false
+	}
+
+	return &response, true
+}
+
+// Set caches the given response under the given key, replacing the file atomically such that
+// concurrent readers never observe a partial response.
+func (cache *DiskCache) Set(key string, response *CachedResponse) error {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode cached response")
+	}
+
+	file, err := ioutil.TempFile(cache.path, ".response-*")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary file")
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to write cached response")
+	}
+
+	err = os.Rename(file.Name(), cache.filename(key))
+	if err != nil {
+		return errors.Wrap(err, "failed to replace cached response")
+	}
+
+	return nil
+}
+
+// cacheKey identifies the representation requested by the given request, distinguishing callers
+// whose credentials or locale may yield a different response to the same URL.
+func cacheKey(req *http.Request) string {
+	credentials := req.Header.Get("Authorization") + "\x00" + req.Header.Get(HeaderAPIKey) + "\x00" + req.Header.Get(HeaderAPIKeyID)
+	hash := sha256.Sum256([]byte(credentials))
+
+	return req.URL.String() + "\x00" + hex.EncodeToString(hash[:]) + "\x00" + req.Header.Get("Accept-Language")
+}
+
+// revalidate makes the given request conditional on the response cached for it, if any,
+// returning the cache key of the request. Only GET requests are cached.
+func (c *Client) revalidate(req *http.Request) string {
+	if c.cache == nil || req.Method != http.MethodGet {
+		return ""
+	}
+
+	key := cacheKey(req)
+	if cached, ok := c.cache.Get(key); ok && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	return key
+}
+
+// fromCache serves a 304 Not Modified response from the cache, and caches any other successful
+// response carrying an ETag. The returned response always carries a readable body.
+func (c *Client) fromCache(key string, resp *http.Response) (*http.Response, error) {
+	if key == "" {
+		return resp, nil
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		cached, ok := c.cache.Get(key)
+		if !ok {
+			return resp, nil
+		}
+		closeBody(resp)
+
+		resp.StatusCode = http.StatusOK
+		resp.Status = "200 " + http.StatusText(http.StatusOK)
+		resp.Header = cached.Header.Clone()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(cached.Body))
+		resp.ContentLength = int64(len(cached.Body))
+
+		return resp, nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read response")
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	_ = c.cache.Set(key, &CachedResponse{
+		ETag:   etag,
+		Header: resp.Header.Clone(),
+		Body:   body,
+	})
+
+	return resp, nil
+}
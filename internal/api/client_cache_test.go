@@ -0,0 +1,86 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/api"
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+func TestClientCache(t *testing.T) {
+	version := "1.0.0"
+
+	// The server responds 304 Not Modified while the client's copy names the current version.
+	var statusCodes []int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := `"` + version + `"`
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			statusCodes = append(statusCodes, http.StatusNotModified)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		statusCodes = append(statusCodes, http.StatusOK)
+		_ = json.NewEncoder(w).Encode([]*model.Plugin{{
+			Manifest: &mattermostModel.Manifest{Id: "matterpoll", Version: version},
+		}})
+	}))
+	defer ts.Close()
+
+	// getVersion fetches the version of the only plugin through the given client.
+	getVersion := func(t *testing.T, client *api.Client) string {
+		t.Helper()
+
+		plugins, err := client.GetPlugins(&api.GetPluginsRequest{PerPage: 10})
+		require.NoError(t, err)
+		require.Len(t, plugins, 1)
+
+		return plugins[0].Manifest.Version
+	}
+
+	diskCache, err := api.NewDiskCache(t.TempDir())
+	require.NoError(t, err)
+
+	testCases := map[string]api.ResponseCache{
+		"memory": api.NewMemoryCache(),
+		"disk":   diskCache,
+	}
+
+	for name, cache := range testCases {
+		cache := cache
+		t.Run(name, func(t *testing.T) {
+			version = "1.0.0"
+			statusCodes = nil
+
+			client := api.NewClient(ts.URL, api.WithCache(cache))
+			require.Equal(t, "1.0.0", getVersion(t, client))
+			require.Equal(t, "1.0.0", getVersion(t, client))
+			require.Equal(t, []int{http.StatusOK, http.StatusNotModified}, statusCodes)
+
+			// A client sharing the cache reuses its responses.
+			require.Equal(t, "1.0.0", getVersion(t, api.NewClient(ts.URL, api.WithCache(cache))))
+			require.Equal(t, http.StatusNotModified, statusCodes[2])
+
+			version = "1.1.0"
+			require.Equal(t, "1.1.0", getVersion(t, client))
+			require.Equal(t, "1.1.0", getVersion(t, client))
+			require.Equal(t, []int{http.StatusOK, http.StatusNotModified}, statusCodes[3:])
+		})
+	}
+
+	t.Run("without cache", func(t *testing.T) {
+		statusCodes = nil
+
+		client := api.NewClient(ts.URL)
+		getVersion(t, client)
+		getVersion(t, client)
+		require.Equal(t, []int{http.StatusOK, http.StatusOK}, statusCodes)
+	})
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// defaultIteratorPerPage is the page size used by an iterator whose request does not specify one.
+const defaultIteratorPerPage = 100
+
+// PluginsIterator walks every plugin matching a request, fetching one page at a time.
+//
+// Pages are followed by cursor where the server issues one, and otherwise by page number, such
+// that plugins are neither skipped nor repeated as long as the catalog does not change.
+type PluginsIterator struct {
+	client  *Client
+	request GetPluginsRequest
+
+	plugins []*model.Plugin
+	index   int
+	current *model.Plugin
+	done    bool
+	err     error
+}
+
+// IteratePlugins returns an iterator over every plugin matching the given request, starting from
+// the requested page or cursor.
+func (c *Client) IteratePlugins(request *GetPluginsRequest) *PluginsIterator {
+	iteratorRequest := *request
+	iteratorRequest.Facets = false
+	if iteratorRequest.PerPage == 0 {
+		iteratorRequest.PerPage = defaultIteratorPerPage
+	}
+
+	return &PluginsIterator{
+		client:  c,
+		request: iteratorRequest,
+	}
+}
+
+// Next advances the iterator to the next plugin, fetching the next page if needed. It returns
+// false once every plugin has been visited or a request fails, as reported by Err.
+func (it *PluginsIterator) Next(ctx context.Context) bool {
+	for it.index >= len(it.plugins) {
+		if it.done || it.err != nil {
+			it.current = nil
+			return false
+		}
+
+		it.fetch(ctx)
+	}
+
+	it.current = it.plugins[it.index]
+	it.index++
+
+	return true
+}
+
+// fetch requests the next page of plugins, recording whether any further page may follow.
+func (it *PluginsIterator) fetch(ctx context.Context) {
+	response, err := it.client.GetPluginsPageWithContext(ctx, &it.request)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	it.plugins = response.Plugins
+	it.index = 0
+
+	switch {
+	case response.NextCursor != "":
+		it.request.Cursor = response.NextCursor
+		it.request.Page = 0
+	case it.request.Cursor != "" || it.request.PerPage == model.AllPerPage:
+		// Without a further cursor, a page fetched by cursor or holding every plugin is the last.
+		it.done = true
+	case len(response.Plugins) < it.request.PerPage:
+		// A server not issuing cursors signals the last page by a partial page.
+		it.done = true
+	default:
+		it.request.Page++
+	}
+}
+
+// Plugin returns the plugin at the current position of the iterator.
+func (it *PluginsIterator) Plugin() *model.Plugin {
+	return it.current
+}
+
+// Err returns the error that stopped the iterator, if any.
+func (it *PluginsIterator) Err() error {
+	return it.err
+}
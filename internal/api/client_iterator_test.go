@@ -0,0 +1,110 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/api"
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+func TestIteratePlugins(t *testing.T) {
+	var plugins []*model.Plugin
+	var pluginIDs []string
+	for i := 0; i < 5; i++ {
+		pluginID := fmt.Sprintf("plugin-%d", i)
+		plugins = append(plugins, &model.Plugin{
+			Manifest: &mattermostModel.Manifest{Id: pluginID, Name: pluginID, Version: "1.0.0"},
+		})
+		pluginIDs = append(pluginIDs, pluginID)
+	}
+
+	// collect returns the ids of every plugin visited by the iterator.
+	collect := func(t *testing.T, iterator *api.PluginsIterator) []string {
+		t.Helper()
+
+		ids := []string{}
+		for iterator.Next(context.Background()) {
+			ids = append(ids, iterator.Plugin().Manifest.Id)
+		}
+		require.NoError(t, iterator.Err())
+		require.Nil(t, iterator.Plugin())
+
+		return ids
+	}
+
+	client, tearDown := setupAPI(t, plugins)
+	defer tearDown()
+
+	testCases := map[string]struct {
+		request  *api.GetPluginsRequest
+		expected []string
+	}{
+		"partial last page": {&api.GetPluginsRequest{PerPage: 2, Sort: model.SortByID}, pluginIDs},
+		"full last page":    {&api.GetPluginsRequest{PerPage: 5, Sort: model.SortByID}, pluginIDs},
+		"single page":       {&api.GetPluginsRequest{PerPage: model.AllPerPage, Sort: model.SortByID}, pluginIDs},
+		"default page size": {&api.GetPluginsRequest{Sort: model.SortByID}, pluginIDs},
+		"starting page":     {&api.GetPluginsRequest{Page: 1, PerPage: 2, Sort: model.SortByID}, pluginIDs[2:]},
+		"filtered":          {&api.GetPluginsRequest{PerPage: 2, PluginID: "plugin-3"}, []string{"plugin-3"}},
+		"no matches":        {&api.GetPluginsRequest{PerPage: 2, PluginID: "unknown"}, []string{}},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, testCase.expected, collect(t, client.IteratePlugins(testCase.request)))
+		})
+	}
+
+	t.Run("server without cursors", func(t *testing.T) {
+		var requests int
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+
+			start, end := page*perPage, (page+1)*perPage
+			if start > len(plugins) {
+				start = len(plugins)
+			}
+			if end > len(plugins) {
+				end = len(plugins)
+			}
+			_ = json.NewEncoder(w).Encode(plugins[start:end])
+		}))
+		defer ts.Close()
+
+		legacyClient := api.NewClient(ts.URL)
+		require.Equal(t, pluginIDs, collect(t, legacyClient.IteratePlugins(&api.GetPluginsRequest{PerPage: 2})))
+		require.Equal(t, 3, requests)
+	})
+
+	t.Run("page size larger than the server maximum", func(t *testing.T) {
+		iterator := client.IteratePlugins(&api.GetPluginsRequest{PerPage: 500})
+		require.False(t, iterator.Next(context.Background()))
+
+		var apiErr *api.Error
+		require.ErrorAs(t, iterator.Err(), &apiErr)
+		require.Equal(t, "per_page", apiErr.Parameter)
+	})
+
+	t.Run("failed request", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer ts.Close()
+
+		iterator := api.NewClient(ts.URL).IteratePlugins(&api.GetPluginsRequest{})
+		require.False(t, iterator.Next(context.Background()))
+		require.Error(t, iterator.Err())
+		require.False(t, iterator.Next(context.Background()))
+	})
+}
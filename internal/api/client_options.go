@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultClientTimeout bounds each attempt of a request, including reading the response body.
+	defaultClientTimeout = 30 * time.Second
+
+	// defaultUserAgent identifies requests made by the client, unless overridden.
+	defaultUserAgent = "mattermost-marketplace-client"
+
+	// defaultMaxRetries is the number of times a failed idempotent request is retried.
+	defaultMaxRetries = 3
+
+	// defaultMinBackoff and defaultMaxBackoff bound the delay before retrying a request, which
+	// doubles with each attempt.
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 2 * time.Second
+)
+
+// defaultTransport is shared by every client not configured with its own transport, allowing
+// connections to the same marketplace to be reused across clients.
+var defaultTransport = newDefaultTransport()
+
+// newDefaultTransport returns a transport keeping enough idle connections per host to serve
+// concurrent requests to a single marketplace without reconnecting.
+func newDefaultTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = 32
+
+	return transport
+}
+
+// ClientOption configures a Client.
+type ClientOption func(*Client)
+
+// WithTransport sends requests through the given transport, instead of a transport shared by all
+// clients.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.httpClient.Transport = transport
+	}
+}
+
+// WithTimeout bounds each attempt of a request by the given duration, or not at all if zero.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithUserAgent identifies requests by the given User-Agent header.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithAuthHeader sends the given header with every request, such as to authenticate with a gateway
+// in front of the marketplace.
+func WithAuthHeader(name, value string) ClientOption {
+	return func(c *Client) {
+		c.headers.Set(name, value)
+	}
+}
+
+// WithBasePath prefixes the path of every request, for a marketplace served below the root of its
+// address.
+func WithBasePath(basePath string) ClientOption {
+	return func(c *Client) {
+		c.basePath = strings.Trim(basePath, "/")
+	}
+}
+
+// WithRetries retries failed idempotent requests up to the given number of times, waiting between
+// the given bounds before each retry. Retries are disabled if maxRetries is zero.
+func WithRetries(maxRetries int, minBackoff, maxBackoff time.Duration) ClientOption {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
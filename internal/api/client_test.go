@@ -1,9 +1,19 @@
 package api
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
 )
 
 func TestBuildURL(t *testing.T) {
@@ -39,4 +49,168 @@ func TestBuildURL(t *testing.T) {
 			assert.Equal(t, testCase.expected, actual)
 		})
 	}
+
+	t.Run("base path", func(t *testing.T) {
+		client := NewClient("https://example.com/", WithBasePath("/marketplace/"))
+		assert.Equal(t, "https://example.com/marketplace/api/v1/plugins", client.buildURL("/api/v1/plugins"))
+	})
+}
+
+func TestClientOptions(t *testing.T) {
+	var headers http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		_, _ = w.Write([]byte("[]"))
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL,
+		WithUserAgent("custom-agent/1.0"),
+		WithAuthHeader("X-Gateway-Token", "secret"),
+		WithTimeout(time.Second),
+	)
+	_, err := client.GetPlugins(&GetPluginsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "custom-agent/1.0", headers.Get("User-Agent"))
+	assert.Equal(t, "secret", headers.Get("X-Gateway-Token"))
+
+	_, err = NewClient(ts.URL).GetPlugins(&GetPluginsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, defaultUserAgent, headers.Get("User-Agent"))
+	assert.Empty(t, headers.Get("X-Gateway-Token"))
+}
+
+func TestClientRetries(t *testing.T) {
+	// newServer counts the requests it receives, failing the first with the given status code.
+	newServer := func(t *testing.T, failures int32, statusCode int) (*httptest.Server, *int32) {
+		var requests int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) <= failures {
+				w.WriteHeader(statusCode)
+				return
+			}
+
+			_, _ = w.Write([]byte(`[]`))
+		}))
+		t.Cleanup(ts.Close)
+
+		return ts, &requests
+	}
+
+	retries := WithRetries(3, time.Millisecond, 2*time.Millisecond)
+
+	t.Run("server errors are retried", func(t *testing.T) {
+		ts, requests := newServer(t, 2, http.StatusServiceUnavailable)
+
+		plugins, err := NewClient(ts.URL, retries).GetPlugins(&GetPluginsRequest{})
+		require.NoError(t, err)
+		assert.Empty(t, plugins)
+		assert.EqualValues(t, 3, atomic.LoadInt32(requests))
+	})
+
+	t.Run("retries are bounded", func(t *testing.T) {
+		ts, requests := newServer(t, 10, http.StatusBadGateway)
+
+		_, err := NewClient(ts.URL, retries).GetPlugins(&GetPluginsRequest{})
+		require.Error(t, err)
+		assert.Equal(t, http.StatusBadGateway, err.(*Error).StatusCode)
+		assert.EqualValues(t, 4, atomic.LoadInt32(requests))
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		ts, requests := newServer(t, 1, http.StatusBadRequest)
+
+		_, err := NewClient(ts.URL, retries).GetPlugins(&GetPluginsRequest{})
+		require.Error(t, err)
+		assert.EqualValues(t, 1, atomic.LoadInt32(requests))
+	})
+
+	t.Run("non-idempotent requests are not retried", func(t *testing.T) {
+		ts, requests := newServer(t, 1, http.StatusInternalServerError)
+
+		_, err := NewClient(ts.URL, retries).AddPlugin(&model.Plugin{})
+		require.Error(t, err)
+		assert.EqualValues(t, 1, atomic.LoadInt32(requests))
+	})
+
+	t.Run("retries disabled", func(t *testing.T) {
+		ts, requests := newServer(t, 1, http.StatusInternalServerError)
+
+		_, err := NewClient(ts.URL, WithRetries(0, 0, 0)).GetPlugins(&GetPluginsRequest{})
+		require.Error(t, err)
+		assert.EqualValues(t, 1, atomic.LoadInt32(requests))
+	})
+
+	t.Run("network errors are retried", func(t *testing.T) {
+		var attempts int32
+		transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&attempts, 1)
+			return nil, context.DeadlineExceeded
+		})
+
+		_, err := NewClient("http://example.com", retries, WithTransport(transport)).GetPlugins(&GetPluginsRequest{})
+		require.Error(t, err)
+		assert.EqualValues(t, 4, atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("cancelled context stops retrying", func(t *testing.T) {
+		ts, requests := newServer(t, 10, http.StatusServiceUnavailable)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		client := NewClient(ts.URL, WithRetries(3, time.Hour, time.Hour))
+		go func() {
+			for atomic.LoadInt32(requests) == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			cancel()
+		}()
+
+		_, err := client.GetPluginsWithContext(ctx, &GetPluginsRequest{})
+		require.ErrorIs(t, err, context.Canceled)
+		assert.EqualValues(t, 1, atomic.LoadInt32(requests))
+	})
+}
+
+// roundTripperFunc adapts a function to an http.RoundTripper.
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestClientBackoff(t *testing.T) {
+	client := NewClient("", WithRetries(5, 100*time.Millisecond, time.Second))
+
+	for attempt, expected := range []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	} {
+		delay := client.backoff(attempt)
+		assert.GreaterOrEqual(t, int64(delay), int64(expected/2), "attempt %d", attempt)
+		assert.LessOrEqual(t, int64(delay), int64(expected), "attempt %d", attempt)
+	}
+}
+
+func TestClientLabelsAndHealthCheck(t *testing.T) {
+	router := mux.NewRouter()
+	Register(router, &Context{
+		Labels: model.DefaultLabelDefinitions,
+		Logger: logrus.New(),
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := NewClient(ts.URL)
+
+	labels, err := client.GetLabels()
+	require.NoError(t, err)
+	assert.Equal(t, model.AllLabels, labels)
+
+	health, err := client.HealthCheckWithContext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, HealthStatusPass, health.Status)
 }
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// compatibilityExplainer is optionally implemented by a Store able to explain which versions of a
+// plugin match a filter, and why.
+type compatibilityExplainer interface {
+	GetPluginCompatibility(filter *model.PluginFilter) (*model.PluginCompatibility, error)
+}
+
+// handleGetPluginCompatibility responds to GET /api/v1/plugins/{plugin_id}/compatibility, listing
+// every version of a single plugin alongside the rule that included or excluded it for the given
+// filter.
+func handleGetPluginCompatibility(c *Context, w http.ResponseWriter, r *http.Request) {
+	pluginID := mux.Vars(r)["plugin_id"]
+
+	explainer, ok := c.Store.(compatibilityExplainer)
+	if !ok {
+		outputError(c, w, newNotFoundError("compatibility explanations are not supported"))
+		return
+	}
+
+	filter, err := ParsePluginFilter(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Warn("failed to parse plugin filter")
+		outputError(c, w, err)
+		return
+	}
+	filter.PluginID = pluginID
+
+	compatibility, err := explainer.GetPluginCompatibility(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to explain plugin compatibility")
+		outputError(c, w, err)
+		return
+	}
+	if len(compatibility.Versions) == 0 {
+		outputError(c, w, newNotFoundError("plugin %s not found", pluginID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	outputJSON(c, w, compatibility)
+}
@@ -0,0 +1,77 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/api"
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/store"
+	"github.com/mattermost/mattermost-marketplace/internal/testlib"
+)
+
+func TestPluginCompatibility(t *testing.T) {
+	newPlugin := func(version, minServerVersion string) *model.Plugin {
+		return &model.Plugin{
+			Manifest: &mattermostModel.Manifest{
+				Id:               "jira",
+				Name:             "Jira",
+				Version:          version,
+				MinServerVersion: minServerVersion,
+			},
+		}
+	}
+
+	logger := testlib.MakeLogger(t)
+	staticStore, err := store.NewStatic([]*model.Plugin{
+		newPlugin("1.0.0", ""),
+		newPlugin("2.0.0", "5.30.0"),
+	}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{Store: staticStore, Logger: logger})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := api.NewClient(ts.URL)
+
+	t.Run("explains each version", func(t *testing.T) {
+		compatibility, err := client.GetPluginCompatibility(&api.GetPluginsRequest{ServerVersion: "5.20.0"}, "jira")
+		require.NoError(t, err)
+		require.Equal(t, "jira", compatibility.PluginID)
+		require.Len(t, compatibility.Versions, 2)
+
+		require.Equal(t, "2.0.0", compatibility.Versions[0].Version)
+		require.False(t, compatibility.Versions[0].Compatible)
+		require.Equal(t, model.RuleMinServerVersion, compatibility.Versions[0].Rule)
+		require.Equal(t, "requires server 5.30.0 or later, but server 5.20.0 was requested", compatibility.Versions[0].Reason)
+
+		require.Equal(t, "1.0.0", compatibility.Versions[1].Version)
+		require.True(t, compatibility.Versions[1].Compatible)
+		require.Equal(t, model.RuleCompatible, compatibility.Versions[1].Rule)
+	})
+
+	t.Run("unknown plugin", func(t *testing.T) {
+		_, err := client.GetPluginCompatibility(&api.GetPluginsRequest{}, "unknown")
+		require.Error(t, err)
+
+		var apiErr *api.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		_, err := client.GetPluginCompatibility(&api.GetPluginsRequest{ServerVersion: "invalid"}, "jira")
+		require.Error(t, err)
+
+		var apiErr *api.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, "server_version", apiErr.Parameter)
+	})
+}
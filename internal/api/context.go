@@ -1,6 +1,9 @@
 package api
 
 import (
+	"io"
+	"time"
+
 	"github.com/sirupsen/logrus"
 
 	"github.com/mattermost/mattermost-marketplace/internal/model"
@@ -9,13 +12,71 @@ import (
 // Store describes the interface to the backing store.
 type Store interface {
 	GetPlugins(filter *model.PluginFilter) ([]*model.Plugin, error)
+	GetPluginsPage(filter *model.PluginFilter) (*model.PluginsPage, error)
+}
+
+// Stats describes the interface to the download statistics.
+type Stats interface {
+	RecordDownload(download *model.Download) error
+	GetPluginStats(pluginID string) (*model.PluginStats, error)
+	GetDownloadCounts() (map[string]int64, error)
+}
+
+// Advisories describes the interface to the security advisories affecting plugins.
+type Advisories interface {
+	GetAdvisories(filter *model.AdvisoryFilter) ([]*model.Advisory, error)
+	Revision() string
+}
+
+// WritableStore describes the interface to a backing store whose catalog may be changed.
+type WritableStore interface {
+	Store
+	AddPlugin(plugin *model.Plugin) error
+	UpdatePlugin(plugin *model.Plugin) error
+	DeletePlugin(pluginID, version string) error
+}
+
+// Bundles describes the interface to the storage of plugin bundles uploaded through the admin API.
+type Bundles interface {
+	// Put stores a bundle, failing with an error satisfying errors.Is(err, os.ErrExist) rather
+	// than replacing an existing bundle of the same name.
+	Put(name string, data []byte) error
+	Open(name string) (io.ReadSeekCloser, time.Time, error)
+	Delete(name string) error
 }
 
 // Context provides the API with all necessary data and interfaces for responding to requests.
 //
 // It is cloned before each request, allowing per-request changes such as logger annotations.
 type Context struct {
-	Store     Store
+	Store  Store
+	Stats  Stats                    // Optional; downloads are not counted if nil
+	Labels []*model.LabelDefinition // The labels assigned to plugins by the store, as listed by the API
+
+	// Advisories describes the vulnerabilities affecting plugins, attached to each affected
+	// version when listing plugins. No plugin is considered affected if nil.
+	Advisories Advisories
+
+	// AdminStore is changed through the admin API, which is only enabled given both the store
+	// and at least one of the AdminTokens with which to authenticate.
+	AdminStore  WritableStore
+	AdminTokens []string
+
+	// Bundles stores the plugin bundles uploaded through the admin API, for serving from the
+	// marketplace itself. Bundles may not be uploaded if nil.
+	Bundles Bundles
+
+	// PublicURL is the base URL at which the marketplace is served to its clients, from which the
+	// download URLs of uploaded bundles and the links in feeds are built. Bundles may not be
+	// uploaded if empty, and feeds link to the host through which they were requested instead.
+	PublicURL string
+
+	// APIKeys authenticate callers, whose requests are then served from the store of their key.
+	// Anonymous requests are served from Store.
+	APIKeys []*APIKey
+
+	APIKeyID  string   // The key authenticating the current request, if any
+	Locales   []string // The locales preferred by the caller of the current request, if any
 	RequestID string
 	Logger    logrus.FieldLogger
 }
@@ -23,7 +84,15 @@ type Context struct {
 // Clone creates a shallow copy of context, allowing clones to apply per-request changes.
 func (c *Context) Clone() *Context {
 	return &Context{
-		Store:  c.Store,
-		Logger: c.Logger,
+		Store:       c.Store,
+		Stats:       c.Stats,
+		Labels:      c.Labels,
+		Advisories:  c.Advisories,
+		AdminStore:  c.AdminStore,
+		AdminTokens: c.AdminTokens,
+		Bundles:     c.Bundles,
+		PublicURL:   c.PublicURL,
+		APIKeys:     c.APIKeys,
+		Logger:      c.Logger,
 	}
 }
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// Headers describing the bundle to which a download is redirected.
+const (
+	headerPluginVersion   = "X-Plugin-Version"
+	headerPluginSignature = "X-Plugin-Signature" // The base64 encoded signature of the bundle
+)
+
+// handleGetPluginDownload responds to GET /api/v1/plugins/{plugin_id}/download, redirecting to the
+// bundle of the latest compatible version of a plugin, or of the version given by the version
+// parameter. If the platform parameter is given, the bundle built for that platform is preferred.
+//
+// The signature of the bundle is given by the X-Plugin-Signature header. Each download is counted
+// if statistics are enabled.
+func handleGetPluginDownload(c *Context, w http.ResponseWriter, r *http.Request) {
+	plugin, err := resolvePlugin(c, r)
+	if err != nil {
+		c.Logger.WithError(err).Warn("failed to resolve plugin")
+		outputError(c, w, err)
+		return
+	}
+
+	if plugin.DownloadURL == "" {
+		outputError(c, w, newNotFoundError("plugin %s has no bundle", mux.Vars(r)["plugin_id"]))
+		return
+	}
+
+	recordDownload(c, r, plugin)
+
+	setBundleHeaders(c, w, plugin)
+	http.Redirect(w, r, plugin.DownloadURL, http.StatusFound)
+}
+
+// handleGetPluginSignature responds to GET /api/v1/plugins/{plugin_id}/signature, serving the
+// decoded signature of the bundle to which the equivalent download request would redirect.
+func handleGetPluginSignature(c *Context, w http.ResponseWriter, r *http.Request) {
+	plugin, err := resolvePlugin(c, r)
+	if err != nil {
+		c.Logger.WithError(err).Warn("failed to resolve plugin")
+		outputError(c, w, err)
+		return
+	}
+
+	if plugin.Signature == "" {
+		outputError(c, w, newNotFoundError("plugin %s has no signature", mux.Vars(r)["plugin_id"]))
+		return
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(plugin.Signature)
+	if err != nil {
+		c.Logger.WithError(err).Errorf("failed to decode signature of plugin %s", plugin.Manifest.Id)
+		outputError(c, w, errors.Wrap(err, "failed to decode signature"))
+		return
+	}
+
+	setBundleHeaders(c, w, plugin)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	_, err = w.Write(signature)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to write signature")
+	}
+}
+
+// setBundleHeaders describes the resolved bundle of the given plugin.
+//
+// The response may be cached as briefly as a listing, since a newer version may be released.
+func setBundleHeaders(c *Context, w http.ResponseWriter, plugin *model.Plugin) {
+	w.Header().Set(headerPluginVersion, plugin.Manifest.Version)
+	if plugin.Signature != "" {
+		w.Header().Set(headerPluginSignature, plugin.Signature)
+	}
+	w.Header().Set("Cache-Control", pluginsCacheControlFor(c))
+	w.Header().Set("Surrogate-Key", surrogateKeys([]*model.Plugin{plugin}))
+	setYankedHeader(w, plugin)
+}
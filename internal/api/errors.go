@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Error codes identifying the reason a request failed.
+const (
+	ErrorCodeInvalidParameter = "invalid_parameter"
+	ErrorCodeNotFound         = "not_found"
+	ErrorCodeUnauthorized     = "unauthorized"
+	ErrorCodeConflict         = "conflict"
+	ErrorCodeMethodNotAllowed = "method_not_allowed"
+	ErrorCodeInternal         = "internal_error"
+	ErrorCodeUnknown          = "unknown"
+)
+
+// Error is the JSON body sent alongside an unsuccessful status code.
+//
+// The Client decodes failed responses into an *Error, allowing callers to branch on the Code or
+// Parameter using errors.As.
+type Error struct {
+	StatusCode int    `json:"status_code"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	Parameter  string `json:"parameter,omitempty"` // The query or path parameter that was rejected, if any
+	RequestID  string `json:"request_id,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	if e.Parameter != "" {
+		return fmt.Sprintf("%s (status %d, parameter %s): %s", e.Code, e.StatusCode, e.Parameter, e.Message)
+	}
+
+	return fmt.Sprintf("%s (status %d): %s", e.Code, e.StatusCode, e.Message)
+}
+
+// newInvalidParameterError describes a request rejected because of the given parameter.
+func newInvalidParameterError(parameter string, err error) *Error {
+	return &Error{
+		StatusCode: http.StatusBadRequest,
+		Code:       ErrorCodeInvalidParameter,
+		Message:    err.Error(),
+		Parameter:  parameter,
+	}
+}
+
+// newNotFoundError describes a request for a resource that does not exist.
+func newNotFoundError(format string, args ...interface{}) *Error {
+	return &Error{
+		StatusCode: http.StatusNotFound,
+		Code:       ErrorCodeNotFound,
+		Message:    fmt.Sprintf(format, args...),
+	}
+}
+
+// newUnauthorizedError describes a request lacking valid credentials.
+func newUnauthorizedError(format string, args ...interface{}) *Error {
+	return &Error{
+		StatusCode: http.StatusUnauthorized,
+		Code:       ErrorCodeUnauthorized,
+		Message:    fmt.Sprintf(format, args...),
+	}
+}
+
+// newConflictError describes a request conflicting with the current state of a resource.
+func newConflictError(format string, args ...interface{}) *Error {
+	return &Error{
+		StatusCode: http.StatusConflict,
+		Code:       ErrorCodeConflict,
+		Message:    fmt.Sprintf(format, args...),
+	}
+}
+
+// newInternalError describes an unexpected failure, deliberately omitting the details.
+func newInternalError() *Error {
+	return &Error{
+		StatusCode: http.StatusInternalServerError,
+		Code:       ErrorCodeInternal,
+		Message:    "an internal error occurred",
+	}
+}
+
+// outputError writes the given error as JSON alongside its status code.
+//
+// Errors other than an *Error are reported as an internal error, avoiding leaking their details.
+func outputError(c *Context, w http.ResponseWriter, err error) {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		apiErr = newInternalError()
+	}
+
+	response := *apiErr
+	response.RequestID = c.RequestID
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+	outputJSON(c, w, response)
+}
+
+// errorFromResponse decodes the *Error describing the given unsuccessful response, synthesizing
+// one from the status code if the server did not describe the error.
+func errorFromResponse(resp *http.Response) error {
+	apiErr := &Error{}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err == nil {
+		err = json.Unmarshal(data, apiErr)
+	}
+	if err != nil || apiErr.Code == "" {
+		apiErr = &Error{
+			Code:    ErrorCodeUnknown,
+			Message: fmt.Sprintf("failed with status code %d", resp.StatusCode),
+		}
+	}
+	apiErr.StatusCode = resp.StatusCode
+
+	return apiErr
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorFromResponse(t *testing.T) {
+	t.Run("described error", func(t *testing.T) {
+		err := errorFromResponse(&http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"status_code":404,"code":"not_found","message":"plugin x not found","request_id":"abc"}`))),
+		})
+
+		assert.Equal(t, &Error{
+			StatusCode: http.StatusNotFound,
+			Code:       ErrorCodeNotFound,
+			Message:    "plugin x not found",
+			RequestID:  "abc",
+		}, err)
+		assert.EqualError(t, err, "not_found (status 404): plugin x not found")
+	})
+
+	t.Run("undescribed error", func(t *testing.T) {
+		err := errorFromResponse(&http.Response{
+			StatusCode: http.StatusBadGateway,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(`<html>Bad Gateway</html>`))),
+		})
+
+		assert.Equal(t, &Error{
+			StatusCode: http.StatusBadGateway,
+			Code:       ErrorCodeUnknown,
+			Message:    "failed with status code 502",
+		}, err)
+	})
+}
+
+func TestUnknownEndpoint(t *testing.T) {
+	router := mux.NewRouter()
+
+	Register(router, &Context{
+		Logger: logrus.New(),
+	})
+
+	testCases := map[string]struct {
+		method     string
+		path       string
+		statusCode int
+		code       string
+	}{
+		"unknown path":       {http.MethodGet, "/api/v1/unknown", http.StatusNotFound, ErrorCodeNotFound},
+		"unknown subpath":    {http.MethodGet, "/api/v1/plugins/demo/unknown", http.StatusNotFound, ErrorCodeNotFound},
+		"unsupported method": {http.MethodDelete, "/api/v1/labels", http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(testCase.method, testCase.path, nil)
+			router.ServeHTTP(w, r)
+
+			result := w.Result()
+			defer result.Body.Close()
+			require.Equal(t, testCase.statusCode, result.StatusCode)
+
+			apiErr := &Error{}
+			require.NoError(t, json.NewDecoder(result.Body).Decode(apiErr))
+			assert.Equal(t, testCase.code, apiErr.Code)
+			assert.NotEmpty(t, apiErr.RequestID)
+		})
+	}
+}
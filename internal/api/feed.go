@@ -0,0 +1,386 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// feedTitle is the title of every feed of releases.
+const feedTitle = "Mattermost Plugin Marketplace releases"
+
+// Content types of the supported feed formats.
+const (
+	atomContentType     = "application/atom+xml; charset=utf-8"
+	rssContentType      = "application/rss+xml; charset=utf-8"
+	jsonFeedContentType = "application/feed+json; charset=utf-8"
+)
+
+// initFeeds registers feed endpoints on the given router.
+func initFeeds(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	apiRouter.Handle("/feed.atom", addContext(handleGetAtomFeed)).Methods(http.MethodGet)
+	apiRouter.Handle("/feed.rss", addContext(handleGetRSSFeed)).Methods(http.MethodGet)
+	apiRouter.Handle("/feed.json", addContext(handleGetJSONFeed)).Methods(http.MethodGet)
+}
+
+// feedRelease describes a single release of a plugin independently of the feed format.
+type feedRelease struct {
+	id          string // A permanent, absolute URL identifying the release
+	link        string
+	title       string
+	summary     string
+	updatedAt   time.Time
+	labels      []string
+	pluginID    string
+	version     string
+	downloadURL string
+}
+
+// feed describes the releases matching a request independently of the feed format.
+type feed struct {
+	selfURL   string
+	homeURL   string
+	updatedAt time.Time
+	releases  []*feedRelease
+}
+
+// parseFeedFilter parses the plugin filter of a feed from the query string of the given URL.
+//
+// Feeds list every release, newest first, unless return_all_versions=false is requested.
+func parseFeedFilter(u *url.URL) (*model.PluginFilter, error) {
+	filter, err := ParsePluginFilter(u)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.ReturnAllVersions, err = parseBool(u, "return_all_versions", true)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.Sort = model.SortByUpdatedAt
+	filter.SortDirection = model.SortDescending
+	filter.Cursor = ""
+	filter.Facets = false
+	filter.DownloadCounts = false
+	filter.Fields = nil
+
+	return filter, nil
+}
+
+// feedBaseURL returns the base URL from which the absolute links of a feed are built: the
+// configured public URL if any, since request headers may be forged to poison cached feeds, or
+// else the scheme and host through which the given request was received.
+func feedBaseURL(c *Context, r *http.Request) string {
+	if c.PublicURL != "" {
+		return strings.TrimRight(c.PublicURL, "/")
+	}
+
+	return requestBaseURL(r)
+}
+
+// requestBaseURL returns the scheme and host through which the given request was received.
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+
+	return scheme + "://" + r.Host
+}
+
+// newFeed describes the given plugins as releases in a feed served at the given request, with
+// links relative to the given base URL.
+func newFeed(r *http.Request, baseURL string, plugins []*model.Plugin) *feed {
+	result := &feed{
+		selfURL:   baseURL + r.URL.RequestURI(),
+		homeURL:   baseURL + "/api/v1/plugins",
+		updatedAt: lastModified(plugins),
+		releases:  make([]*feedRelease, 0, len(plugins)),
+	}
+	if result.updatedAt.IsZero() {
+		result.updatedAt = time.Unix(0, 0)
+	}
+
+	for _, plugin := range plugins {
+		release := &feedRelease{
+			id:          fmt.Sprintf("%s/api/v1/plugins/%s/versions/%s", baseURL, url.PathEscape(plugin.Manifest.Id), url.PathEscape(plugin.Manifest.Version)),
+			link:        plugin.ReleaseNotesURL,
+			title:       fmt.Sprintf("%s %s", plugin.Manifest.Name, plugin.Manifest.Version),
+			summary:     plugin.Manifest.Description,
+			updatedAt:   plugin.UpdatedAt,
+			pluginID:    plugin.Manifest.Id,
+			version:     plugin.Manifest.Version,
+			downloadURL: plugin.DownloadURL,
+		}
+		if release.link == "" {
+			release.link = plugin.HomepageURL
+		}
+		for _, label := range plugin.Labels {
+			release.labels = append(release.labels, label.Name)
+		}
+
+		result.releases = append(result.releases, release)
+	}
+
+	return result
+}
+
+// handleGetFeed responds with a feed of the releases matching the filter, encoded by the given
+// function.
+func handleGetFeed(c *Context, w http.ResponseWriter, r *http.Request, variant, contentType string, encode func(*feed) ([]byte, error)) {
+	filter, err := parseFeedFilter(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Warn("failed to parse plugin filter")
+		outputError(c, w, err)
+		return
+	}
+
+	plugins, err := c.Store.GetPlugins(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query plugins")
+		outputError(c, w, err)
+		return
+	}
+
+	baseURL := feedBaseURL(c, r)
+	body, err := encode(newFeed(r, baseURL, localizePlugins(c, plugins)))
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to encode feed")
+		outputError(c, w, err)
+		return
+	}
+
+	// Feeds embed absolute URLs, and so differ by the host through which they are served.
+	outputCached(c, w, r, filter, localizedVariant(c, variant+" "+baseURL), contentType, body, plugins)
+}
+
+// handleGetAtomFeed responds to GET /api/v1/feed.atom, returning an Atom feed of releases.
+func handleGetAtomFeed(c *Context, w http.ResponseWriter, r *http.Request) {
+	handleGetFeed(c, w, r, "atom", atomContentType, encodeAtomFeed)
+}
+
+// handleGetRSSFeed responds to GET /api/v1/feed.rss, returning an RSS 2.0 feed of releases.
+func handleGetRSSFeed(c *Context, w http.ResponseWriter, r *http.Request) {
+	handleGetFeed(c, w, r, "rss", rssContentType, encodeRSSFeed)
+}
+
+// handleGetJSONFeed responds to GET /api/v1/feed.json, returning a JSON Feed of releases.
+func handleGetJSONFeed(c *Context, w http.ResponseWriter, r *http.Request) {
+	handleGetFeed(c, w, r, "json", jsonFeedContentType, encodeJSONFeed)
+}
+
+// atomFeed is an Atom feed as specified by RFC 4287.
+type atomFeed struct {
+	XMLName xml.Name     `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string       `xml:"title"`
+	ID      string       `xml:"id"`
+	Updated string       `xml:"updated"`
+	Author  atomPerson   `xml:"author"`
+	Links   []atomLink   `xml:"link"`
+	Entries []*atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Updated    string         `xml:"updated"`
+	Links      []atomLink     `xml:"link"`
+	Summary    string         `xml:"summary,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+// encodeAtomFeed encodes the given feed as Atom.
+func encodeAtomFeed(f *feed) ([]byte, error) {
+	atom := &atomFeed{
+		Title:   feedTitle,
+		ID:      f.selfURL,
+		Updated: f.updatedAt.UTC().Format(time.RFC3339),
+		Author:  atomPerson{Name: "Mattermost"},
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: f.selfURL},
+			{Rel: "alternate", Href: f.homeURL},
+		},
+	}
+
+	for _, release := range f.releases {
+		entry := &atomEntry{
+			Title:   release.title,
+			ID:      release.id,
+			Updated: release.updatedAt.UTC().Format(time.RFC3339),
+			Summary: release.summary,
+		}
+		if release.link != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: "alternate", Href: release.link})
+		}
+		if release.downloadURL != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: "enclosure", Type: "application/gzip", Href: release.downloadURL})
+		}
+		for _, label := range release.labels {
+			entry.Categories = append(entry.Categories, atomCategory{Term: label})
+		}
+
+		atom.Entries = append(atom.Entries, entry)
+	}
+
+	return encodeXML(atom)
+}
+
+// rssFeed is an RSS 2.0 feed.
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	LastBuildDate string     `xml:"lastBuildDate"`
+	Items         []*rssItem `xml:"item"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link,omitempty"`
+	Description string   `xml:"description,omitempty"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Categories  []string `xml:"category"`
+}
+
+// encodeRSSFeed encodes the given feed as RSS 2.0.
+func encodeRSSFeed(f *feed) ([]byte, error) {
+	rss := &rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         feedTitle,
+			Link:          f.homeURL,
+			Description:   "New releases of plugins in the Mattermost Plugin Marketplace.",
+			LastBuildDate: f.updatedAt.UTC().Format(time.RFC1123Z),
+		},
+	}
+
+	for _, release := range f.releases {
+		rss.Channel.Items = append(rss.Channel.Items, &rssItem{
+			Title:       release.title,
+			Link:        release.link,
+			Description: release.summary,
+			GUID:        rssGUID{Value: release.id},
+			PubDate:     release.updatedAt.UTC().Format(time.RFC1123Z),
+			Categories:  release.labels,
+		})
+	}
+
+	return encodeXML(rss)
+}
+
+// encodeXML encodes the given value as an XML document.
+func encodeXML(v interface{}) ([]byte, error) {
+	var body bytes.Buffer
+	body.WriteString(xml.Header)
+
+	encoder := xml.NewEncoder(&body)
+	encoder.Indent("", "  ")
+	err := encoder.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return body.Bytes(), nil
+}
+
+// jsonFeedVersion identifies the version of the JSON Feed specification implemented.
+const jsonFeedVersion = "https://jsonfeed.org/version/1.1"
+
+// JSONFeed is a JSON Feed of plugin releases, as specified at https://jsonfeed.org.
+type JSONFeed struct {
+	Version     string          `json:"version"`
+	Title       string          `json:"title"`
+	HomePageURL string          `json:"home_page_url"`
+	FeedURL     string          `json:"feed_url"`
+	Items       []*JSONFeedItem `json:"items"`
+}
+
+// JSONFeedItem describes a single release in a JSON Feed.
+type JSONFeedItem struct {
+	ID            string                 `json:"id"`
+	URL           string                 `json:"url,omitempty"` // The release notes of the release
+	Title         string                 `json:"title"`
+	Summary       string                 `json:"summary,omitempty"`
+	DatePublished time.Time              `json:"date_published"`
+	Tags          []string               `json:"tags,omitempty"` // The names of the plugin's labels
+	Release       *JSONFeedPluginRelease `json:"_marketplace"`
+}
+
+// JSONFeedPluginRelease extends a JSON Feed item with the plugin released.
+type JSONFeedPluginRelease struct {
+	PluginID    string `json:"plugin_id"`
+	Version     string `json:"version"`
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+// encodeJSONFeed encodes the given feed as a JSON Feed.
+func encodeJSONFeed(f *feed) ([]byte, error) {
+	jsonFeed := &JSONFeed{
+		Version:     jsonFeedVersion,
+		Title:       feedTitle,
+		HomePageURL: f.homeURL,
+		FeedURL:     f.selfURL,
+		Items:       make([]*JSONFeedItem, 0, len(f.releases)),
+	}
+
+	for _, release := range f.releases {
+		jsonFeed.Items = append(jsonFeed.Items, &JSONFeedItem{
+			ID:            release.id,
+			URL:           release.link,
+			Title:         release.title,
+			Summary:       release.summary,
+			DatePublished: release.updatedAt.UTC(),
+			Tags:          release.labels,
+			Release: &JSONFeedPluginRelease{
+				PluginID:    release.pluginID,
+				Version:     release.version,
+				DownloadURL: release.downloadURL,
+			},
+		})
+	}
+
+	return json.Marshal(jsonFeed)
+}
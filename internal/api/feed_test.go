@@ -0,0 +1,228 @@
+package api_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/api"
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/store"
+	"github.com/mattermost/mattermost-marketplace/internal/testlib"
+)
+
+func TestFeeds(t *testing.T) {
+	newPlugin := func(id, version string, updatedAt time.Time) *model.Plugin {
+		return &model.Plugin{
+			ReleaseNotesURL: "https://example.com/" + id + "/releases/v" + version,
+			DownloadURL:     "https://example.com/" + id + "-" + version + ".tar.gz",
+			AuthorType:      model.Mattermost,
+			ReleaseStage:    model.Production,
+			UpdatedAt:       updatedAt,
+			Manifest: &mattermostModel.Manifest{
+				Id:          id,
+				Name:        id + " plugin",
+				Description: "The " + id + " plugin.",
+				Version:     version,
+			},
+		}
+	}
+
+	day := func(d int) time.Time {
+		return time.Date(2020, time.January, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	demoV1 := newPlugin("demo", "1.0.0", day(1))
+	demoV2 := newPlugin("demo", "2.0.0", day(3))
+	beta := newPlugin("beta", "0.1.0", day(2))
+	beta.ReleaseStage = model.Beta
+	cloudOnly := newPlugin("cloud", "1.0.0", day(4))
+	cloudOnly.Hosting = model.Cloud
+
+	logger := testlib.MakeLogger(t)
+	staticStore, err := store.NewStatic([]*model.Plugin{demoV1, demoV2, beta, cloudOnly}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{Store: staticStore, Logger: logger})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	get := func(t *testing.T, path string) (*http.Response, []byte) {
+		resp, err := http.Get(ts.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp, body
+	}
+
+	t.Run("json feed of all releases, newest first", func(t *testing.T) {
+		resp, body := get(t, "/api/v1/feed.json")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "application/feed+json; charset=utf-8", resp.Header.Get("Content-Type"))
+		require.NotEmpty(t, resp.Header.Get("ETag"))
+
+		var feed api.JSONFeed
+		require.NoError(t, json.Unmarshal(body, &feed))
+		require.Equal(t, "https://jsonfeed.org/version/1.1", feed.Version)
+		require.Equal(t, ts.URL+"/api/v1/feed.json", feed.FeedURL)
+
+		var titles []string
+		for _, item := range feed.Items {
+			titles = append(titles, item.Title)
+		}
+		require.Equal(t, []string{"demo plugin 2.0.0", "beta plugin 0.1.0", "demo plugin 1.0.0"}, titles)
+
+		item := feed.Items[1]
+		require.Equal(t, ts.URL+"/api/v1/plugins/beta/versions/0.1.0", item.ID)
+		require.Equal(t, beta.ReleaseNotesURL, item.URL)
+		require.Equal(t, "The beta plugin.", item.Summary)
+		require.Equal(t, day(2), item.DatePublished)
+		require.Equal(t, []string{"Beta"}, item.Tags)
+		require.Equal(t, &api.JSONFeedPluginRelease{
+			PluginID:    "beta",
+			Version:     "0.1.0",
+			DownloadURL: beta.DownloadURL,
+		}, item.Release)
+	})
+
+	t.Run("filtered feed", func(t *testing.T) {
+		resp, body := get(t, "/api/v1/feed.json?cloud=true&release_stage=production&return_all_versions=false")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var feed api.JSONFeed
+		require.NoError(t, json.Unmarshal(body, &feed))
+
+		var titles []string
+		for _, item := range feed.Items {
+			titles = append(titles, item.Title)
+		}
+		require.Equal(t, []string{"cloud plugin 1.0.0", "demo plugin 2.0.0"}, titles)
+	})
+
+	t.Run("atom feed", func(t *testing.T) {
+		resp, body := get(t, "/api/v1/feed.atom?release_stage=beta")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "application/atom+xml; charset=utf-8", resp.Header.Get("Content-Type"))
+
+		var feed struct {
+			XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+			Updated string   `xml:"updated"`
+			Entries []struct {
+				Title   string `xml:"title"`
+				ID      string `xml:"id"`
+				Updated string `xml:"updated"`
+				Links   []struct {
+					Rel  string `xml:"rel,attr"`
+					Href string `xml:"href,attr"`
+				} `xml:"link"`
+				Categories []struct {
+					Term string `xml:"term,attr"`
+				} `xml:"category"`
+			} `xml:"entry"`
+		}
+		require.NoError(t, xml.Unmarshal(body, &feed))
+		require.Equal(t, "2020-01-02T00:00:00Z", feed.Updated)
+		require.Len(t, feed.Entries, 1)
+
+		entry := feed.Entries[0]
+		require.Equal(t, "beta plugin 0.1.0", entry.Title)
+		require.Equal(t, ts.URL+"/api/v1/plugins/beta/versions/0.1.0", entry.ID)
+		require.Equal(t, "2020-01-02T00:00:00Z", entry.Updated)
+		require.Equal(t, "alternate", entry.Links[0].Rel)
+		require.Equal(t, beta.ReleaseNotesURL, entry.Links[0].Href)
+		require.Len(t, entry.Categories, 1)
+		require.Equal(t, "Beta", entry.Categories[0].Term)
+	})
+
+	t.Run("rss feed", func(t *testing.T) {
+		resp, body := get(t, "/api/v1/feed.rss?return_all_versions=false")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "application/rss+xml; charset=utf-8", resp.Header.Get("Content-Type"))
+
+		var feed struct {
+			Version string `xml:"version,attr"`
+			Channel struct {
+				Items []struct {
+					Title   string `xml:"title"`
+					Link    string `xml:"link"`
+					GUID    string `xml:"guid"`
+					PubDate string `xml:"pubDate"`
+				} `xml:"item"`
+			} `xml:"channel"`
+		}
+		require.NoError(t, xml.Unmarshal(body, &feed))
+		require.Equal(t, "2.0", feed.Version)
+		require.Len(t, feed.Channel.Items, 2)
+		require.Equal(t, "demo plugin 2.0.0", feed.Channel.Items[0].Title)
+		require.Equal(t, demoV2.ReleaseNotesURL, feed.Channel.Items[0].Link)
+		require.Equal(t, ts.URL+"/api/v1/plugins/demo/versions/2.0.0", feed.Channel.Items[0].GUID)
+		require.Equal(t, "Fri, 03 Jan 2020 00:00:00 +0000", feed.Channel.Items[0].PubDate)
+	})
+
+	t.Run("conditional request", func(t *testing.T) {
+		resp, _ := get(t, "/api/v1/feed.atom")
+		etag := resp.Header.Get("ETag")
+		require.NotEmpty(t, etag)
+
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/feed.atom", nil)
+		require.NoError(t, err)
+		req.Header.Set("If-None-Match", etag)
+
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+		resp, _ = get(t, "/api/v1/feed.rss")
+		require.NotEqual(t, etag, resp.Header.Get("ETag"))
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		resp, _ := get(t, "/api/v1/feed.atom?server_version=invalid")
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("public URL ignores request headers", func(t *testing.T) {
+		router := mux.NewRouter()
+		api.Register(router, &api.Context{Store: staticStore, PublicURL: "https://marketplace.example.com/", Logger: logger})
+		ts := httptest.NewServer(router)
+		defer ts.Close()
+
+		var etag string
+		for _, host := range []string{"", "attacker.example.com"} {
+			req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/feed.json", nil)
+			require.NoError(t, err)
+			if host != "" {
+				req.Host = host
+				req.Header.Set("X-Forwarded-Proto", "http")
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var feed api.JSONFeed
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&feed))
+			require.Equal(t, "https://marketplace.example.com/api/v1/feed.json", feed.FeedURL)
+			require.Equal(t, "https://marketplace.example.com/api/v1/plugins", feed.HomePageURL)
+
+			if etag != "" {
+				require.Equal(t, etag, resp.Header.Get("ETag"))
+			}
+			etag = resp.Header.Get("ETag")
+		}
+	})
+}
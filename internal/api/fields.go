@@ -0,0 +1,174 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// pluginFieldNames are the top-level JSON fields of a Plugin that may be selected with fields=.
+var pluginFieldNames = jsonFieldNames(reflect.TypeOf(model.Plugin{}))
+
+// jsonFieldNames returns the names under which the fields of the given struct type are encoded.
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		names[name] = true
+	}
+
+	return names
+}
+
+// parseFields parses the sparse fieldset given by the repeatable, comma-separated fields
+// parameter. Each field is a dotted JSON path such as manifest.settings_schema, selecting only
+// the given fields, or excluding the field if prefixed with a hyphen.
+func parseFields(u *url.URL) ([]string, error) {
+	var fields []string
+	for _, value := range parseStrings(u, "fields") {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+
+			path := strings.Split(strings.TrimPrefix(field, "-"), ".")
+			if !pluginFieldNames[path[0]] {
+				return nil, newInvalidParameterError("fields", errors.Errorf("unsupported field %s", field))
+			}
+			for _, segment := range path[1:] {
+				if segment == "" {
+					return nil, newInvalidParameterError("fields", errors.Errorf("unsupported field %s", field))
+				}
+			}
+
+			fields = append(fields, field)
+		}
+	}
+
+	return fields, nil
+}
+
+// sparsePluginsResponse is a PluginsResponse whose plugins are restricted to a sparse fieldset.
+type sparsePluginsResponse struct {
+	*PluginsResponse
+	Plugins interface{} `json:"plugins"`
+}
+
+// selectPluginsFields restricts each of the given plugins to the given sparse fieldset, returning
+// the plugins unchanged if no fields are given.
+func selectPluginsFields(plugins []*model.Plugin, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return plugins, nil
+	}
+
+	result := make([]interface{}, 0, len(plugins))
+	for _, plugin := range plugins {
+		selected, err := selectPluginFields(plugin, fields)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, selected)
+	}
+
+	return result, nil
+}
+
+// selectPluginFields restricts the given plugin to the given sparse fieldset, returning the plugin
+// unchanged if no fields are given.
+func selectPluginFields(plugin *model.Plugin, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return plugin, nil
+	}
+
+	data, err := json.Marshal(plugin)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode plugin")
+	}
+
+	var encoded map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err = decoder.Decode(&encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode plugin")
+	}
+
+	var included, excluded [][]string
+	for _, field := range fields {
+		if strings.HasPrefix(field, "-") {
+			excluded = append(excluded, strings.Split(field[1:], "."))
+		} else {
+			included = append(included, strings.Split(field, "."))
+		}
+	}
+
+	result := encoded
+	if len(included) > 0 {
+		result = make(map[string]interface{})
+		for _, path := range included {
+			copyPath(result, encoded, path)
+		}
+	}
+
+	for _, path := range excluded {
+		deletePath(result, path)
+	}
+
+	return result, nil
+}
+
+// copyPath copies the value at the given path, if present, from src to dst.
+func copyPath(dst, src map[string]interface{}, path []string) {
+	value, ok := src[path[0]]
+	if !ok {
+		return
+	}
+
+	if len(path) == 1 {
+		dst[path[0]] = value
+		return
+	}
+
+	srcChild, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	dstChild, ok := dst[path[0]].(map[string]interface{})
+	if !ok {
+		dstChild = make(map[string]interface{})
+		dst[path[0]] = dstChild
+	}
+
+	copyPath(dstChild, srcChild, path[1:])
+}
+
+// deletePath removes the value at the given path, if present.
+func deletePath(m map[string]interface{}, path []string) {
+	if len(path) == 1 {
+		delete(m, path[0])
+		return
+	}
+
+	child, ok := m[path[0]].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	deletePath(child, path[1:])
+}
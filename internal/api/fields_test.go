@@ -0,0 +1,146 @@
+package api
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+func TestParseFields(t *testing.T) {
+	testCases := map[string]struct {
+		query       string
+		expected    []string
+		expectError bool
+	}{
+		"none":                {"", nil, false},
+		"comma-separated":     {"fields=icon_data,-manifest.settings_schema", []string{"icon_data", "-manifest.settings_schema"}, false},
+		"repeated":            {"fields=icon_data&fields=manifest.id", []string{"icon_data", "manifest.id"}, false},
+		"blank entries":       {"fields=icon_data,,%20", []string{"icon_data"}, false},
+		"unknown field":       {"fields=popularity", nil, true},
+		"empty path segment":  {"fields=manifest.", nil, true},
+		"unknown with hyphen": {"fields=-popularity", nil, true},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			u, err := url.Parse("/api/v1/plugins?" + testCase.query)
+			require.NoError(t, err)
+
+			fields, err := parseFields(u)
+			if testCase.expectError {
+				var apiErr *Error
+				require.ErrorAs(t, err, &apiErr)
+				assert.Equal(t, "fields", apiErr.Parameter)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, fields)
+		})
+	}
+}
+
+func TestSelectPluginFields(t *testing.T) {
+	plugin := &model.Plugin{
+		IconData:    "data:image/svg+xml;base64,PHN2Zz4=",
+		DownloadURL: "https://example.com/demo.tar.gz",
+		Manifest: &mattermostModel.Manifest{
+			Id:      "demo",
+			Version: "1.0.0",
+			SettingsSchema: &mattermostModel.PluginSettingsSchema{
+				Header: "Settings",
+			},
+		},
+	}
+
+	encode := func(t *testing.T, value interface{}) map[string]interface{} {
+		data, err := json.Marshal(value)
+		require.NoError(t, err)
+
+		var result map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &result))
+
+		return result
+	}
+
+	t.Run("no fields", func(t *testing.T) {
+		selected, err := selectPluginFields(plugin, nil)
+		require.NoError(t, err)
+		assert.Equal(t, plugin, selected)
+	})
+
+	t.Run("exclusions", func(t *testing.T) {
+		selected, err := selectPluginFields(plugin, []string{"-icon_data", "-manifest.settings_schema"})
+		require.NoError(t, err)
+
+		result := encode(t, selected)
+		assert.NotContains(t, result, "icon_data")
+		assert.Equal(t, "https://example.com/demo.tar.gz", result["download_url"])
+		assert.NotContains(t, result["manifest"], "settings_schema")
+		assert.Equal(t, "demo", result["manifest"].(map[string]interface{})["id"])
+	})
+
+	t.Run("inclusions", func(t *testing.T) {
+		selected, err := selectPluginFields(plugin, []string{"download_url", "manifest.id", "manifest.version"})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]interface{}{
+			"download_url": "https://example.com/demo.tar.gz",
+			"manifest": map[string]interface{}{
+				"id":      "demo",
+				"version": "1.0.0",
+			},
+		}, encode(t, selected))
+	})
+
+	t.Run("inclusions and exclusions", func(t *testing.T) {
+		selected, err := selectPluginFields(plugin, []string{"manifest", "-manifest.settings_schema"})
+		require.NoError(t, err)
+
+		result := encode(t, selected)
+		assert.Len(t, result, 1)
+		assert.NotContains(t, result["manifest"], "settings_schema")
+		assert.Equal(t, "demo", result["manifest"].(map[string]interface{})["id"])
+	})
+
+	t.Run("missing fields", func(t *testing.T) {
+		selected, err := selectPluginFields(plugin, []string{"labels", "manifest.webapp.bundle_path"})
+		require.NoError(t, err)
+		assert.Empty(t, encode(t, selected)["labels"])
+	})
+}
+
+func TestDecodeDataURI(t *testing.T) {
+	t.Run("svg", func(t *testing.T) {
+		mediaType, data, err := decodeDataURI("data:image/svg+xml;base64,PHN2Zz4=")
+		require.NoError(t, err)
+		assert.Equal(t, "image/svg+xml", mediaType)
+		assert.Equal(t, "<svg>", string(data))
+	})
+
+	t.Run("default media type", func(t *testing.T) {
+		mediaType, _, err := decodeDataURI("data:;base64,PHN2Zz4=")
+		require.NoError(t, err)
+		assert.Equal(t, "text/plain;charset=US-ASCII", mediaType)
+	})
+
+	for name, uri := range map[string]string{
+		"not a data uri":  "icon-data.svg",
+		"missing content": "data:image/svg+xml;base64",
+		"not base64":      "data:image/svg+xml,<svg>",
+		"invalid base64":  "data:image/svg+xml;base64,!!!",
+	} {
+		uri := uri
+		t.Run(name, func(t *testing.T) {
+			_, _, err := decodeDataURI(uri)
+			assert.Error(t, err)
+		})
+	}
+}
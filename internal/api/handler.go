@@ -2,6 +2,7 @@ package api
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/mattermost/mattermost-server/v6/model"
 )
@@ -20,6 +21,26 @@ func (h contextHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		"path":    r.URL.Path,
 		"request": context.RequestID,
 	})
+	w.Header().Set("X-Request-ID", context.RequestID)
+
+	// Plugins and labels are translated into the locales preferred by the caller.
+	context.Locales = requestLocales(r)
+	w.Header().Add("Vary", "Accept-Language")
+
+	if len(context.APIKeys) > 0 {
+		key, err := authenticateAPIKey(context.APIKeys, r, time.Now())
+		if err != nil {
+			context.Logger.WithError(err).Warn("failed to authenticate request")
+			outputError(context, w, newUnauthorizedError("a valid API key is required"))
+			return
+		}
+
+		if key != nil {
+			context.Store = key.Store
+			context.APIKeyID = key.ID
+			context.Logger = context.Logger.WithField("api_key", key.ID)
+		}
+	}
 
 	h.handler(context, w, r)
 }
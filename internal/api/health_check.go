@@ -12,7 +12,11 @@ var (
 	buildHashShort = ""
 )
 
-type healthCheckResponse struct {
+// HealthStatusPass is the status reported by a healthy server.
+const HealthStatusPass = "pass"
+
+// HealthCheckResponse describes the health of the server and the build it is running.
+type HealthCheckResponse struct {
 	Status      string                       `json:"status"`
 	Version     string                       `json:"version"`
 	ReleaseID   string                       `json:"releaseID"`
@@ -40,8 +44,8 @@ func handleHealthCheck(c *Context, w http.ResponseWriter, r *http.Request) {
 	details := make(map[string]map[string]string)
 	details["buildInfo"] = buildInfo
 
-	response := healthCheckResponse{
-		Status:      "pass",
+	response := HealthCheckResponse{
+		Status:      HealthStatusPass,
 		Version:     "1",
 		ReleaseID:   buildTag,
 		Details:     details,
@@ -27,7 +27,7 @@ func TestHealthCheck(t *testing.T) {
 	require.NotNil(t, result)
 	defer result.Body.Close()
 
-	respose := &healthCheckResponse{}
+	respose := &HealthCheckResponse{}
 	err := json.NewDecoder(result.Body).Decode(&respose)
 	require.NoError(t, err)
 	require.NotNil(t, respose)
@@ -3,6 +3,7 @@ package api
 import (
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 )
@@ -15,7 +16,7 @@ func parseInt(u *url.URL, name string, defaultValue int) (int, error) {
 
 	value, err := strconv.Atoi(valueStr)
 	if err != nil {
-		return 0, errors.Wrapf(err, "failed to parse %s as integer", name)
+		return 0, newInvalidParameterError(name, errors.Errorf("failed to parse %s as integer", name))
 	}
 
 	return value, nil
@@ -29,8 +30,21 @@ func parseBool(u *url.URL, name string, defaultValue bool) (bool, error) {
 
 	value, err := strconv.ParseBool(valueStr)
 	if err != nil {
-		return false, errors.Wrapf(err, "failed to parse %s as boolean", name)
+		return false, newInvalidParameterError(name, errors.Errorf("failed to parse %s as boolean", name))
 	}
 
 	return value, nil
 }
+
+// parseStrings returns every non-empty value of a repeatable parameter.
+func parseStrings(u *url.URL, name string) []string {
+	var values []string
+	for _, value := range u.Query()[name] {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// iconCacheControl allows browsers and CDNs to reuse an icon for a day, after which it must be
+// revalidated using the ETag. Icons rarely change between releases.
+const iconCacheControl = "public, max-age=86400"
+
+// privateIconCacheControl allows only the client itself to reuse an icon served to an
+// authenticated caller, which may belong to a plugin hidden from others.
+const privateIconCacheControl = "private, max-age=86400"
+
+// iconContentSecurityPolicy prevents scripts embedded within an SVG icon from running when the
+// icon is opened directly.
+const iconContentSecurityPolicy = "default-src 'none'; style-src 'unsafe-inline'; sandbox"
+
+// decodeDataURI decodes the media type and content of a base64 encoded data URI, such as
+// data:image/svg+xml;base64,PHN2Zz4=.
+func decodeDataURI(uri string) (string, []byte, error) {
+	if !strings.HasPrefix(uri, "data:") {
+		return "", nil, errors.New("not a data URI")
+	}
+
+	metadata, encoded, ok := cut(strings.TrimPrefix(uri, "data:"), ",")
+	if !ok {
+		return "", nil, errors.New("data URI is missing its content")
+	}
+
+	if !strings.HasSuffix(metadata, ";base64") {
+		return "", nil, errors.New("data URI is not base64 encoded")
+	}
+
+	mediaType := strings.TrimSuffix(metadata, ";base64")
+	if mediaType == "" {
+		mediaType = "text/plain;charset=US-ASCII"
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to decode data URI")
+	}
+
+	return mediaType, data, nil
+}
+
+// cut slices s around the first instance of sep.
+func cut(s, sep string) (string, string, bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+
+	return s, "", false
+}
+
+// handleGetPluginIcon responds to GET /api/v1/plugins/{plugin_id}/icon, serving the decoded icon
+// of the latest compatible version of a plugin, or of the version given by the version parameter.
+func handleGetPluginIcon(c *Context, w http.ResponseWriter, r *http.Request) {
+	pluginID := mux.Vars(r)["plugin_id"]
+
+	plugin, err := resolvePlugin(c, r)
+	if err != nil {
+		c.Logger.WithError(err).Warn("failed to resolve plugin")
+		outputError(c, w, err)
+		return
+	}
+
+	if plugin.IconData == "" {
+		outputError(c, w, newNotFoundError("plugin %s has no icon", pluginID))
+		return
+	}
+
+	contentType, icon, err := decodeDataURI(plugin.IconData)
+	if err != nil {
+		c.Logger.WithError(err).Errorf("failed to decode icon of plugin %s", pluginID)
+		outputError(c, w, err)
+		return
+	}
+
+	etag := computeETag(icon)
+	w.Header().Set("ETag", etag)
+	if c.APIKeyID != "" {
+		w.Header().Set("Cache-Control", privateIconCacheControl)
+	} else {
+		w.Header().Set("Cache-Control", iconCacheControl)
+	}
+	w.Header().Set("Surrogate-Key", surrogateKeys([]*model.Plugin{plugin}))
+	if !plugin.UpdatedAt.IsZero() {
+		w.Header().Set("Last-Modified", plugin.UpdatedAt.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, plugin.UpdatedAt) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Security-Policy", iconContentSecurityPolicy)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	_, err = w.Write(icon)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to write icon")
+	}
+}
@@ -18,9 +18,13 @@ func initLabels(apiRouter *mux.Router, context *Context) {
 	pluginsRouter.Handle("", addContext(handleGetLabels)).Methods(http.MethodGet)
 }
 
-// handleGetPlugins responds to GET /api/v1/labels, returning a list of all defined labels.
+// handleGetLabels responds to GET /api/v1/labels, returning a list of all configured labels
+// translated into the locales preferred by the caller.
 func handleGetLabels(c *Context, w http.ResponseWriter, r *http.Request) {
-	response := model.AllLabels
+	response := model.DefinedLabels(c.Labels)
+	for i, label := range response {
+		response[i] = label.Localize(c.Locales)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	outputJSON(c, w, response)
@@ -18,6 +18,7 @@ func TestGetLabels(t *testing.T) {
 	router := mux.NewRouter()
 
 	Register(router, &Context{
+		Labels: model.DefaultLabelDefinitions,
 		Logger: logrus.New(),
 	})
 
@@ -34,3 +35,57 @@ func TestGetLabels(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, model.AllLabels, respose)
 }
+
+func TestGetConfiguredLabels(t *testing.T) {
+	approved := model.Label{Name: "Approved by IT", Color: "#3db887"}
+
+	router := mux.NewRouter()
+
+	Register(router, &Context{
+		Labels: []*model.LabelDefinition{{Label: approved}},
+		Logger: logrus.New(),
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/labels", nil)
+	router.ServeHTTP(w, r)
+
+	result := w.Result()
+	require.NotNil(t, result)
+	defer result.Body.Close()
+
+	var response []model.Label
+	err := json.NewDecoder(result.Body).Decode(&response)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Label{approved}, response)
+}
+
+func TestGetLocalizedLabels(t *testing.T) {
+	beta := model.BetaLabel
+	beta.Localizations = model.Localizations{
+		"ja": {Description: "このプラグインはベータ版です。"},
+	}
+
+	router := mux.NewRouter()
+
+	Register(router, &Context{
+		Labels: []*model.LabelDefinition{{Label: beta}},
+		Logger: logrus.New(),
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/labels", nil)
+	r.Header.Set("Accept-Language", "ja-JP,ja;q=0.9")
+	router.ServeHTTP(w, r)
+
+	result := w.Result()
+	require.NotNil(t, result)
+	defer result.Body.Close()
+
+	var response []model.Label
+	err := json.NewDecoder(result.Body).Decode(&response)
+	require.NoError(t, err)
+	require.Len(t, response, 1)
+	assert.Equal(t, "Beta", response[0].Name)
+	assert.Equal(t, "このプラグインはベータ版です。", response[0].Description)
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// requestLocales returns the locales preferred by the caller, most preferred first, as given by
+// the locale parameter or else the Accept-Language header. Malformed locales are ignored, leaving
+// the response in the default locale.
+func requestLocales(r *http.Request) []string {
+	if locale := r.URL.Query().Get("locale"); locale != "" {
+		if normalized := model.NormalizeLocale(locale); normalized != "" {
+			return []string{normalized}
+		}
+
+		return nil
+	}
+
+	return parseAcceptLanguage(r.Header.Get("Accept-Language"))
+}
+
+// parseAcceptLanguage returns the locales listed by the given Accept-Language header, ordered by
+// their quality values as described by RFC 7231. The wildcard and locales explicitly refused with
+// a quality of zero are omitted.
+func parseAcceptLanguage(header string) []string {
+	type weightedLocale struct {
+		locale  string
+		quality float64
+	}
+
+	var weighted []weightedLocale
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+
+		locale := model.NormalizeLocale(params[0])
+		if locale == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+
+			value, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+			if err != nil {
+				value = 0
+			}
+			quality = value
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		weighted = append(weighted, weightedLocale{locale, quality})
+	}
+
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return weighted[i].quality > weighted[j].quality
+	})
+
+	var locales []string
+	for _, w := range weighted {
+		locales = append(locales, w.locale)
+	}
+
+	return locales
+}
+
+// localizePlugins returns copies of the given plugins translated into the locales preferred by
+// the caller, leaving the plugins served by the store untouched.
+func localizePlugins(c *Context, plugins []*model.Plugin) []*model.Plugin {
+	if len(c.Locales) == 0 {
+		return plugins
+	}
+
+	localized := make([]*model.Plugin, 0, len(plugins))
+	for _, plugin := range plugins {
+		localized = append(localized, plugin.Localize(c.Locales))
+	}
+
+	return localized
+}
+
+// localizedVariant qualifies the given response variant by the locales preferred by the caller,
+// since the same filter yields differently translated responses.
+func localizedVariant(c *Context, variant string) string {
+	if len(c.Locales) == 0 {
+		return variant
+	}
+
+	return variant + " " + strings.Join(c.Locales, ",")
+}
@@ -0,0 +1,129 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/api"
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+func TestLocalizedPlugins(t *testing.T) {
+	matterpoll := &model.Plugin{
+		HomepageURL: "https://github.com/matterpoll/matterpoll",
+		DownloadURL: "https://github.com/matterpoll/matterpoll/releases/download/v1.3.0/com.github.matterpoll.matterpoll-1.3.0.tar.gz",
+		AuthorType:  model.Community,
+		Manifest: &mattermostModel.Manifest{
+			Id:          "matterpoll",
+			Name:        "Matterpoll",
+			Description: "Create polls and surveys.",
+			Version:     "1.3.0",
+		},
+		Localizations: model.Localizations{
+			"de":    {Name: "Abstimmungen", Description: "Umfragen erstellen."},
+			"de-CH": {Description: "Umfragen erstellen, auf Schweizerdeutsch."},
+			"ja":    {Name: "投票", Description: "投票とアンケートを作成します。"},
+		},
+	}
+
+	client, tearDown := setupAPI(t, []*model.Plugin{matterpoll})
+	defer tearDown()
+
+	// get fetches the plugins with the given Accept-Language header, returning the response
+	// alongside the decoded plugins.
+	get := func(t *testing.T, query, acceptLanguage string) (*http.Response, []*model.Plugin) {
+		t.Helper()
+
+		req, err := http.NewRequest(http.MethodGet, client.Address+"/api/v1/plugins"+query, nil)
+		require.NoError(t, err)
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var plugins []*model.Plugin
+		err = json.NewDecoder(resp.Body).Decode(&plugins)
+		require.NoError(t, err)
+
+		return resp, plugins
+	}
+
+	testCases := map[string]struct {
+		query               string
+		acceptLanguage      string
+		expectedName        string
+		expectedDescription string
+	}{
+		"default":               {"", "", "Matterpoll", "Create polls and surveys."},
+		"accept language":       {"", "de", "Abstimmungen", "Umfragen erstellen."},
+		"region":                {"", "de-AT", "Abstimmungen", "Umfragen erstellen."},
+		"partial region":        {"", "de-CH", "Abstimmungen", "Umfragen erstellen, auf Schweizerdeutsch."},
+		"quality values":        {"", "de;q=0.5, ja;q=0.9, en;q=0.1", "投票", "投票とアンケートを作成します。"},
+		"english preferred":     {"", "en-US, de;q=0.9", "Matterpoll", "Create polls and surveys."},
+		"unavailable locale":    {"", "fr, *;q=0.5", "Matterpoll", "Create polls and surveys."},
+		"refused locale":        {"", "ja;q=0, de;q=0.5", "Abstimmungen", "Umfragen erstellen."},
+		"locale parameter":      {"?locale=ja", "de", "投票", "投票とアンケートを作成します。"},
+		"underscored parameter": {"?locale=de_CH", "", "Abstimmungen", "Umfragen erstellen, auf Schweizerdeutsch."},
+		"malformed parameter":   {"?locale=%2A", "de", "Matterpoll", "Create polls and surveys."},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			resp, plugins := get(t, testCase.query, testCase.acceptLanguage)
+			require.Contains(t, resp.Header.Values("Vary"), "Accept-Language")
+			require.Len(t, plugins, 1)
+			require.Equal(t, testCase.expectedName, plugins[0].Manifest.Name)
+			require.Equal(t, testCase.expectedDescription, plugins[0].Manifest.Description)
+		})
+	}
+
+	t.Run("labels", func(t *testing.T) {
+		community := model.CommunityLabel
+		community.Localizations = model.Localizations{
+			"de": {Name: "Community", Description: "Dieses Plugin wird von der Open-Source-Community gepflegt."},
+		}
+		labelsClient, tearDown := setupAPIWithLabels(t, []*model.Plugin{matterpoll}, []*model.LabelDefinition{
+			{Label: community, When: []*model.LabelCondition{{AuthorTypes: []model.AuthorType{model.Community}}}},
+		})
+		defer tearDown()
+
+		plugins, err := labelsClient.GetPlugins(&api.GetPluginsRequest{PerPage: -1, Locale: "de"})
+		require.NoError(t, err)
+		require.Len(t, plugins, 1)
+		require.Len(t, plugins[0].Labels, 1)
+		require.Equal(t, "Dieses Plugin wird von der Open-Source-Community gepflegt.", plugins[0].Labels[0].Description)
+	})
+
+	t.Run("client", func(t *testing.T) {
+		plugins, err := client.GetPlugins(&api.GetPluginsRequest{PerPage: -1, Locale: "ja"})
+		require.NoError(t, err)
+		require.Len(t, plugins, 1)
+		require.Equal(t, "投票", plugins[0].Manifest.Name)
+
+		plugin, err := client.GetPluginVersion(&api.GetPluginsRequest{Locale: "de"}, "matterpoll", "1.3.0")
+		require.NoError(t, err)
+		require.Equal(t, "Abstimmungen", plugin.Manifest.Name)
+	})
+
+	t.Run("search localized text", func(t *testing.T) {
+		_, plugins := get(t, "?filter=umfragen", "")
+		require.Len(t, plugins, 1)
+		require.Equal(t, "Matterpoll", plugins[0].Manifest.Name)
+	})
+
+	t.Run("entity tags differ by locale", func(t *testing.T) {
+		english, _ := get(t, "", "")
+		german, _ := get(t, "", "de")
+		require.NotEmpty(t, english.Header.Get("ETag"))
+		require.NotEqual(t, english.Header.Get("ETag"), german.Header.Get("ETag"))
+	})
+}
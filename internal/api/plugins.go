@@ -3,8 +3,11 @@ package api
 import (
 	"net/http"
 	"net/url"
+	"strings"
 
+	"github.com/blang/semver"
 	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
 
 	"github.com/mattermost/mattermost-marketplace/internal/model"
 )
@@ -17,23 +20,48 @@ func initPlugins(apiRouter *mux.Router, context *Context) {
 
 	pluginsRouter := apiRouter.PathPrefix("/plugins").Subrouter()
 	pluginsRouter.Handle("", addContext(handleGetPlugins)).Methods(http.MethodGet)
+	pluginsRouter.Handle("/updates", addContext(handlePostPluginUpdates)).Methods(http.MethodPost)
+
+	pluginRouter := pluginsRouter.PathPrefix("/{plugin_id}").Subrouter()
+	pluginRouter.Handle("", addContext(handleGetPlugin)).Methods(http.MethodGet)
+	pluginRouter.Handle("/versions/{version}", addContext(handleGetPluginVersion)).Methods(http.MethodGet)
+	pluginRouter.Handle("/icon", addContext(handleGetPluginIcon)).Methods(http.MethodGet)
+	pluginRouter.Handle("/download", addContext(handleGetPluginDownload)).Methods(http.MethodGet)
+	pluginRouter.Handle("/signature", addContext(handleGetPluginSignature)).Methods(http.MethodGet)
+	pluginRouter.Handle("/stats", addContext(handleGetPluginStats)).Methods(http.MethodGet)
+	pluginRouter.Handle("/compatibility", addContext(handleGetPluginCompatibility)).Methods(http.MethodGet)
 }
 
+// maxPerPage is the largest page size a client may request, short of requesting all plugins.
+const maxPerPage = 200
+
+// ParsePluginFilter parses the plugin filter from the query string of the given URL.
+//
+// Any error returned is an *Error identifying the offending parameter.
 func ParsePluginFilter(u *url.URL) (*model.PluginFilter, error) {
 	page, err := parseInt(u, "page", 0)
 	if err != nil {
 		return nil, err
 	}
+	if page < 0 {
+		return nil, newInvalidParameterError("page", errors.New("page must not be negative"))
+	}
 
 	perPage, err := parseInt(u, "per_page", 100)
 	if err != nil {
 		return nil, err
 	}
+	if (perPage < 0 && perPage != model.AllPerPage) || perPage > maxPerPage {
+		return nil, newInvalidParameterError("per_page", errors.Errorf("per_page must be between 0 and %d, or %d for all plugins", maxPerPage, model.AllPerPage))
+	}
 
-	filter := u.Query().Get("filter")
 	serverVersion := u.Query().Get("server_version")
-	platform := u.Query().Get("platform")
-	pluginID := u.Query().Get("plugin_id")
+	if serverVersion != "" {
+		_, err = semver.Parse(serverVersion)
+		if err != nil {
+			return nil, newInvalidParameterError("server_version", errors.Wrapf(err, "failed to parse server_version %s", serverVersion))
+		}
+	}
 
 	enterprisePlugins, err := parseBool(u, "enterprise_plugins", false)
 	if err != nil {
@@ -50,38 +78,345 @@ func ParsePluginFilter(u *url.URL) (*model.PluginFilter, error) {
 		return nil, err
 	}
 
-	return &model.PluginFilter{
+	includeYanked, err := parseBool(u, "include_yanked", false)
+	if err != nil {
+		return nil, err
+	}
+
+	facets, err := parseBool(u, "facets", false)
+	if err != nil {
+		return nil, err
+	}
+
+	downloadCounts, err := parseBool(u, "download_counts", false)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := parseFields(u)
+	if err != nil {
+		return nil, err
+	}
+
+	sort := model.PluginSort(u.Query().Get("sort"))
+	if !sort.IsValid() {
+		return nil, newInvalidParameterError("sort", errors.Errorf("unsupported sort %s", sort))
+	}
+
+	sortDirection := model.SortDirection(u.Query().Get("sort_direction"))
+	if !sortDirection.IsValid() {
+		return nil, newInvalidParameterError("sort_direction", errors.Errorf("unsupported sort_direction %s", sortDirection))
+	}
+
+	var authorTypes []model.AuthorType
+	for _, value := range parseStrings(u, "author_type") {
+		authorType := model.AuthorType(strings.ToLower(value))
+		if !authorType.IsValid() {
+			return nil, newInvalidParameterError("author_type", errors.Errorf("unsupported author_type %s", value))
+		}
+		authorTypes = append(authorTypes, authorType)
+	}
+
+	var releaseStages []model.ReleaseStage
+	for _, value := range parseStrings(u, "release_stage") {
+		releaseStage := model.ReleaseStage(strings.ToLower(value))
+		if !releaseStage.IsValid() {
+			return nil, newInvalidParameterError("release_stage", errors.Errorf("unsupported release_stage %s", value))
+		}
+		releaseStages = append(releaseStages, releaseStage)
+	}
+
+	var hosting []model.HostingType
+	for _, value := range parseStrings(u, "hosting") {
+		hostingType := model.HostingType(strings.ToLower(value))
+		if !hostingType.IsValid() {
+			return nil, newInvalidParameterError("hosting", errors.Errorf("unsupported hosting %s", value))
+		}
+		hosting = append(hosting, hostingType)
+	}
+
+	filter := &model.PluginFilter{
 		Page:              page,
 		PerPage:           perPage,
-		Filter:            filter,
+		Filter:            u.Query().Get("filter"),
 		ServerVersion:     serverVersion,
 		EnterprisePlugins: enterprisePlugins,
 		Cloud:             cloud,
-		Platform:          platform,
-		PluginID:          pluginID,
+		Platform:          u.Query().Get("platform"),
+		PluginID:          u.Query().Get("plugin_id"),
 		ReturnAllVersions: returnAllVersions,
-	}, nil
+		IncludeYanked:     includeYanked,
+		Cursor:            u.Query().Get("cursor"),
+		Sort:              sort,
+		SortDirection:     sortDirection,
+		AuthorTypes:       authorTypes,
+		ReleaseStages:     releaseStages,
+		Hosting:           hosting,
+		Labels:            parseStrings(u, "label"),
+		Facets:            facets,
+		DownloadCounts:    downloadCounts,
+		Fields:            fields,
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := model.DecodePluginCursor(filter.Cursor)
+		if err != nil {
+			return nil, newInvalidParameterError("cursor", err)
+		}
+
+		if !cursor.Matches(filter) {
+			return nil, newInvalidParameterError("cursor", errors.New("cursor was issued for a different sort order"))
+		}
+	}
+
+	return filter, nil
 }
 
 // handleGetPlugins responds to GET /api/v1/plugins, returning the specified page of plugins.
+//
+// The total number of matching plugins and links to related pages are described by the
+// X-Total-Count and Link headers, or alternatively in a PluginsResponse envelope if requested.
+// Responses carry an ETag and Last-Modified header, allowing clients to revalidate their cached
+// copy with a conditional request.
 func handleGetPlugins(c *Context, w http.ResponseWriter, r *http.Request) {
 	filter, err := ParsePluginFilter(r.URL)
 	if err != nil {
-		c.Logger.WithError(err).Error("failed to parse paging parameters")
-		w.WriteHeader(http.StatusBadRequest)
+		c.Logger.WithError(err).Warn("failed to parse plugin filter")
+		outputError(c, w, err)
 		return
 	}
 
-	plugins, err := c.Store.GetPlugins(filter)
+	envelope, err := parseBool(r.URL, "envelope", false)
+	if err != nil {
+		c.Logger.WithError(err).Warn("failed to parse envelope parameter")
+		outputError(c, w, err)
+		return
+	}
+
+	if filter.Facets && !envelope {
+		err = newInvalidParameterError("facets", errors.New("facets are only returned with envelope=true"))
+		c.Logger.WithError(err).Warn("failed to parse facets parameter")
+		outputError(c, w, err)
+		return
+	}
+
+	variant := "plugins"
+	if envelope {
+		variant = "envelope"
+	}
+	variant = localizedVariant(c, variant)
+
+	// Avoid querying the store at all if the client's copy was derived from the same catalog.
+	if revision := catalogRevision(c, filter); revision != "" {
+		etag := computeETag([]byte(revision), normalizeFilter(filter), []byte(variant))
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Cache-Control", pluginsCacheControlFor(c))
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	page, err := c.Store.GetPluginsPage(filter)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to query plugins")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, err)
 		return
 	}
+	plugins := page.Plugins
 	if plugins == nil {
 		plugins = []*model.Plugin{}
 	}
+	plugins = warnYanked(localizePlugins(c, plugins))
+	addDownloadCounts(c, filter, plugins)
+	addAdvisories(c, plugins)
+
+	links := pageLinks(r.URL, filter, page)
+	setPageHeaders(w, links, page)
+
+	response, err := selectPluginsFields(plugins, filter.Fields)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to select fields")
+		outputError(c, w, err)
+		return
+	}
+	if envelope {
+		pluginsResponse := &PluginsResponse{
+			Plugins:    plugins,
+			Total:      page.Total,
+			Page:       filter.Page,
+			PerPage:    filter.PerPage,
+			NextCursor: page.NextCursor,
+			Links:      links,
+			Facets:     page.Facets,
+		}
+
+		if len(filter.Fields) > 0 {
+			response = &sparsePluginsResponse{PluginsResponse: pluginsResponse, Plugins: response}
+		} else {
+			response = pluginsResponse
+		}
+	}
+
+	outputCachedPlugins(c, w, r, filter, variant, response, plugins)
+}
+
+// handleGetPlugin responds to GET /api/v1/plugins/{plugin_id}, returning the versions of a single
+// plugin compatible with the given filter. Only the latest compatible version is returned unless
+// return_all_versions is set.
+func handleGetPlugin(c *Context, w http.ResponseWriter, r *http.Request) {
+	pluginID := mux.Vars(r)["plugin_id"]
+
+	filter, err := ParsePluginFilter(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Warn("failed to parse plugin filter")
+		outputError(c, w, err)
+		return
+	}
+	filter.PluginID = pluginID
+
+	plugins, err := c.Store.GetPlugins(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query plugins")
+		outputError(c, w, err)
+		return
+	}
+	if len(plugins) == 0 {
+		outputError(c, w, newNotFoundError("plugin %s not found", pluginID))
+		return
+	}
+	plugins = warnYanked(localizePlugins(c, plugins))
+	addDownloadCounts(c, filter, plugins)
+	addAdvisories(c, plugins)
+
+	response, err := selectPluginsFields(plugins, filter.Fields)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to select fields")
+		outputError(c, w, err)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	outputJSON(c, w, plugins)
+	outputJSON(c, w, response)
+}
+
+// handleGetPluginVersion responds to GET /api/v1/plugins/{plugin_id}/versions/{version}, returning
+// the given version of a single plugin if it is compatible with the given filter.
+func handleGetPluginVersion(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	pluginID := vars["plugin_id"]
+
+	version, err := semver.ParseTolerant(vars["version"])
+	if err != nil {
+		c.Logger.WithError(err).Warn("failed to parse version")
+		outputError(c, w, newInvalidParameterError("version", err))
+		return
+	}
+
+	filter, err := ParsePluginFilter(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Warn("failed to parse plugin filter")
+		outputError(c, w, err)
+		return
+	}
+	filter.PluginID = pluginID
+	filter.ReturnAllVersions = true
+	filter.IncludeYanked = true
+	filter.Page = 0
+	filter.PerPage = model.AllPerPage
+
+	plugins, err := c.Store.GetPlugins(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query plugins")
+		outputError(c, w, err)
+		return
+	}
+
+	for _, plugin := range plugins {
+		pluginVersion, err := semver.Parse(plugin.Manifest.Version)
+		if err != nil {
+			c.Logger.WithError(err).Warnf("failed to parse version of plugin %s", plugin.Manifest.Id)
+			continue
+		}
+
+		if pluginVersion.EQ(version) {
+			plugin = warnYanked([]*model.Plugin{plugin.Localize(c.Locales)})[0]
+			addDownloadCounts(c, filter, []*model.Plugin{plugin})
+			addAdvisories(c, []*model.Plugin{plugin})
+
+			response, err := selectPluginFields(plugin, filter.Fields)
+			if err != nil {
+				c.Logger.WithError(err).Error("failed to select fields")
+				outputError(c, w, err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			outputJSON(c, w, response)
+			return
+		}
+	}
+
+	outputError(c, w, newNotFoundError("version %s of plugin %s not found", version, pluginID))
+}
+
+// resolvePlugin returns the single plugin identified by the plugin_id path parameter that is
+// compatible with the filter given by the query string. The latest compatible version is returned
+// unless the version parameter requests a specific one.
+func resolvePlugin(c *Context, r *http.Request) (*model.Plugin, error) {
+	filter, err := ParsePluginFilter(r.URL)
+	if err != nil {
+		return nil, err
+	}
+	filter.PluginID = mux.Vars(r)["plugin_id"]
+	filter.Page = 0
+	filter.PerPage = model.AllPerPage
+	filter.ReturnAllVersions = false
+
+	return findPluginVersion(c, r.URL, filter)
+}
+
+// findPluginVersion returns the plugin matching the given filter at the version given by the
+// version parameter, or the latest matching version if none is given.
+func findPluginVersion(c *Context, u *url.URL, filter *model.PluginFilter) (*model.Plugin, error) {
+	versionParam := u.Query().Get("version")
+	if versionParam == "" {
+		plugins, err := c.Store.GetPlugins(filter)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to query plugins")
+		}
+		if len(plugins) == 0 {
+			return nil, newNotFoundError("plugin %s not found", filter.PluginID)
+		}
+
+		return plugins[0], nil
+	}
+
+	version, err := semver.ParseTolerant(versionParam)
+	if err != nil {
+		return nil, newInvalidParameterError("version", err)
+	}
+
+	versionFilter := *filter
+	versionFilter.ReturnAllVersions = true
+	versionFilter.IncludeYanked = true
+
+	plugins, err := c.Store.GetPlugins(&versionFilter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query plugins")
+	}
+
+	for _, plugin := range plugins {
+		pluginVersion, err := semver.Parse(plugin.Manifest.Version)
+		if err != nil {
+			c.Logger.WithError(err).Warnf("failed to parse version of plugin %s", plugin.Manifest.Id)
+			continue
+		}
+
+		if pluginVersion.EQ(version) {
+			return plugin, nil
+		}
+	}
+
+	return nil, newNotFoundError("version %s of plugin %s not found", version, filter.PluginID)
 }
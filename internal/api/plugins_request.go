@@ -3,6 +3,9 @@ package api
 import (
 	"net/url"
 	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
 )
 
 // GetPluginsRequest describes the parameters to request a list of plugins.
@@ -15,7 +18,19 @@ type GetPluginsRequest struct {
 	Cloud             bool
 	Platform          string
 	ReturnAllVersions bool
+	IncludeYanked     bool // Only honoured alongside ReturnAllVersions
 	PluginID          string
+	Cursor            string
+	Sort              model.PluginSort
+	SortDirection     model.SortDirection
+	AuthorTypes       []model.AuthorType
+	ReleaseStages     []model.ReleaseStage
+	Hosting           []model.HostingType
+	Labels            []string
+	Facets            bool     // Only supported by GetPluginsPage
+	DownloadCounts    bool     // Whether to return download counts, if statistics are enabled
+	Fields            []string // Dotted JSON paths of the fields to return, or to omit if prefixed with a hyphen
+	Locale            string   // The locale into which plugins are translated, if available
 }
 
 // ApplyToURL modifies the given url to include query string parameters for the request.
@@ -29,6 +44,34 @@ func (request *GetPluginsRequest) ApplyToURL(u *url.URL) {
 	q.Add("cloud", strconv.FormatBool(request.Cloud))
 	q.Add("platform", request.Platform)
 	q.Add("return_all_versions", strconv.FormatBool(request.ReturnAllVersions))
+	if request.IncludeYanked {
+		q.Add("include_yanked", "true")
+	}
 	q.Add("plugin_id", request.PluginID)
+	q.Add("cursor", request.Cursor)
+	q.Add("sort", string(request.Sort))
+	q.Add("sort_direction", string(request.SortDirection))
+	for _, authorType := range request.AuthorTypes {
+		q.Add("author_type", string(authorType))
+	}
+	for _, releaseStage := range request.ReleaseStages {
+		q.Add("release_stage", string(releaseStage))
+	}
+	for _, hosting := range request.Hosting {
+		q.Add("hosting", string(hosting))
+	}
+	for _, label := range request.Labels {
+		q.Add("label", label)
+	}
+	q.Add("facets", strconv.FormatBool(request.Facets))
+	if request.DownloadCounts {
+		q.Add("download_counts", "true")
+	}
+	if len(request.Fields) > 0 {
+		q.Add("fields", strings.Join(request.Fields, ","))
+	}
+	if request.Locale != "" {
+		q.Add("locale", request.Locale)
+	}
 	u.RawQuery = q.Encode()
 }
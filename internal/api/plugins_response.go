@@ -0,0 +1,94 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// PluginsResponse is the envelope returned by GET /api/v1/plugins when requested with envelope=true.
+type PluginsResponse struct {
+	Plugins []*model.Plugin `json:"plugins"`
+
+	// Total is the number of plugins matching the request across all pages, or -1 if unknown.
+	Total   int `json:"total"`
+	Page    int `json:"page"`
+	PerPage int `json:"per_page"`
+
+	// NextCursor may be passed as the cursor of a subsequent request to fetch the next page.
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	// Links holds the URLs of related pages, keyed by their RFC 8288 relation type.
+	Links map[string]string `json:"links,omitempty"`
+
+	// Facets counts the matching plugins per facet value when requested with facets=true.
+	Facets *model.PluginFacets `json:"facets,omitempty"`
+}
+
+// pageLinks returns the URLs of the pages related to the given page, keyed by relation type.
+//
+// When paging by cursor, only the next page can be linked.
+func pageLinks(u *url.URL, filter *model.PluginFilter, page *model.PluginsPage) map[string]string {
+	links := make(map[string]string)
+
+	withQuery := func(name, value string) string {
+		linkURL := *u
+		q := linkURL.Query()
+		q.Set(name, value)
+		if name == "cursor" {
+			q.Del("page")
+		} else {
+			q.Del("cursor")
+		}
+		linkURL.RawQuery = q.Encode()
+
+		return linkURL.RequestURI()
+	}
+
+	if filter.Cursor != "" || filter.PerPage <= 0 {
+		if page.NextCursor != "" {
+			links["next"] = withQuery("cursor", page.NextCursor)
+		}
+
+		return links
+	}
+
+	lastPage := 0
+	if page.Total > 0 {
+		lastPage = (page.Total - 1) / filter.PerPage
+	}
+
+	links["first"] = withQuery("page", "0")
+	if filter.Page > 0 {
+		links["prev"] = withQuery("page", strconv.Itoa(filter.Page-1))
+	}
+	if page.NextCursor != "" {
+		links["next"] = withQuery("page", strconv.Itoa(filter.Page+1))
+	}
+	if page.Total >= 0 {
+		links["last"] = withQuery("page", strconv.Itoa(lastPage))
+	}
+
+	return links
+}
+
+// setPageHeaders describes the given page using the X-Total-Count and RFC 8288 Link headers.
+func setPageHeaders(w http.ResponseWriter, links map[string]string, page *model.PluginsPage) {
+	if page.Total >= 0 {
+		w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	}
+
+	var values []string
+	for _, rel := range []string{"first", "prev", "next", "last"} {
+		if link, ok := links[rel]; ok {
+			values = append(values, fmt.Sprintf(`<%s>; rel="%s"`, link, rel))
+		}
+	}
+	if len(values) > 0 {
+		w.Header().Set("Link", strings.Join(values, ", "))
+	}
+}
@@ -2,11 +2,15 @@ package api_test
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
@@ -19,16 +23,23 @@ import (
 )
 
 func setupAPI(t *testing.T, plugins []*model.Plugin) (*api.Client, func()) {
+	return setupAPIWithLabels(t, plugins, model.DefaultLabelDefinitions)
+}
+
+// setupAPIWithLabels serves the given plugins, assigning them the labels whose given definitions
+// match.
+func setupAPIWithLabels(t *testing.T, plugins []*model.Plugin, labels []*model.LabelDefinition) (*api.Client, func()) {
 	logger := testlib.MakeLogger(t)
 
 	data, err := json.Marshal(plugins)
 	require.NoError(t, err)
-	store, err := store.NewStaticFromReader(bytes.NewReader(data), logger)
+	store, err := store.NewStaticFromReader(bytes.NewReader(data), labels, logger)
 	require.NoError(t, err)
 
 	router := mux.NewRouter()
 	api.Register(router, &api.Context{
 		Store:  store,
+		Labels: labels,
 		Logger: logger,
 	})
 	ts := httptest.NewServer(router)
@@ -96,6 +107,76 @@ func TestPlugins(t *testing.T) {
 			require.NoError(t, err)
 			require.Equal(t, http.StatusOK, resp.StatusCode)
 		})
+
+		t.Run("structured errors", func(t *testing.T) {
+			client, tearDown := setupAPI(t, nil)
+			defer tearDown()
+
+			testCases := map[string]struct {
+				query     string
+				parameter string
+			}{
+				"invalid page":           {"page=invalid", "page"},
+				"negative page":          {"page=-1", "page"},
+				"invalid perPage":        {"per_page=invalid", "per_page"},
+				"negative perPage":       {"per_page=-2", "per_page"},
+				"excessive perPage":      {"per_page=201", "per_page"},
+				"invalid server version": {"server_version=5", "server_version"},
+				"invalid boolean":        {"cloud=maybe", "cloud"},
+				"invalid sort":           {"sort=popularity", "sort"},
+				"invalid cursor":         {"cursor=invalid", "cursor"},
+				"invalid author type":    {"author_type=mattermost&author_type=vendor", "author_type"},
+				"invalid release stage":  {"release_stage=alpha", "release_stage"},
+				"invalid hosting":        {"hosting=saas", "hosting"},
+			}
+
+			for name, testCase := range testCases {
+				testCase := testCase
+				t.Run(name, func(t *testing.T) {
+					resp, err := http.Get(fmt.Sprintf("%s/api/v1/plugins?%s", client.Address, testCase.query))
+					require.NoError(t, err)
+					defer resp.Body.Close()
+					require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+					require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+					apiErr := &api.Error{}
+					require.NoError(t, json.NewDecoder(resp.Body).Decode(apiErr))
+					require.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+					require.Equal(t, api.ErrorCodeInvalidParameter, apiErr.Code)
+					require.Equal(t, testCase.parameter, apiErr.Parameter)
+					require.NotEmpty(t, apiErr.Message)
+					require.NotEmpty(t, apiErr.RequestID)
+					require.Equal(t, resp.Header.Get("X-Request-ID"), apiErr.RequestID)
+				})
+			}
+		})
+
+		t.Run("typed client error", func(t *testing.T) {
+			client, tearDown := setupAPI(t, nil)
+			defer tearDown()
+
+			_, err := client.GetPlugins(&api.GetPluginsRequest{
+				PerPage:       10,
+				ServerVersion: "invalid",
+			})
+			require.Error(t, err)
+
+			var apiErr *api.Error
+			require.True(t, errors.As(err, &apiErr))
+			require.Equal(t, api.ErrorCodeInvalidParameter, apiErr.Code)
+			require.Equal(t, "server_version", apiErr.Parameter)
+		})
+
+		t.Run("largest perPage", func(t *testing.T) {
+			client, tearDown := setupAPI(t, nil)
+			defer tearDown()
+
+			response, err := client.GetPluginsPage(&api.GetPluginsRequest{
+				PerPage: 200,
+			})
+			require.NoError(t, err)
+			require.Equal(t, 200, response.PerPage)
+		})
 	})
 
 	t.Run("plugins", func(t *testing.T) {
@@ -213,7 +294,7 @@ func TestPlugins(t *testing.T) {
 
 		plugin5EnterpriseWithLabels := &model.Plugin{}
 		*plugin5EnterpriseWithLabels = *plugin5Enterprise
-		plugin5EnterpriseWithLabels.AddLabels()
+		plugin5EnterpriseWithLabels.AddLabels(model.DefaultLabelDefinitions)
 
 		plugin6WithPlatform := &model.Plugin{
 			HomepageURL: "https://github.com/mattermost/mattermost-plugin-todo",
@@ -570,5 +651,457 @@ func TestPlugins(t *testing.T) {
 			require.Error(t, err)
 			require.Nil(t, plugins)
 		})
+
+		t.Run("pagination headers", func(t *testing.T) {
+			client, tearDown := setupAPI(t, allPlugins)
+			defer tearDown()
+
+			resp, err := http.Get(fmt.Sprintf("%s/api/v1/plugins?page=1&per_page=1&filter=", client.Address))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, "4", resp.Header.Get("X-Total-Count"))
+			require.Equal(t, `</api/v1/plugins?filter=&page=0&per_page=1>; rel="first", `+
+				`</api/v1/plugins?filter=&page=0&per_page=1>; rel="prev", `+
+				`</api/v1/plugins?filter=&page=2&per_page=1>; rel="next", `+
+				`</api/v1/plugins?filter=&page=3&per_page=1>; rel="last"`, resp.Header.Get("Link"))
+
+			plugins, err := model.PluginsFromReader(resp.Body)
+			require.NoError(t, err)
+			require.Equal(t, []*model.Plugin{plugin2V1Min516}, plugins)
+		})
+
+		t.Run("pagination envelope", func(t *testing.T) {
+			client, tearDown := setupAPI(t, allPlugins)
+			defer tearDown()
+
+			response, err := client.GetPluginsPage(&api.GetPluginsRequest{
+				Page:    1,
+				PerPage: 2,
+			})
+			require.NoError(t, err)
+			require.Equal(t, []*model.Plugin{plugin3V3Min517, plugin6WithPlatform}, response.Plugins)
+			require.Equal(t, 4, response.Total)
+			require.Equal(t, 1, response.Page)
+			require.Equal(t, 2, response.PerPage)
+			require.Empty(t, response.NextCursor)
+			require.Contains(t, response.Links, "prev")
+			require.NotContains(t, response.Links, "next")
+		})
+
+		t.Run("pagination by cursor", func(t *testing.T) {
+			client, tearDown := setupAPI(t, allPlugins)
+			defer tearDown()
+
+			var plugins []*model.Plugin
+			request := &api.GetPluginsRequest{
+				PerPage: 3,
+			}
+			for {
+				response, err := client.GetPluginsPage(request)
+				require.NoError(t, err)
+				require.Equal(t, 4, response.Total)
+				plugins = append(plugins, response.Plugins...)
+
+				if response.NextCursor == "" {
+					require.NotContains(t, response.Links, "next")
+					break
+				}
+				if request.Cursor != "" {
+					require.Contains(t, response.Links["next"], "cursor="+response.NextCursor)
+				}
+				request.Cursor = response.NextCursor
+			}
+			require.Equal(t, []*model.Plugin{plugin1V3Min515, plugin2V1Min516, plugin3V3Min517, plugin6WithPlatform}, plugins)
+
+			resp, err := http.Get(fmt.Sprintf("%s/api/v1/plugins?cursor=invalid", client.Address))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("sort by id descending", func(t *testing.T) {
+			client, tearDown := setupAPI(t, allPlugins)
+			defer tearDown()
+
+			plugins, err := client.GetPlugins(&api.GetPluginsRequest{
+				PerPage:       -1,
+				Sort:          model.SortByID,
+				SortDirection: model.SortDescending,
+			})
+			require.NoError(t, err)
+			require.Equal(t, []*model.Plugin{plugin3V3Min517, plugin2V1Min516, plugin1V3Min515, plugin6WithPlatform}, plugins)
+		})
+
+		t.Run("invalid sort", func(t *testing.T) {
+			client, tearDown := setupAPI(t, allPlugins)
+			defer tearDown()
+
+			resp, err := http.Get(fmt.Sprintf("%s/api/v1/plugins?sort=popularity", client.Address))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+			resp, err = http.Get(fmt.Sprintf("%s/api/v1/plugins?sort=name&sort_direction=up", client.Address))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("facets", func(t *testing.T) {
+			partnerPlugin := &model.Plugin{}
+			*partnerPlugin = *plugin3V1NoMin
+			partnerPlugin.AuthorType = model.Partner
+			partnerPlugin.ReleaseStage = model.Beta
+			partnerPlugin.AddLabels(model.DefaultLabelDefinitions)
+
+			client, tearDown := setupAPI(t, []*model.Plugin{plugin1V2Min515, partnerPlugin})
+			defer tearDown()
+
+			plugins, err := client.GetPlugins(&api.GetPluginsRequest{
+				PerPage:     -1,
+				AuthorTypes: []model.AuthorType{model.Partner, model.Community},
+			})
+			require.NoError(t, err)
+			require.Equal(t, []*model.Plugin{partnerPlugin}, plugins)
+
+			plugins, err = client.GetPlugins(&api.GetPluginsRequest{
+				PerPage: -1,
+				Labels:  []string{"beta"},
+			})
+			require.NoError(t, err)
+			require.Equal(t, []*model.Plugin{partnerPlugin}, plugins)
+
+			plugins, err = client.GetPlugins(&api.GetPluginsRequest{
+				PerPage:       -1,
+				ReleaseStages: []model.ReleaseStage{model.Experimental},
+			})
+			require.NoError(t, err)
+			require.Empty(t, plugins)
+
+			response, err := client.GetPluginsPage(&api.GetPluginsRequest{
+				PerPage:     0,
+				AuthorTypes: []model.AuthorType{model.Partner},
+				Facets:      true,
+			})
+			require.NoError(t, err)
+			require.Equal(t, 1, response.Total)
+			require.NotNil(t, response.Facets)
+			require.Equal(t, map[model.AuthorType]int{model.Partner: 1}, response.Facets.AuthorType)
+			require.Equal(t, map[model.ReleaseStage]int{model.Beta: 1}, response.Facets.ReleaseStage)
+			require.Equal(t, map[string]int{"Partner": 1, "Beta": 1}, response.Facets.Label)
+
+			response, err = client.GetPluginsPage(&api.GetPluginsRequest{PerPage: 0})
+			require.NoError(t, err)
+			require.Nil(t, response.Facets)
+
+			resp, err := http.Get(fmt.Sprintf("%s/api/v1/plugins?facets=true", client.Address))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("caching headers", func(t *testing.T) {
+			updatedPlugin := &model.Plugin{}
+			*updatedPlugin = *plugin6WithPlatform
+			updatedPlugin.UpdatedAt = time.Date(2020, time.March, 4, 5, 6, 7, 0, time.UTC)
+
+			client, tearDown := setupAPI(t, append(allPlugins, updatedPlugin))
+			defer tearDown()
+
+			get := func(query string, header http.Header) *http.Response {
+				req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/v1/plugins?%s", client.Address, query), nil)
+				require.NoError(t, err)
+				if header != nil {
+					req.Header = header
+				}
+
+				resp, err := http.DefaultClient.Do(req)
+				require.NoError(t, err)
+				t.Cleanup(func() { resp.Body.Close() })
+
+				return resp
+			}
+
+			resp := get("per_page=-1", nil)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			etag := resp.Header.Get("ETag")
+			require.NotEmpty(t, etag)
+			require.Equal(t, "public, max-age=300", resp.Header.Get("Cache-Control"))
+			require.Equal(t, "Wed, 04 Mar 2020 05:06:07 GMT", resp.Header.Get("Last-Modified"))
+			require.Contains(t, resp.Header.Get("Surrogate-Key"), "plugin:matterpoll")
+			require.Contains(t, resp.Header.Get("Surrogate-Key"), "plugin:com.mattermost.plugin-todo")
+
+			t.Run("etag is stable", func(t *testing.T) {
+				resp := get("per_page=-1&page=0", nil)
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+				require.Equal(t, etag, resp.Header.Get("ETag"))
+			})
+
+			t.Run("etag depends on filter", func(t *testing.T) {
+				resp := get("per_page=-1&filter=matterpoll", nil)
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+				require.NotEqual(t, etag, resp.Header.Get("ETag"))
+				require.Equal(t, "plugins plugin:matterpoll", resp.Header.Get("Surrogate-Key"))
+			})
+
+			t.Run("matching If-None-Match", func(t *testing.T) {
+				resp := get("per_page=-1", http.Header{"If-None-Match": []string{`"other", ` + etag}})
+				require.Equal(t, http.StatusNotModified, resp.StatusCode)
+				require.Equal(t, etag, resp.Header.Get("ETag"))
+			})
+
+			t.Run("stale If-None-Match", func(t *testing.T) {
+				resp := get("per_page=-1", http.Header{"If-None-Match": []string{`"other"`}})
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+			})
+
+			t.Run("If-Modified-Since", func(t *testing.T) {
+				resp := get("per_page=-1", http.Header{"If-Modified-Since": []string{"Wed, 04 Mar 2020 05:06:07 GMT"}})
+				require.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+				resp = get("per_page=-1", http.Header{"If-Modified-Since": []string{"Wed, 04 Mar 2020 05:06:06 GMT"}})
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+			})
+		})
+
+		t.Run("get plugin, latest version", func(t *testing.T) {
+			client, tearDown := setupAPI(t, allPlugins)
+			defer tearDown()
+
+			plugins, err := client.GetPlugin(&api.GetPluginsRequest{
+				PerPage:       -1,
+				ServerVersion: "5.16.0",
+			}, "matterpoll")
+			require.NoError(t, err)
+			require.Equal(t, []*model.Plugin{plugin3V2Min516}, plugins)
+		})
+
+		t.Run("get plugin, all versions", func(t *testing.T) {
+			client, tearDown := setupAPI(t, allPlugins)
+			defer tearDown()
+
+			plugins, err := client.GetPlugin(&api.GetPluginsRequest{
+				PerPage:           -1,
+				ReturnAllVersions: true,
+			}, "matterpoll")
+			require.NoError(t, err)
+			require.Equal(t, []*model.Plugin{plugin3V3Min517, plugin3V2Min516, plugin3V1NoMin}, plugins)
+		})
+
+		t.Run("get plugin, unknown id", func(t *testing.T) {
+			client, tearDown := setupAPI(t, allPlugins)
+			defer tearDown()
+
+			plugins, err := client.GetPlugin(&api.GetPluginsRequest{
+				PerPage: -1,
+			}, "unknown")
+			require.Error(t, err)
+			require.Nil(t, plugins)
+
+			resp, err := http.Get(fmt.Sprintf("%s/api/v1/plugins/unknown", client.Address))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusNotFound, resp.StatusCode)
+			require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			require.NotEmpty(t, body["message"])
+		})
+
+		t.Run("get plugin version", func(t *testing.T) {
+			client, tearDown := setupAPI(t, allPlugins)
+			defer tearDown()
+
+			plugin, err := client.GetPluginVersion(&api.GetPluginsRequest{}, "matterpoll", "1.2.0")
+			require.NoError(t, err)
+			require.Equal(t, plugin3V2Min516, plugin)
+
+			plugin, err = client.GetPluginVersion(&api.GetPluginsRequest{}, "matterpoll", "v1.1.0")
+			require.NoError(t, err)
+			require.Equal(t, plugin3V1NoMin, plugin)
+		})
+
+		t.Run("get plugin icon", func(t *testing.T) {
+			svg := `<svg xmlns="http://www.w3.org/2000/svg"></svg>`
+			withIcon := &model.Plugin{}
+			*withIcon = *plugin3V2Min516
+			withIcon.IconData = "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(svg))
+
+			client, tearDown := setupAPI(t, []*model.Plugin{plugin3V1NoMin, withIcon, plugin1V1Min515})
+			defer tearDown()
+
+			icon, contentType, err := client.GetPluginIcon(&api.GetPluginsRequest{}, "matterpoll")
+			require.NoError(t, err)
+			require.Equal(t, svg, string(icon))
+			require.Equal(t, "image/svg+xml", contentType)
+
+			resp, err := http.Get(fmt.Sprintf("%s/api/v1/plugins/matterpoll/icon", client.Address))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, "public, max-age=86400", resp.Header.Get("Cache-Control"))
+			require.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"))
+			require.NotEmpty(t, resp.Header.Get("Content-Security-Policy"))
+			etag := resp.Header.Get("ETag")
+			require.NotEmpty(t, etag)
+
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/v1/plugins/matterpoll/icon", client.Address), nil)
+			require.NoError(t, err)
+			req.Header.Set("If-None-Match", etag)
+			resp, err = http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+			// The icon of an older version is an invalid data URI.
+			resp, err = http.Get(fmt.Sprintf("%s/api/v1/plugins/matterpoll/icon?version=1.1.0", client.Address))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+			_, _, err = client.GetPluginIcon(&api.GetPluginsRequest{}, "unknown")
+			var apiErr *api.Error
+			require.True(t, errors.As(err, &apiErr))
+			require.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+		})
+
+		t.Run("download", func(t *testing.T) {
+			client, tearDown := setupAPI(t, allPlugins)
+			defer tearDown()
+
+			httpClient := &http.Client{
+				CheckRedirect: func(*http.Request, []*http.Request) error {
+					return http.ErrUseLastResponse
+				},
+			}
+			get := func(path string) *http.Response {
+				resp, err := httpClient.Get(client.Address + path)
+				require.NoError(t, err)
+				t.Cleanup(func() { resp.Body.Close() })
+
+				return resp
+			}
+
+			resp := get("/api/v1/plugins/matterpoll/download")
+			require.Equal(t, http.StatusFound, resp.StatusCode)
+			require.Equal(t, plugin3V3Min517.DownloadURL, resp.Header.Get("Location"))
+			require.Equal(t, "1.3.0", resp.Header.Get("X-Plugin-Version"))
+			require.Equal(t, plugin3V3Min517.Signature, resp.Header.Get("X-Plugin-Signature"))
+			require.NotEmpty(t, resp.Header.Get("Cache-Control"))
+
+			resp = get("/api/v1/plugins/matterpoll/download?server_version=5.16.0")
+			require.Equal(t, http.StatusFound, resp.StatusCode)
+			require.Equal(t, plugin3V2Min516.DownloadURL, resp.Header.Get("Location"))
+
+			resp = get("/api/v1/plugins/matterpoll/download?version=1.1.0")
+			require.Equal(t, http.StatusFound, resp.StatusCode)
+			require.Equal(t, plugin3V1NoMin.DownloadURL, resp.Header.Get("Location"))
+
+			resp = get("/api/v1/plugins/com.mattermost.plugin-todo/download?platform=darwin-amd64")
+			require.Equal(t, http.StatusFound, resp.StatusCode)
+			require.Equal(t, plugin6WithPlatform.Platforms.DarwinAmd64.DownloadURL, resp.Header.Get("Location"))
+			require.Equal(t, plugin6WithPlatform.Platforms.DarwinAmd64.Signature, resp.Header.Get("X-Plugin-Signature"))
+
+			resp = get("/api/v1/plugins/com.mattermost.plugin-todo/download")
+			require.Equal(t, http.StatusFound, resp.StatusCode)
+			require.Equal(t, plugin6WithPlatform.DownloadURL, resp.Header.Get("Location"))
+
+			resp = get("/api/v1/plugins/matterpoll/download?version=9.9.9")
+			require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+			resp = get("/api/v1/plugins/matterpoll/download?version=invalid")
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+			resp = get("/api/v1/plugins/unknown/download")
+			require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		})
+
+		t.Run("signature", func(t *testing.T) {
+			signed := &model.Plugin{}
+			*signed = *plugin3V1NoMin
+			signed.Signature = base64.StdEncoding.EncodeToString([]byte("signature"))
+
+			client, tearDown := setupAPI(t, []*model.Plugin{signed})
+			defer tearDown()
+
+			resp, err := http.Get(client.Address + "/api/v1/plugins/matterpoll/signature")
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, "application/octet-stream", resp.Header.Get("Content-Type"))
+			require.Equal(t, signed.Manifest.Version, resp.Header.Get("X-Plugin-Version"))
+
+			body, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Equal(t, "signature", string(body))
+		})
+
+		t.Run("sparse fieldsets", func(t *testing.T) {
+			client, tearDown := setupAPI(t, allPlugins)
+			defer tearDown()
+
+			plugins, err := client.GetPlugins(&api.GetPluginsRequest{
+				PerPage:  -1,
+				PluginID: "matterpoll",
+				Fields:   []string{"-icon_data", "-manifest.min_server_version"},
+			})
+			require.NoError(t, err)
+			require.Len(t, plugins, 1)
+			require.Empty(t, plugins[0].IconData)
+			require.Empty(t, plugins[0].Manifest.MinServerVersion)
+			require.Equal(t, plugin3V3Min517.DownloadURL, plugins[0].DownloadURL)
+			require.Equal(t, "matterpoll", plugins[0].Manifest.Id)
+
+			response, err := client.GetPluginsPage(&api.GetPluginsRequest{
+				PerPage: 1,
+				Fields:  []string{"manifest.id", "manifest.version"},
+			})
+			require.NoError(t, err)
+			require.Len(t, response.Plugins, 1)
+			require.Equal(t, &model.Plugin{Manifest: &mattermostModel.Manifest{
+				Id:      response.Plugins[0].Manifest.Id,
+				Version: response.Plugins[0].Manifest.Version,
+			}}, response.Plugins[0])
+			require.NotEmpty(t, response.Plugins[0].Manifest.Id)
+			require.Positive(t, response.Total)
+
+			plugin, err := client.GetPluginVersion(&api.GetPluginsRequest{Fields: []string{"-icon_data"}}, "matterpoll", "1.2.0")
+			require.NoError(t, err)
+			require.Empty(t, plugin.IconData)
+			require.Equal(t, plugin3V2Min516.DownloadURL, plugin.DownloadURL)
+
+			resp, err := http.Get(fmt.Sprintf("%s/api/v1/plugins?fields=unknown", client.Address))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("get plugin version, incompatible with server version", func(t *testing.T) {
+			client, tearDown := setupAPI(t, allPlugins)
+			defer tearDown()
+
+			plugin, err := client.GetPluginVersion(&api.GetPluginsRequest{
+				ServerVersion: "5.16.0",
+			}, "matterpoll", "1.3.0")
+			require.Error(t, err)
+			require.Nil(t, plugin)
+		})
+
+		t.Run("get plugin version, unknown version", func(t *testing.T) {
+			client, tearDown := setupAPI(t, allPlugins)
+			defer tearDown()
+
+			resp, err := http.Get(fmt.Sprintf("%s/api/v1/plugins/matterpoll/versions/9.9.9", client.Address))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+			resp, err = http.Get(fmt.Sprintf("%s/api/v1/plugins/matterpoll/versions/invalid", client.Address))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
 	})
 }
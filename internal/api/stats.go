@@ -0,0 +1,94 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// handleGetPluginStats responds to GET /api/v1/plugins/{plugin_id}/stats, summarizing the
+// downloads of the given plugin through the marketplace.
+func handleGetPluginStats(c *Context, w http.ResponseWriter, r *http.Request) {
+	pluginID := mux.Vars(r)["plugin_id"]
+
+	if c.Stats == nil {
+		outputError(c, w, newNotFoundError("statistics are not enabled"))
+		return
+	}
+
+	if !pluginVisible(c, pluginID) {
+		outputError(c, w, newNotFoundError("plugin %s not found", pluginID))
+		return
+	}
+
+	stats, err := c.Stats.GetPluginStats(pluginID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get plugin stats")
+		outputError(c, w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	outputJSON(c, w, stats)
+}
+
+// recordDownload counts a download of the given plugin, if statistics are enabled.
+//
+// Failing to count the download is logged rather than failing the download.
+func recordDownload(c *Context, r *http.Request, plugin *model.Plugin) {
+	if c.Stats == nil {
+		return
+	}
+
+	err := c.Stats.RecordDownload(&model.Download{
+		PluginID: plugin.Manifest.Id,
+		Version:  plugin.Manifest.Version,
+		Platform: downloadPlatform(r),
+		Time:     time.Now(),
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to record download")
+	}
+}
+
+// includesDownloadCounts reports whether responses to the given filter include download counts.
+func includesDownloadCounts(c *Context, filter *model.PluginFilter) bool {
+	return c.Stats != nil && filter.DownloadCounts
+}
+
+// downloadPlatform returns the platform for which a download was requested, or the empty string if
+// the platform is not one for which bundles are published.
+func downloadPlatform(r *http.Request) string {
+	platform := r.URL.Query().Get("platform")
+	switch platform {
+	case model.LinuxAmd64, model.DarwinAmd64, model.WindowsAmd64:
+		return platform
+	default:
+		return ""
+	}
+}
+
+// addDownloadCounts annotates the given plugins with their download counts, if requested by the
+// filter and statistics are enabled.
+//
+// Failing to get the counts is logged rather than failing the request, leaving the counts unset.
+func addDownloadCounts(c *Context, filter *model.PluginFilter, plugins []*model.Plugin) {
+	if !includesDownloadCounts(c, filter) || len(plugins) == 0 {
+		return
+	}
+
+	counts, err := c.Stats.GetDownloadCounts()
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get download counts")
+		return
+	}
+
+	for _, plugin := range plugins {
+		count := counts[plugin.Manifest.Id]
+		plugin.DownloadCount = &count
+	}
+}
@@ -0,0 +1,183 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/api"
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/stats"
+	"github.com/mattermost/mattermost-marketplace/internal/store"
+	"github.com/mattermost/mattermost-marketplace/internal/testlib"
+)
+
+func TestStats(t *testing.T) {
+	newPlugin := func(id, version string) *model.Plugin {
+		return &model.Plugin{
+			DownloadURL: "https://example.com/" + id + "-" + version + ".tar.gz",
+			Manifest: &mattermostModel.Manifest{
+				Id:      id,
+				Name:    id,
+				Version: version,
+			},
+		}
+	}
+
+	setup := func(t *testing.T, apiStats api.Stats, restrictions ...*model.PluginFilter) *api.Client {
+		logger := testlib.MakeLogger(t)
+
+		staticStore, err := store.NewStatic([]*model.Plugin{
+			newPlugin("demo", "1.0.0"),
+			newPlugin("demo", "2.0.0"),
+			newPlugin("other", "1.0.0"),
+		}, model.DefaultLabelDefinitions, logger)
+		require.NoError(t, err)
+
+		var apiStore api.Store = staticStore
+		for _, restriction := range restrictions {
+			apiStore = store.NewRestricted(apiStore, restriction)
+		}
+
+		router := mux.NewRouter()
+		api.Register(router, &api.Context{
+			Store:  apiStore,
+			Stats:  apiStats,
+			Logger: logger,
+		})
+		ts := httptest.NewServer(router)
+		t.Cleanup(ts.Close)
+
+		return api.NewClient(ts.URL)
+	}
+
+	download := func(t *testing.T, client *api.Client, query string) {
+		httpClient := &http.Client{
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+
+		resp, err := httpClient.Get(client.Address + "/api/v1/plugins/demo/download?" + query)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusFound, resp.StatusCode)
+	}
+
+	t.Run("downloads are counted", func(t *testing.T) {
+		client := setup(t, stats.NewMemory())
+
+		download(t, client, "")
+		download(t, client, "platform=linux-amd64")
+		download(t, client, "platform=linux-amd64")
+		download(t, client, "version=1.0.0")
+		download(t, client, "version=1.0.0&platform=../../etc")
+
+		pluginStats, err := client.GetPluginStats("demo")
+		require.NoError(t, err)
+
+		today := time.Now().UTC().Format(model.DayFormat)
+		require.Equal(t, &model.PluginStats{
+			PluginID:      "demo",
+			DownloadCount: 5,
+			Downloads: []*model.DownloadStats{
+				{Version: "1.0.0", Day: today, Count: 2},
+				{Version: "2.0.0", Day: today, Count: 1},
+				{Version: "2.0.0", Platform: "linux-amd64", Day: today, Count: 2},
+			},
+		}, pluginStats)
+
+		pluginStats, err = client.GetPluginStats("other")
+		require.NoError(t, err)
+		require.Equal(t, int64(0), pluginStats.DownloadCount)
+		require.Empty(t, pluginStats.Downloads)
+	})
+
+	t.Run("download counts are returned with plugins if requested", func(t *testing.T) {
+		client := setup(t, stats.NewMemory())
+
+		plugins, err := client.GetPlugins(&api.GetPluginsRequest{PerPage: -1})
+		require.NoError(t, err)
+		require.Len(t, plugins, 2)
+		for _, plugin := range plugins {
+			require.Nil(t, plugin.DownloadCount)
+		}
+
+		plugins, err = client.GetPlugins(&api.GetPluginsRequest{PerPage: -1, DownloadCounts: true})
+		require.NoError(t, err)
+		require.Len(t, plugins, 2)
+		for _, plugin := range plugins {
+			require.NotNil(t, plugin.DownloadCount)
+			require.Equal(t, int64(0), *plugin.DownloadCount)
+		}
+
+		download(t, client, "")
+
+		plugins, err = client.GetPlugin(&api.GetPluginsRequest{PerPage: -1, DownloadCounts: true}, "demo")
+		require.NoError(t, err)
+		require.Len(t, plugins, 1)
+		require.NotNil(t, plugins[0].DownloadCount)
+		require.Equal(t, int64(1), *plugins[0].DownloadCount)
+	})
+
+	t.Run("downloads do not invalidate listings without counts", func(t *testing.T) {
+		client := setup(t, stats.NewMemory())
+
+		get := func(t *testing.T, query, etag string) *http.Response {
+			request, err := http.NewRequest(http.MethodGet, client.Address+"/api/v1/plugins?"+query, nil)
+			require.NoError(t, err)
+			if etag != "" {
+				request.Header.Set("If-None-Match", etag)
+			}
+
+			resp, err := http.DefaultClient.Do(request)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			return resp
+		}
+
+		resp := get(t, "", "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		etag := resp.Header.Get("ETag")
+
+		resp = get(t, "download_counts=true", "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Empty(t, resp.Header.Get("Last-Modified"))
+		countsETag := resp.Header.Get("ETag")
+		require.NotEqual(t, etag, countsETag)
+
+		download(t, client, "")
+
+		resp = get(t, "", etag)
+		require.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+		resp = get(t, "download_counts=true", countsETag)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("restricted plugins", func(t *testing.T) {
+		client := setup(t, stats.NewMemory(), &model.PluginFilter{Labels: []string{"missing"}})
+
+		_, err := client.GetPluginStats("demo")
+		require.Error(t, err)
+	})
+
+	t.Run("stats disabled", func(t *testing.T) {
+		client := setup(t, nil)
+
+		download(t, client, "")
+
+		plugins, err := client.GetPlugins(&api.GetPluginsRequest{PerPage: -1})
+		require.NoError(t, err)
+		require.Nil(t, plugins[0].DownloadCount)
+
+		_, err = client.GetPluginStats("demo")
+		require.Error(t, err)
+	})
+}
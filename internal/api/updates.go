@@ -0,0 +1,220 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// maxUpdateChecks is the largest number of installed plugins that may be checked in one request.
+const maxUpdateChecks = 1000
+
+// InstalledPlugin identifies a version of a plugin installed on a server.
+type InstalledPlugin struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+}
+
+// PluginUpdatesRequest describes the plugins installed on a server, alongside the server itself,
+// to be checked for updates.
+type PluginUpdatesRequest struct {
+	Plugins           []*InstalledPlugin `json:"plugins"`
+	ServerVersion     string             `json:"server_version"`
+	EnterprisePlugins bool               `json:"enterprise_plugins"`
+	Cloud             bool               `json:"cloud"`
+	Platform          string             `json:"platform"`
+}
+
+// PluginUpdate describes the newest version of an installed plugin compatible with the server.
+type PluginUpdate struct {
+	ID               string `json:"id"`
+	InstalledVersion string `json:"installed_version"`
+
+	// LatestVersion is the newest compatible version, or empty if no version is compatible.
+	LatestVersion   string `json:"latest_version,omitempty"`
+	UpdateAvailable bool   `json:"update_available"`
+
+	// Plugin is the release of the newest compatible version, if newer than the installed version.
+	Plugin *model.Plugin `json:"plugin,omitempty"`
+
+	// ReleaseNotesURLs are the release notes of each compatible version newer than the installed
+	// version, up to and including the latest version, from oldest to newest. Yanked versions are
+	// skipped.
+	ReleaseNotesURLs []string `json:"release_notes_urls"`
+
+	// Warning describes the withdrawal of the installed version, if it has been yanked.
+	Warning string `json:"warning,omitempty"`
+}
+
+// PluginUpdatesResponse is returned by POST /api/v1/plugins/updates, describing an update for
+// each installed plugin in the order requested.
+type PluginUpdatesResponse struct {
+	Updates []*PluginUpdate `json:"updates"`
+}
+
+// parsePluginUpdatesRequest decodes and validates the body of an update check, returning the
+// parsed version of each installed plugin in the order given.
+//
+// Any error returned is an *Error identifying the offending field.
+func parsePluginUpdatesRequest(r *http.Request) (*PluginUpdatesRequest, []semver.Version, error) {
+	var request PluginUpdatesRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		return nil, nil, newInvalidParameterError("body", errors.Wrap(err, "failed to parse request body"))
+	}
+
+	if len(request.Plugins) == 0 {
+		return nil, nil, newInvalidParameterError("plugins", errors.New("at least one plugin must be given"))
+	}
+	if len(request.Plugins) > maxUpdateChecks {
+		return nil, nil, newInvalidParameterError("plugins", errors.Errorf("at most %d plugins may be given", maxUpdateChecks))
+	}
+
+	if request.ServerVersion != "" {
+		_, err = semver.Parse(request.ServerVersion)
+		if err != nil {
+			return nil, nil, newInvalidParameterError("server_version", errors.Wrapf(err, "failed to parse server_version %s", request.ServerVersion))
+		}
+	}
+
+	installedVersions := make([]semver.Version, 0, len(request.Plugins))
+	for _, installed := range request.Plugins {
+		if installed == nil || installed.ID == "" {
+			return nil, nil, newInvalidParameterError("plugins", errors.New("plugin id must not be empty"))
+		}
+
+		version, err := semver.ParseTolerant(installed.Version)
+		if err != nil {
+			return nil, nil, newInvalidParameterError("plugins", errors.Wrapf(err, "failed to parse version %s of plugin %s", installed.Version, installed.ID))
+		}
+		installedVersions = append(installedVersions, version)
+	}
+
+	return &request, installedVersions, nil
+}
+
+// handlePostPluginUpdates responds to POST /api/v1/plugins/updates, returning the newest
+// compatible version of each of the given installed plugins.
+//
+// The latest version of each plugin is selected by the store exactly as when listing plugins,
+// with the versions in between consulted only for their release notes.
+func handlePostPluginUpdates(c *Context, w http.ResponseWriter, r *http.Request) {
+	request, installedVersions, err := parsePluginUpdatesRequest(r)
+	if err != nil {
+		c.Logger.WithError(err).Warn("failed to parse plugin updates request")
+		outputError(c, w, err)
+		return
+	}
+
+	filter := &model.PluginFilter{
+		PerPage:           model.AllPerPage,
+		ServerVersion:     request.ServerVersion,
+		EnterprisePlugins: request.EnterprisePlugins,
+		Cloud:             request.Cloud,
+		Platform:          request.Platform,
+	}
+	if len(request.Plugins) == 1 {
+		filter.PluginID = request.Plugins[0].ID
+	}
+
+	latestPlugins, err := c.Store.GetPlugins(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query latest plugins")
+		outputError(c, w, err)
+		return
+	}
+
+	allFilter := *filter
+	allFilter.ReturnAllVersions = true
+	allFilter.IncludeYanked = true
+	allPlugins, err := c.Store.GetPlugins(&allFilter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query plugin versions")
+		outputError(c, w, err)
+		return
+	}
+
+	latestByID := make(map[string]*model.Plugin, len(latestPlugins))
+	for _, plugin := range localizePlugins(c, latestPlugins) {
+		latestByID[plugin.Manifest.Id] = plugin
+	}
+
+	versionsByID := make(map[string][]*pluginVersion)
+	for _, plugin := range allPlugins {
+		version, err := semver.Parse(plugin.Manifest.Version)
+		if err != nil {
+			c.Logger.WithError(err).Warnf("failed to parse version %s of plugin %s", plugin.Manifest.Version, plugin.Manifest.Id)
+			continue
+		}
+		versionsByID[plugin.Manifest.Id] = append(versionsByID[plugin.Manifest.Id], &pluginVersion{plugin, version})
+	}
+
+	response := &PluginUpdatesResponse{
+		Updates: make([]*PluginUpdate, 0, len(request.Plugins)),
+	}
+	for i, installed := range request.Plugins {
+		update := newPluginUpdate(
+			installed,
+			installedVersions[i],
+			latestByID[installed.ID],
+			versionsByID[installed.ID],
+		)
+		response.Updates = append(response.Updates, update)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	outputJSON(c, w, response)
+}
+
+// pluginVersion pairs a release with its parsed version.
+type pluginVersion struct {
+	plugin  *model.Plugin
+	version semver.Version
+}
+
+// newPluginUpdate describes the update from the installed version to the given latest release,
+// collecting the release notes of the compatible versions in between and warning if the installed
+// version has been yanked.
+func newPluginUpdate(installed *InstalledPlugin, installedVersion semver.Version, latest *model.Plugin, versions []*pluginVersion) *PluginUpdate {
+	update := &PluginUpdate{
+		ID:               installed.ID,
+		InstalledVersion: installed.Version,
+		ReleaseNotesURLs: []string{},
+	}
+	for _, v := range versions {
+		if v.version.EQ(installedVersion) && v.plugin.IsYanked() {
+			update.Warning = v.plugin.YankWarning()
+		}
+	}
+	if latest == nil {
+		return update
+	}
+	update.LatestVersion = latest.Manifest.Version
+
+	latestVersion, err := semver.Parse(latest.Manifest.Version)
+	if err != nil || latestVersion.LTE(installedVersion) {
+		return update
+	}
+	update.UpdateAvailable = true
+	update.Plugin = latest
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].version.LT(versions[j].version)
+	})
+	for _, v := range versions {
+		if v.version.LTE(installedVersion) || v.version.GT(latestVersion) || v.plugin.IsYanked() {
+			continue
+		}
+		if v.plugin.ReleaseNotesURL != "" {
+			update.ReleaseNotesURLs = append(update.ReleaseNotesURLs, v.plugin.ReleaseNotesURL)
+		}
+	}
+
+	return update
+}
@@ -0,0 +1,134 @@
+package api_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/api"
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/store"
+	"github.com/mattermost/mattermost-marketplace/internal/testlib"
+)
+
+func TestPluginUpdates(t *testing.T) {
+	newPlugin := func(id, version, minServerVersion string) *model.Plugin {
+		return &model.Plugin{
+			ReleaseNotesURL: "https://example.com/" + id + "/releases/v" + version,
+			Manifest: &mattermostModel.Manifest{
+				Id:               id,
+				Name:             id,
+				Version:          version,
+				MinServerVersion: minServerVersion,
+			},
+		}
+	}
+
+	demoV1 := newPlugin("demo", "1.0.0", "")
+	demoV11 := newPlugin("demo", "1.1.0", "")
+	demoV12 := newPlugin("demo", "1.2.0", "5.20.0")
+	demoV2 := newPlugin("demo", "2.0.0", "5.30.0")
+	otherV1 := newPlugin("other", "1.0.0", "5.30.0")
+
+	logger := testlib.MakeLogger(t)
+	staticStore, err := store.NewStatic([]*model.Plugin{demoV1, demoV11, demoV12, demoV2, otherV1}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{Store: staticStore, Logger: logger})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := api.NewClient(ts.URL)
+
+	t.Run("updates limited by server version", func(t *testing.T) {
+		response, err := client.GetPluginUpdates(&api.PluginUpdatesRequest{
+			Plugins: []*api.InstalledPlugin{
+				{ID: "demo", Version: "1.0.0"},
+				{ID: "other", Version: "0.9.0"},
+				{ID: "unknown", Version: "1.0.0"},
+			},
+			ServerVersion: "5.25.0",
+		})
+		require.NoError(t, err)
+		require.Len(t, response.Updates, 3)
+
+		require.Equal(t, "demo", response.Updates[0].ID)
+		require.Equal(t, "1.0.0", response.Updates[0].InstalledVersion)
+		require.Equal(t, "1.2.0", response.Updates[0].LatestVersion)
+		require.True(t, response.Updates[0].UpdateAvailable)
+		require.Equal(t, "1.2.0", response.Updates[0].Plugin.Manifest.Version)
+		require.Equal(t, []string{demoV11.ReleaseNotesURL, demoV12.ReleaseNotesURL}, response.Updates[0].ReleaseNotesURLs)
+
+		require.Equal(t, &api.PluginUpdate{
+			ID:               "other",
+			InstalledVersion: "0.9.0",
+			ReleaseNotesURLs: []string{},
+		}, response.Updates[1])
+
+		require.Equal(t, &api.PluginUpdate{
+			ID:               "unknown",
+			InstalledVersion: "1.0.0",
+			ReleaseNotesURLs: []string{},
+		}, response.Updates[2])
+	})
+
+	t.Run("latest version installed", func(t *testing.T) {
+		response, err := client.GetPluginUpdates(&api.PluginUpdatesRequest{
+			Plugins: []*api.InstalledPlugin{{ID: "demo", Version: "v2.0.0"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*api.PluginUpdate{{
+			ID:               "demo",
+			InstalledVersion: "v2.0.0",
+			LatestVersion:    "2.0.0",
+			ReleaseNotesURLs: []string{},
+		}}, response.Updates)
+	})
+
+	t.Run("all updates without server version", func(t *testing.T) {
+		response, err := client.GetPluginUpdates(&api.PluginUpdatesRequest{
+			Plugins: []*api.InstalledPlugin{{ID: "demo", Version: "1.1.0"}},
+		})
+		require.NoError(t, err)
+		require.Len(t, response.Updates, 1)
+		require.Equal(t, "2.0.0", response.Updates[0].LatestVersion)
+		require.Equal(t, []string{demoV12.ReleaseNotesURL, demoV2.ReleaseNotesURL}, response.Updates[0].ReleaseNotesURLs)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		testCases := []struct {
+			Description string
+			Request     *api.PluginUpdatesRequest
+			Parameter   string
+		}{
+			{"no plugins", &api.PluginUpdatesRequest{}, "plugins"},
+			{"empty id", &api.PluginUpdatesRequest{Plugins: []*api.InstalledPlugin{{Version: "1.0.0"}}}, "plugins"},
+			{"invalid version", &api.PluginUpdatesRequest{Plugins: []*api.InstalledPlugin{{ID: "demo", Version: "latest"}}}, "plugins"},
+			{"invalid server version", &api.PluginUpdatesRequest{Plugins: []*api.InstalledPlugin{{ID: "demo", Version: "1.0.0"}}, ServerVersion: "5"}, "server_version"},
+		}
+
+		for _, testCase := range testCases {
+			testCase := testCase
+			t.Run(testCase.Description, func(t *testing.T) {
+				_, err := client.GetPluginUpdates(testCase.Request)
+				require.Error(t, err)
+
+				var apiErr *api.Error
+				require.ErrorAs(t, err, &apiErr)
+				require.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+				require.Equal(t, testCase.Parameter, apiErr.Parameter)
+			})
+		}
+
+		resp, err := http.Post(ts.URL+"/api/v1/plugins/updates", "application/json", bytes.NewReader([]byte("not json")))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
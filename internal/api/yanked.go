@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// warnYanked returns the given plugins with a warning set on copies of any yanked versions, which
+// are only served when requested explicitly.
+func warnYanked(plugins []*model.Plugin) []*model.Plugin {
+	warned := make([]*model.Plugin, 0, len(plugins))
+	for _, plugin := range plugins {
+		if plugin.IsYanked() {
+			yanked := *plugin
+			yanked.Warning = plugin.YankWarning()
+			plugin = &yanked
+		}
+		warned = append(warned, plugin)
+	}
+
+	return warned
+}
+
+// setYankedHeader warns of serving the bundle of a yanked version through a Warning header, as
+// described by RFC 7234, since the bundle itself cannot carry the warning.
+func setYankedHeader(w http.ResponseWriter, plugin *model.Plugin) {
+	if !plugin.IsYanked() {
+		return
+	}
+
+	w.Header().Set("Warning", "299 - "+strconv.Quote(plugin.YankWarning()))
+}
@@ -0,0 +1,125 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/api"
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/store"
+	"github.com/mattermost/mattermost-marketplace/internal/testlib"
+)
+
+func TestYankedVersions(t *testing.T) {
+	newPlugin := func(version string) *model.Plugin {
+		return &model.Plugin{
+			DownloadURL:     "https://example.com/demo-" + version + ".tar.gz",
+			ReleaseNotesURL: "https://example.com/demo/releases/v" + version,
+			Manifest: &mattermostModel.Manifest{
+				Id:      "demo",
+				Name:    "Demo",
+				Version: version,
+			},
+		}
+	}
+
+	demoV1 := newPlugin("1.0.0")
+	demoV11 := newPlugin("1.1.0")
+	demoV11.Yanked = &model.Yank{
+		Reason:   "Corrupts data",
+		YankedAt: time.Date(2021, time.October, 4, 0, 0, 0, 0, time.UTC),
+	}
+	demoV12 := newPlugin("1.2.0")
+	demoV2 := newPlugin("2.0.0")
+	demoV2.Yanked = &model.Yank{Reason: "Fails to start"}
+
+	logger := testlib.MakeLogger(t)
+	staticStore, err := store.NewStatic([]*model.Plugin{demoV1, demoV11, demoV12, demoV2}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{Store: staticStore, Logger: logger})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := api.NewClient(ts.URL)
+
+	t.Run("latest version skips yanked versions", func(t *testing.T) {
+		plugins, err := client.GetPlugins(&api.GetPluginsRequest{PerPage: -1})
+		require.NoError(t, err)
+		require.Len(t, plugins, 1)
+		require.Equal(t, "1.2.0", plugins[0].Manifest.Version)
+		require.Empty(t, plugins[0].Warning)
+	})
+
+	t.Run("all versions omit yanked versions by default", func(t *testing.T) {
+		plugins, err := client.GetPlugin(&api.GetPluginsRequest{PerPage: -1, ReturnAllVersions: true}, "demo")
+		require.NoError(t, err)
+		require.Len(t, plugins, 2)
+	})
+
+	t.Run("yanked versions included on request", func(t *testing.T) {
+		plugins, err := client.GetPlugin(&api.GetPluginsRequest{PerPage: -1, ReturnAllVersions: true, IncludeYanked: true}, "demo")
+		require.NoError(t, err)
+		require.Len(t, plugins, 4)
+
+		for _, plugin := range plugins {
+			require.Equal(t, plugin.IsYanked(), plugin.Warning != "", plugin.Manifest.Version)
+		}
+	})
+
+	t.Run("explicit version carries a warning", func(t *testing.T) {
+		plugin, err := client.GetPluginVersion(&api.GetPluginsRequest{}, "demo", "1.1.0")
+		require.NoError(t, err)
+		require.NotNil(t, plugin.Yanked)
+		require.Equal(t, "version 1.1.0 of plugin demo was yanked on 2021-10-04: Corrupts data", plugin.Warning)
+	})
+
+	t.Run("download of explicit version carries a warning header", func(t *testing.T) {
+		httpClient := &http.Client{
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+
+		resp, err := httpClient.Get(ts.URL + "/api/v1/plugins/demo/download?version=1.1.0")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusFound, resp.StatusCode)
+		require.Equal(t, demoV11.DownloadURL, resp.Header.Get("Location"))
+		require.Equal(t, `299 - "version 1.1.0 of plugin demo was yanked on 2021-10-04: Corrupts data"`, resp.Header.Get("Warning"))
+
+		resp, err = httpClient.Get(ts.URL + "/api/v1/plugins/demo/download")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, demoV12.DownloadURL, resp.Header.Get("Location"))
+		require.Empty(t, resp.Header.Get("Warning"))
+	})
+
+	t.Run("updates skip yanked versions", func(t *testing.T) {
+		response, err := client.GetPluginUpdates(&api.PluginUpdatesRequest{
+			Plugins: []*api.InstalledPlugin{{ID: "demo", Version: "1.0.0"}},
+		})
+		require.NoError(t, err)
+		require.Len(t, response.Updates, 1)
+		require.Equal(t, "1.2.0", response.Updates[0].LatestVersion)
+		require.Equal(t, []string{demoV12.ReleaseNotesURL}, response.Updates[0].ReleaseNotesURLs)
+		require.Empty(t, response.Updates[0].Warning)
+	})
+
+	t.Run("updates warn of an installed yanked version", func(t *testing.T) {
+		response, err := client.GetPluginUpdates(&api.PluginUpdatesRequest{
+			Plugins: []*api.InstalledPlugin{{ID: "demo", Version: "1.1.0"}},
+		})
+		require.NoError(t, err)
+		require.Len(t, response.Updates, 1)
+		require.True(t, response.Updates[0].UpdateAvailable)
+		require.Equal(t, "version 1.1.0 of plugin demo was yanked on 2021-10-04: Corrupts data", response.Updates[0].Warning)
+	})
+}
@@ -0,0 +1,107 @@
+// Package bundle reads plugin bundles, and stores them for serving from the marketplace.
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+)
+
+// MaxDecompressedSize is the largest uncompressed tar file read from a plugin bundle, comfortably
+// above the size of a bundle including server binaries for every platform.
+const MaxDecompressedSize = 1 << 30
+
+// ErrTooLarge is returned when a plugin bundle decompresses to more than the permitted size.
+var ErrTooLarge = errors.New("plugin bundle is too large when decompressed")
+
+// Decompress reads the uncompressed tar file of a gzipped plugin bundle, failing with ErrTooLarge
+// rather than reading more than maxSize bytes.
+func Decompress(reader io.Reader, maxSize int64) ([]byte, error) {
+	gzBundleReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read gzipped plugin bundle")
+	}
+
+	// Read a byte beyond the limit to tell a bundle of exactly maxSize from a larger one.
+	bundleData, err := ioutil.ReadAll(io.LimitReader(gzBundleReader, maxSize+1))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read plugin bundle")
+	}
+	if int64(len(bundleData)) > maxSize {
+		return nil, errors.Wrapf(ErrTooLarge, "exceeds %d bytes", maxSize)
+	}
+
+	return bundleData, nil
+}
+
+// GetFromTarFile reads the file at the given path within a plugin bundle.
+func GetFromTarFile(reader *tar.Reader, filepath string) ([]byte, error) {
+	for {
+		hdr, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read tar file")
+		}
+
+		// Match the filepath, assuming the tar file contains a leading folder matching the
+		// plugin id.
+		matched, err := path.Match(fmt.Sprintf("*/%s", filepath), hdr.Name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to match file %s in tar file", filepath)
+		} else if !matched {
+			continue
+		}
+
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s in tar file", filepath)
+		}
+		return data, nil
+	}
+
+	return nil, errors.Errorf("failed to find %s in tar file", filepath)
+}
+
+// GetManifestFromTarFile reads and validates the manifest of the given uncompressed plugin
+// bundle.
+func GetManifestFromTarFile(file []byte) (*mattermostModel.Manifest, error) {
+	manifestData, err := GetFromTarFile(tar.NewReader(bytes.NewReader(file)), "plugin.json")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read manifest from plugin bundle")
+	}
+
+	var manifest mattermostModel.Manifest
+	err = json.Unmarshal(manifestData, &manifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read manifest from plugin bundle")
+	}
+
+	err = manifest.IsValid()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid manifest")
+	}
+
+	return &manifest, nil
+}
+
+// GetIconDataFromTarFile reads the icon at the given path within the given uncompressed plugin
+// bundle, encoding it as a data URL.
+func GetIconDataFromTarFile(file []byte, path string) (string, error) {
+	iconData, err := GetFromTarFile(tar.NewReader(bytes.NewReader(file)), path)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read icon data from plugin bundle for path %s", path)
+	}
+
+	return fmt.Sprintf("data:image/svg+xml;base64,%s", base64.StdEncoding.EncodeToString(iconData)), nil
+}
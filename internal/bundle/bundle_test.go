@@ -0,0 +1,83 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/testlib"
+)
+
+func TestBundle(t *testing.T) {
+	const manifest = `{"id": "com.example.demo", "name": "Demo", "version": "1.2.3", "icon_path": "assets/icon.svg"}`
+
+	t.Run("not gzipped", func(t *testing.T) {
+		_, err := Decompress(bytes.NewReader([]byte("not a bundle")), MaxDecompressedSize)
+		require.Error(t, err)
+	})
+
+	t.Run("too large when decompressed", func(t *testing.T) {
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		_, err := writer.Write(make([]byte, 1<<20))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		_, err = Decompress(bytes.NewReader(compressed.Bytes()), 1<<20-1)
+		require.ErrorIs(t, err, ErrTooLarge)
+
+		data, err := Decompress(bytes.NewReader(compressed.Bytes()), 1<<20)
+		require.NoError(t, err)
+		assert.Len(t, data, 1<<20)
+	})
+
+	t.Run("valid bundle", func(t *testing.T) {
+		data, err := Decompress(bytes.NewReader(testlib.MakeBundle(t, "com.example.demo", map[string]string{
+			"plugin.json":     manifest,
+			"assets/icon.svg": "<svg/>",
+		})), MaxDecompressedSize)
+		require.NoError(t, err)
+
+		pluginManifest, err := GetManifestFromTarFile(data)
+		require.NoError(t, err)
+		assert.Equal(t, "com.example.demo", pluginManifest.Id)
+		assert.Equal(t, "1.2.3", pluginManifest.Version)
+
+		iconData, err := GetIconDataFromTarFile(data, pluginManifest.IconPath)
+		require.NoError(t, err)
+		assert.Equal(t, "data:image/svg+xml;base64,PHN2Zy8+", iconData)
+
+		file, err := GetFromTarFile(tar.NewReader(bytes.NewReader(data)), "plugin.json")
+		require.NoError(t, err)
+		assert.Equal(t, manifest, string(file))
+	})
+
+	t.Run("missing files", func(t *testing.T) {
+		data, err := Decompress(bytes.NewReader(testlib.MakeBundle(t, "com.example.demo", map[string]string{
+			"README.md": "demo",
+		})), MaxDecompressedSize)
+		require.NoError(t, err)
+
+		_, err = GetManifestFromTarFile(data)
+		require.Error(t, err)
+
+		_, err = GetIconDataFromTarFile(data, "assets/icon.svg")
+		require.Error(t, err)
+	})
+
+	t.Run("invalid manifest", func(t *testing.T) {
+		for _, invalid := range []string{`not json`, `{"id": "com.example.demo", "version": "1.2.3"}`} {
+			data, err := Decompress(bytes.NewReader(testlib.MakeBundle(t, "com.example.demo", map[string]string{
+				"plugin.json": invalid,
+			})), MaxDecompressedSize)
+			require.NoError(t, err)
+
+			_, err = GetManifestFromTarFile(data)
+			require.Error(t, err)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package bundle
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Directory stores plugin bundles and their signatures as files in a local directory.
+type Directory struct {
+	path string
+}
+
+// NewDirectory creates a new instance of a directory of bundles, creating the directory if
+// needed.
+func NewDirectory(path string) (*Directory, error) {
+	err := os.MkdirAll(path, 0755)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create bundle directory %s", path)
+	}
+
+	return &Directory{
+		path: path,
+	}, nil
+}
+
+// filename resolves the given name within the directory, rejecting names that would escape it.
+func (d *Directory) filename(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", errors.Errorf("invalid bundle name %s", name)
+	}
+
+	return filepath.Join(d.path, name), nil
+}
+
+// Put stores the given data under the given name. The file appears atomically, and an existing
+// file is never replaced.
+//
+// An error satisfying errors.Is(err, os.ErrExist) is returned if a file is already stored under
+// the given name, including when stored concurrently.
+func (d *Directory) Put(name string, data []byte) error {
+	filename, err := d.filename(name)
+	if err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(d.path, ".upload-")
+	if err != nil {
+		return errors.Wrapf(err, "failed to create temporary file for %s", name)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(data)
+	if err != nil {
+		file.Close()
+		return errors.Wrapf(err, "failed to write %s", name)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %s", name)
+	}
+
+	err = os.Chmod(file.Name(), 0644)
+	if err != nil {
+		return errors.Wrapf(err, "failed to set permissions on %s", name)
+	}
+
+	// Unlike renaming, linking fails if the target exists, so concurrent uploads of the same
+	// name cannot replace each other.
+	err = os.Link(file.Name(), filename)
+	if err != nil {
+		return errors.Wrapf(err, "failed to store %s", name)
+	}
+
+	return nil
+}
+
+// Delete removes the file stored under the given name, if any.
+func (d *Directory) Delete(name string) error {
+	filename, err := d.filename(name)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to delete %s", name)
+	}
+
+	return nil
+}
+
+// Open opens the file stored under the given name, returning its modification time alongside.
+//
+// An error satisfying os.IsNotExist is returned if no such file exists.
+func (d *Directory) Open(name string) (io.ReadSeekCloser, time.Time, error) {
+	filename, err := d.filename(name)
+	if err != nil {
+		return nil, time.Time{}, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, time.Time{}, errors.Wrapf(err, "failed to stat %s", name)
+	}
+
+	return file, info.ModTime(), nil
+}
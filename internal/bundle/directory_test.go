@@ -0,0 +1,62 @@
+package bundle
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundles")
+	directory, err := NewDirectory(path)
+	require.NoError(t, err)
+
+	t.Run("missing file", func(t *testing.T) {
+		_, _, err := directory.Open("missing.tar.gz")
+		require.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("put and open", func(t *testing.T) {
+		require.NoError(t, directory.Put("demo-1.0.0.tar.gz", []byte("first")))
+
+		err := directory.Put("demo-1.0.0.tar.gz", []byte("second"))
+		require.True(t, errors.Is(err, os.ErrExist))
+
+		file, modTime, err := directory.Open("demo-1.0.0.tar.gz")
+		require.NoError(t, err)
+		defer file.Close()
+
+		data, err := ioutil.ReadAll(file)
+		require.NoError(t, err)
+		assert.Equal(t, "first", string(data))
+		assert.False(t, modTime.IsZero())
+
+		entries, err := ioutil.ReadDir(path)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, directory.Put("demo-2.0.0.tar.gz", []byte("data")))
+		require.NoError(t, directory.Delete("demo-2.0.0.tar.gz"))
+		require.NoError(t, directory.Delete("demo-2.0.0.tar.gz"))
+
+		_, _, err := directory.Open("demo-2.0.0.tar.gz")
+		require.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("invalid names", func(t *testing.T) {
+		for _, name := range []string{"", ".", "..", "../escape.tar.gz", "nested/demo.tar.gz", ".hidden"} {
+			assert.Error(t, directory.Put(name, []byte("data")), name)
+			assert.Error(t, directory.Delete(name), name)
+
+			_, _, err := directory.Open(name)
+			assert.True(t, errors.Is(err, os.ErrNotExist), name)
+		}
+	})
+}
@@ -0,0 +1,163 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+)
+
+// Severity describes how severely a vulnerability affects a plugin, as rated by CVSS.
+type Severity string
+
+const (
+	// SeverityLow describes a vulnerability that is difficult to exploit or has little impact.
+	SeverityLow Severity = "low"
+	// SeverityModerate describes a vulnerability exploitable only under specific circumstances.
+	SeverityModerate Severity = "moderate"
+	// SeverityHigh describes a vulnerability that is readily exploitable with significant impact.
+	SeverityHigh Severity = "high"
+	// SeverityCritical describes a vulnerability that is trivially exploitable with severe impact.
+	SeverityCritical Severity = "critical"
+)
+
+// severityRanks orders the severities from least to most severe.
+var severityRanks = map[Severity]int{
+	SeverityLow:      1,
+	SeverityModerate: 2,
+	SeverityHigh:     3,
+	SeverityCritical: 4,
+}
+
+// IsValid reports whether the severity is one of the known severities.
+func (s Severity) IsValid() bool {
+	return severityRanks[s] > 0
+}
+
+// AtLeast reports whether the severity is at least as severe as the given severity.
+func (s Severity) AtLeast(other Severity) bool {
+	return severityRanks[s] >= severityRanks[other]
+}
+
+// Advisory describes a security vulnerability affecting some versions of a plugin.
+type Advisory struct {
+	ID               string   `json:"id"`
+	CVE              string   `json:"cve,omitempty"`
+	PluginID         string   `json:"plugin_id"`
+	AffectedVersions []string `json:"affected_versions"`       // Semver ranges such as ">=1.0.0 <1.2.3", any of which may match
+	FixedVersion     string   `json:"fixed_version,omitempty"` // The first version fixing the vulnerability, if any
+	Severity         Severity `json:"severity"`
+	Description      string   `json:"description"`
+}
+
+// Validate checks that the advisory identifies the plugin and versions it affects.
+func (a *Advisory) Validate() error {
+	if a.ID == "" {
+		return errors.New("advisory id must not be empty")
+	}
+	if a.PluginID == "" {
+		return errors.Errorf("plugin id of advisory %s must not be empty", a.ID)
+	}
+	if len(a.AffectedVersions) == 0 {
+		return errors.Errorf("advisory %s must affect at least one version range", a.ID)
+	}
+	for _, affected := range a.AffectedVersions {
+		if _, err := semver.ParseRange(affected); err != nil {
+			return errors.Wrapf(err, "invalid affected versions %q of advisory %s", affected, a.ID)
+		}
+	}
+	if a.FixedVersion != "" {
+		if _, err := semver.Parse(a.FixedVersion); err != nil {
+			return errors.Wrapf(err, "invalid fixed version of advisory %s", a.ID)
+		}
+	}
+	if !a.Severity.IsValid() {
+		return errors.Errorf("unsupported severity %q of advisory %s", a.Severity, a.ID)
+	}
+
+	return nil
+}
+
+// Affects reports whether the advisory applies to the given version of the given plugin. Versions
+// that cannot be parsed are never affected.
+func (a *Advisory) Affects(pluginID, version string) bool {
+	if a.PluginID != pluginID {
+		return false
+	}
+
+	v, err := semver.Parse(version)
+	if err != nil {
+		return false
+	}
+
+	for _, affected := range a.AffectedVersions {
+		versionRange, err := semver.ParseRange(affected)
+		if err != nil {
+			continue
+		}
+		if versionRange(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AdvisoryFilter describes the parameters used to constrain a set of advisories.
+type AdvisoryFilter struct {
+	PluginID string
+	Version  string   // Matches advisories affecting this version of the plugin, if set
+	Severity Severity // Matches advisories at least this severe, if set
+	CVE      string   // Matches the CVE identifier, ignoring case
+}
+
+// Matches reports whether the advisory satisfies every constraint of the filter.
+func (f *AdvisoryFilter) Matches(advisory *Advisory) bool {
+	if f.PluginID != "" && advisory.PluginID != f.PluginID {
+		return false
+	}
+	if f.Version != "" && !advisory.Affects(advisory.PluginID, f.Version) {
+		return false
+	}
+	if f.Severity != "" && !advisory.Severity.AtLeast(f.Severity) {
+		return false
+	}
+	if f.CVE != "" && !strings.EqualFold(advisory.CVE, f.CVE) {
+		return false
+	}
+
+	return true
+}
+
+// AdvisoriesFromReader decodes and validates a json-encoded list of advisories from the given
+// io.Reader.
+func AdvisoriesFromReader(reader io.Reader) ([]*Advisory, error) {
+	advisories := []*Advisory{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&advisories)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(advisories))
+	for _, advisory := range advisories {
+		if advisory == nil {
+			return nil, errors.New("advisory must not be empty")
+		}
+
+		err = advisory.Validate()
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[advisory.ID] {
+			return nil, errors.Errorf("duplicate advisory %s", advisory.ID)
+		}
+		seen[advisory.ID] = true
+	}
+
+	return advisories, nil
+}
@@ -0,0 +1,120 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdvisoryAffects(t *testing.T) {
+	advisory := &Advisory{
+		ID:               "MMSA-2021-0001",
+		PluginID:         "jira",
+		AffectedVersions: []string{">=2.0.0 <2.4.1", "<1.0.5"},
+		FixedVersion:     "2.4.1",
+		Severity:         SeverityHigh,
+	}
+	require.NoError(t, advisory.Validate())
+
+	testCases := map[string]struct {
+		pluginID string
+		version  string
+		expected bool
+	}{
+		"first range":     {"jira", "2.0.0", true},
+		"upper bound":     {"jira", "2.4.0", true},
+		"fixed version":   {"jira", "2.4.1", false},
+		"second range":    {"jira", "1.0.4", true},
+		"between ranges":  {"jira", "1.5.0", false},
+		"other plugin":    {"github", "2.0.0", false},
+		"invalid version": {"jira", "latest", false},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, advisory.Affects(testCase.pluginID, testCase.version))
+		})
+	}
+}
+
+func TestAdvisoryFilterMatches(t *testing.T) {
+	advisory := &Advisory{
+		ID:               "MMSA-2021-0001",
+		CVE:              "CVE-2021-1234",
+		PluginID:         "jira",
+		AffectedVersions: []string{"<2.4.1"},
+		Severity:         SeverityModerate,
+	}
+
+	testCases := map[string]struct {
+		filter   *AdvisoryFilter
+		expected bool
+	}{
+		"empty":             {&AdvisoryFilter{}, true},
+		"plugin":            {&AdvisoryFilter{PluginID: "jira"}, true},
+		"other plugin":      {&AdvisoryFilter{PluginID: "github"}, false},
+		"affected version":  {&AdvisoryFilter{PluginID: "jira", Version: "2.4.0"}, true},
+		"fixed version":     {&AdvisoryFilter{PluginID: "jira", Version: "2.4.1"}, false},
+		"lower severity":    {&AdvisoryFilter{Severity: SeverityLow}, true},
+		"same severity":     {&AdvisoryFilter{Severity: SeverityModerate}, true},
+		"higher severity":   {&AdvisoryFilter{Severity: SeverityHigh}, false},
+		"cve ignoring case": {&AdvisoryFilter{CVE: "cve-2021-1234"}, true},
+		"other cve":         {&AdvisoryFilter{CVE: "CVE-2021-9999"}, false},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, testCase.filter.Matches(advisory))
+		})
+	}
+}
+
+func TestAdvisoriesFromReader(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		advisories, err := AdvisoriesFromReader(strings.NewReader(""))
+		require.NoError(t, err)
+		assert.Empty(t, advisories)
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		advisories, err := AdvisoriesFromReader(strings.NewReader(`[{
+			"id": "MMSA-2021-0001",
+			"cve": "CVE-2021-1234",
+			"plugin_id": "jira",
+			"affected_versions": [">=2.0.0 <2.4.1"],
+			"fixed_version": "2.4.1",
+			"severity": "critical",
+			"description": "Webhook requests are not authenticated."
+		}]`))
+		require.NoError(t, err)
+		require.Len(t, advisories, 1)
+		assert.Equal(t, "MMSA-2021-0001", advisories[0].ID)
+		assert.Equal(t, SeverityCritical, advisories[0].Severity)
+	})
+
+	testCases := map[string]string{
+		"missing id":        `[{"plugin_id": "jira", "affected_versions": ["<1.0.0"], "severity": "low"}]`,
+		"missing plugin id": `[{"id": "a", "affected_versions": ["<1.0.0"], "severity": "low"}]`,
+		"no ranges":         `[{"id": "a", "plugin_id": "jira", "severity": "low"}]`,
+		"invalid range":     `[{"id": "a", "plugin_id": "jira", "affected_versions": ["before 1.0"], "severity": "low"}]`,
+		"invalid fixed":     `[{"id": "a", "plugin_id": "jira", "affected_versions": ["<1.0.0"], "fixed_version": "next", "severity": "low"}]`,
+		"invalid severity":  `[{"id": "a", "plugin_id": "jira", "affected_versions": ["<1.0.0"], "severity": "urgent"}]`,
+		"null advisory":     `[null]`,
+		"duplicate id": `[
+			{"id": "a", "plugin_id": "jira", "affected_versions": ["<1.0.0"], "severity": "low"},
+			{"id": "a", "plugin_id": "github", "affected_versions": ["<1.0.0"], "severity": "low"}
+		]`,
+	}
+
+	for name, data := range testCases {
+		data := data
+		t.Run(name, func(t *testing.T) {
+			_, err := AdvisoriesFromReader(strings.NewReader(data))
+			require.Error(t, err)
+		})
+	}
+}
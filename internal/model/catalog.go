@@ -0,0 +1,147 @@
+package model
+
+import (
+	"sort"
+
+	"github.com/blang/semver"
+)
+
+// PluginVersion identifies a single version of a plugin.
+type PluginVersion struct {
+	PluginID string `json:"plugin_id"`
+	Version  string `json:"version"`
+}
+
+// LabelChange describes the labels added to and removed from a single version of a plugin.
+type LabelChange struct {
+	PluginVersion
+	Added   []string `json:"added"`   // The names of the labels added
+	Removed []string `json:"removed"` // The names of the labels removed
+}
+
+// CatalogChange describes the differences between two snapshots of a catalog of plugins.
+type CatalogChange struct {
+	Added        []*Plugin        `json:"added"`
+	Removed      []*PluginVersion `json:"removed"`
+	LabelChanges []*LabelChange   `json:"label_changes"`
+	Yanked       []*Plugin        `json:"yanked"` // Versions withdrawn since the previous snapshot
+}
+
+// IsEmpty reports whether the change describes no differences at all.
+func (c *CatalogChange) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.LabelChanges) == 0 && len(c.Yanked) == 0
+}
+
+// DiffCatalogs describes the versions added to, removed from, relabelled in and yanked from the
+// current catalog relative to the previous catalog. Changes are ordered by plugin id then version.
+//
+// Versions are identified by plugin id and version. If a version appears more than once in a
+// catalog, the last occurrence is compared.
+func DiffCatalogs(previous, current []*Plugin) *CatalogChange {
+	previousVersions := indexCatalog(previous)
+	currentVersions := indexCatalog(current)
+
+	change := &CatalogChange{
+		Added:        []*Plugin{},
+		Removed:      []*PluginVersion{},
+		LabelChanges: []*LabelChange{},
+		Yanked:       []*Plugin{},
+	}
+
+	for _, key := range sortedVersions(currentVersions) {
+		plugin := currentVersions[key]
+		previousPlugin, ok := previousVersions[key]
+		if !ok {
+			change.Added = append(change.Added, plugin)
+			continue
+		}
+
+		added, removed := diffLabels(previousPlugin.Labels, plugin.Labels)
+		if len(added) > 0 || len(removed) > 0 {
+			change.LabelChanges = append(change.LabelChanges, &LabelChange{
+				PluginVersion: key,
+				Added:         added,
+				Removed:       removed,
+			})
+		}
+
+		if plugin.IsYanked() && !previousPlugin.IsYanked() {
+			change.Yanked = append(change.Yanked, plugin)
+		}
+	}
+
+	for _, key := range sortedVersions(previousVersions) {
+		if _, ok := currentVersions[key]; !ok {
+			removed := key
+			change.Removed = append(change.Removed, &removed)
+		}
+	}
+
+	return change
+}
+
+// indexCatalog keys the given plugins by plugin id and version.
+func indexCatalog(plugins []*Plugin) map[PluginVersion]*Plugin {
+	result := make(map[PluginVersion]*Plugin, len(plugins))
+	for _, plugin := range plugins {
+		if plugin.Manifest == nil {
+			continue
+		}
+
+		result[PluginVersion{PluginID: plugin.Manifest.Id, Version: plugin.Manifest.Version}] = plugin
+	}
+
+	return result
+}
+
+// sortedVersions returns the keys of the given index ordered by plugin id then version.
+func sortedVersions(index map[PluginVersion]*Plugin) []PluginVersion {
+	keys := make([]PluginVersion, 0, len(index))
+	for key := range index {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].PluginID != keys[j].PluginID {
+			return keys[i].PluginID < keys[j].PluginID
+		}
+
+		vi, errI := semver.Parse(keys[i].Version)
+		vj, errJ := semver.Parse(keys[j].Version)
+		if errI != nil || errJ != nil {
+			return keys[i].Version < keys[j].Version
+		}
+		return vi.LT(vj)
+	})
+
+	return keys
+}
+
+// diffLabels returns the names of the labels added and removed between the given sets of labels.
+func diffLabels(previous, current []Label) (added, removed []string) {
+	added, removed = []string{}, []string{}
+
+	previousNames := make(map[string]bool, len(previous))
+	for _, label := range previous {
+		previousNames[label.Name] = true
+	}
+
+	currentNames := make(map[string]bool, len(current))
+	for _, label := range current {
+		currentNames[label.Name] = true
+		if !previousNames[label.Name] {
+			added = append(added, label.Name)
+		}
+	}
+
+	for _, label := range previous {
+		if !currentNames[label.Name] {
+			removed = append(removed, label.Name)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	return added, removed
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"testing"
+
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffCatalogs(t *testing.T) {
+	newPlugin := func(id, version string, labels ...Label) *Plugin {
+		return &Plugin{
+			Labels: labels,
+			Manifest: &mattermostModel.Manifest{
+				Id:      id,
+				Version: version,
+			},
+		}
+	}
+
+	t.Run("no changes", func(t *testing.T) {
+		catalog := []*Plugin{newPlugin("demo", "1.0.0", BetaLabel)}
+		change := DiffCatalogs(catalog, []*Plugin{newPlugin("demo", "1.0.0", BetaLabel)})
+		assert.True(t, change.IsEmpty())
+	})
+
+	t.Run("added, removed and relabelled versions", func(t *testing.T) {
+		demoV2 := newPlugin("demo", "2.0.0")
+		demoV10 := newPlugin("demo", "10.0.0")
+
+		change := DiffCatalogs(
+			[]*Plugin{
+				newPlugin("demo", "1.0.0", BetaLabel),
+				newPlugin("old", "1.0.0"),
+				newPlugin("other", "1.0.0", CommunityLabel),
+			},
+			[]*Plugin{
+				newPlugin("other", "1.0.0", EnterpriseLabel),
+				demoV10,
+				newPlugin("demo", "1.0.0"),
+				demoV2,
+			},
+		)
+
+		assert.False(t, change.IsEmpty())
+		assert.Equal(t, []*Plugin{demoV2, demoV10}, change.Added)
+		assert.Equal(t, []*PluginVersion{{PluginID: "old", Version: "1.0.0"}}, change.Removed)
+		assert.Equal(t, []*LabelChange{
+			{
+				PluginVersion: PluginVersion{PluginID: "demo", Version: "1.0.0"},
+				Added:         []string{},
+				Removed:       []string{"Beta"},
+			},
+			{
+				PluginVersion: PluginVersion{PluginID: "other", Version: "1.0.0"},
+				Added:         []string{EnterpriseLabel.Name},
+				Removed:       []string{"Community"},
+			},
+		}, change.LabelChanges)
+	})
+
+	t.Run("repeated versions compare the last occurrence", func(t *testing.T) {
+		change := DiffCatalogs(
+			[]*Plugin{newPlugin("demo", "1.0.0")},
+			[]*Plugin{newPlugin("demo", "1.0.0"), newPlugin("demo", "1.0.0", BetaLabel)},
+		)
+		assert.Empty(t, change.Added)
+		assert.Len(t, change.LabelChanges, 1)
+	})
+
+	t.Run("yanked versions", func(t *testing.T) {
+		yanked := newPlugin("demo", "1.0.0")
+		yanked.Yanked = &Yank{Reason: "broken"}
+
+		change := DiffCatalogs([]*Plugin{newPlugin("demo", "1.0.0")}, []*Plugin{yanked})
+		assert.False(t, change.IsEmpty())
+		assert.Equal(t, []*Plugin{yanked}, change.Yanked)
+		assert.Empty(t, change.Removed)
+
+		change = DiffCatalogs([]*Plugin{yanked}, []*Plugin{yanked})
+		assert.True(t, change.IsEmpty())
+	})
+}
@@ -0,0 +1,58 @@
+package model
+
+// CompatibilityRule identifies the rule by which a version of a plugin was included in, or
+// excluded from, the plugins matching a filter.
+type CompatibilityRule string
+
+const (
+	// RuleCompatible includes a version satisfying every compatibility check.
+	RuleCompatible CompatibilityRule = "compatible"
+	// RuleEnterpriseLegacyServer includes an enterprise version despite enterprise plugins not
+	// being requested, since servers older than 5.25.0 never request them. See MM-26507.
+	RuleEnterpriseLegacyServer CompatibilityRule = "enterprise_legacy_server"
+	// RuleEnterprise excludes an enterprise version when enterprise plugins are not requested.
+	RuleEnterprise CompatibilityRule = "enterprise"
+	// RuleOnPremOnly excludes a version restricted to on-prem hosting from cloud servers.
+	RuleOnPremOnly CompatibilityRule = "on_prem_only"
+	// RuleCloudOnly excludes a version restricted to cloud hosting from on-prem servers.
+	RuleCloudOnly CompatibilityRule = "cloud_only"
+	// RuleMinServerVersion excludes a version requiring a newer server.
+	RuleMinServerVersion CompatibilityRule = "min_server_version"
+	// RuleSuperseded excludes a compatible version in favour of a newer compatible version, unless
+	// all versions are requested.
+	RuleSuperseded CompatibilityRule = "superseded"
+	// RulePinned excludes a compatible version in favour of the version to which the plugin is
+	// pinned, unless all versions are requested.
+	RulePinned CompatibilityRule = "pinned"
+	// RuleYanked excludes a version that has been withdrawn, unless all versions are requested
+	// including those yanked.
+	RuleYanked CompatibilityRule = "yanked"
+	// RuleFiltered excludes a version not matching the author type, release stage, hosting or
+	// label constraints of the filter.
+	RuleFiltered CompatibilityRule = "filtered"
+)
+
+// Includes reports whether the rule includes the version to which it applies.
+func (r CompatibilityRule) Includes() bool {
+	return r == RuleCompatible || r == RuleEnterpriseLegacyServer
+}
+
+// VersionCompatibility explains whether a single version of a plugin matches a filter.
+type VersionCompatibility struct {
+	Version    string            `json:"version"`
+	Compatible bool              `json:"compatible"` // Whether the version is returned for the filter
+	Rule       CompatibilityRule `json:"rule"`       // The rule that included or excluded the version
+	Reason     string            `json:"reason"`     // A human readable explanation of the rule
+
+	// PlatformBundle is whether a bundle specific to the requested platform would be served,
+	// rather than the platform-independent bundle.
+	PlatformBundle bool `json:"platform_bundle"`
+
+	Plugin *Plugin `json:"plugin"`
+}
+
+// PluginCompatibility explains which versions of a plugin match a filter, and why.
+type PluginCompatibility struct {
+	PluginID string                  `json:"plugin_id"`
+	Versions []*VersionCompatibility `json:"versions"` // Ordered by version descending
+}
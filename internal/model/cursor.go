@@ -0,0 +1,57 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PluginCursor identifies a position within a sorted list of plugins.
+//
+// Paging relative to the last plugin seen rather than by offset keeps the remaining pages stable
+// even if plugins are added or removed between requests. Clients should treat the encoded cursor
+// as opaque.
+type PluginCursor struct {
+	Sort      PluginSort    `json:"s"`
+	Direction SortDirection `json:"d"`
+
+	Name      string    `json:"n"`
+	ID        string    `json:"i"`
+	Version   string    `json:"v"`
+	UpdatedAt time.Time `json:"u"`
+	Score     float64   `json:"r,omitempty"`
+}
+
+// Encode returns the opaque string representation of the cursor.
+func (c *PluginCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Matches reports whether the cursor was issued for a list sorted the same way as the given filter.
+func (c *PluginCursor) Matches(filter *PluginFilter) bool {
+	sort, direction := filter.SortOrder()
+	return c.Sort == sort && c.Direction == direction
+}
+
+// DecodePluginCursor parses a cursor previously returned by Encode.
+func DecodePluginCursor(encoded string) (*PluginCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode cursor")
+	}
+
+	var cursor PluginCursor
+	err = json.Unmarshal(data, &cursor)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse cursor")
+	}
+
+	if cursor.ID == "" || cursor.Version == "" {
+		return nil, errors.New("cursor is missing a plugin id or version")
+	}
+
+	return &cursor, nil
+}
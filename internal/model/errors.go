@@ -0,0 +1,9 @@
+package model
+
+import "github.com/pkg/errors"
+
+// ErrPluginExists is returned when adding a version of a plugin already in the catalog.
+var ErrPluginExists = errors.New("plugin version already exists")
+
+// ErrPluginNotFound is returned when changing a version of a plugin not in the catalog.
+var ErrPluginNotFound = errors.New("plugin version not found")
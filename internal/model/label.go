@@ -1,13 +1,26 @@
 package model
 
+import (
+	"encoding/json"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
 // Label represents a label shown in the Plugin Marketplace UI.
 type Label struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	URL         string `json:"url"`
 	Color       string `json:"color"`
+
+	// Localizations holds translations of the name and description, keyed by locale.
+	Localizations Localizations `json:"localizations,omitempty"`
 }
 
+// AllLabels are the labels assigned by default, as defined by DefaultLabelDefinitions.
 var AllLabels = []Label{
 	PartnerLabel,
 	CommunityLabel,
@@ -44,3 +57,191 @@ var EnterpriseLabel Label = Label{
 	Description: "This plugin requires a Professional or Enterprise subscription.",
 	URL:         "https://mattermost.com/pricing/",
 }
+
+// LabelCondition matches plugins satisfying every one of its constraints. Each constraint matches
+// any one of its values, and an empty constraint matches every plugin.
+type LabelCondition struct {
+	AuthorTypes   []AuthorType   `json:"author_type,omitempty"`
+	ReleaseStages []ReleaseStage `json:"release_stage,omitempty"`
+	Hosting       []HostingType  `json:"hosting,omitempty"` // Matches the hosting to which the plugin is restricted
+	Enterprise    *bool          `json:"enterprise,omitempty"`
+	PluginIDs     []string       `json:"plugin_id,omitempty"` // Patterns using the syntax of path.Match
+}
+
+// Matches reports whether the given plugin satisfies the condition.
+func (c *LabelCondition) Matches(p *Plugin) bool {
+	if len(c.AuthorTypes) > 0 {
+		matched := false
+		for _, authorType := range c.AuthorTypes {
+			matched = matched || authorType == p.AuthorType
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(c.ReleaseStages) > 0 {
+		matched := false
+		for _, releaseStage := range c.ReleaseStages {
+			matched = matched || releaseStage == p.ReleaseStage
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(c.Hosting) > 0 {
+		matched := false
+		for _, hosting := range c.Hosting {
+			matched = matched || hosting == p.Hosting
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if c.Enterprise != nil && *c.Enterprise != p.Enterprise {
+		return false
+	}
+
+	if len(c.PluginIDs) > 0 {
+		if p.Manifest == nil {
+			return false
+		}
+
+		matched := false
+		for _, pattern := range c.PluginIDs {
+			ok, _ := path.Match(pattern, p.Manifest.Id)
+			matched = matched || ok
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validate checks that every value of the condition is supported.
+func (c *LabelCondition) validate() error {
+	for _, authorType := range c.AuthorTypes {
+		if !authorType.IsValid() {
+			return errors.Errorf("unsupported author type %s", authorType)
+		}
+	}
+
+	for _, releaseStage := range c.ReleaseStages {
+		if !releaseStage.IsValid() {
+			return errors.Errorf("unsupported release stage %s", releaseStage)
+		}
+	}
+
+	for _, hosting := range c.Hosting {
+		if !hosting.IsValid() {
+			return errors.Errorf("unsupported hosting %s", hosting)
+		}
+	}
+
+	for _, pattern := range c.PluginIDs {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.Wrapf(err, "invalid plugin id pattern %s", pattern)
+		}
+	}
+
+	return nil
+}
+
+// LabelDefinition is a label alongside the conditions under which it is assigned to a plugin.
+type LabelDefinition struct {
+	Label
+
+	// When lists the conditions under which the label is assigned, if any one matches. A label
+	// without conditions is only carried by plugins listing it explicitly.
+	When []*LabelCondition `json:"when"`
+}
+
+// Matches reports whether the label is assigned to the given plugin.
+func (d *LabelDefinition) Matches(p *Plugin) bool {
+	for _, condition := range d.When {
+		if condition.Matches(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newBool returns a pointer to the given value.
+func newBool(value bool) *bool {
+	return &value
+}
+
+// DefaultLabelDefinitions assigns the default labels by the plugin's author, release stage and
+// enterprise flag.
+var DefaultLabelDefinitions = []*LabelDefinition{
+	{Label: PartnerLabel, When: []*LabelCondition{{AuthorTypes: []AuthorType{Partner}}}},
+	{Label: CommunityLabel, When: []*LabelCondition{{AuthorTypes: []AuthorType{Community}}}},
+	{Label: BetaLabel, When: []*LabelCondition{{ReleaseStages: []ReleaseStage{Beta}}}},
+	{Label: ExperimentalLabel, When: []*LabelCondition{{ReleaseStages: []ReleaseStage{Experimental}}}},
+	{Label: EnterpriseLabel, When: []*LabelCondition{{Enterprise: newBool(true)}}},
+}
+
+// DefinedLabels returns the labels of the given definitions, without their conditions.
+func DefinedLabels(definitions []*LabelDefinition) []Label {
+	labels := make([]Label, 0, len(definitions))
+	for _, definition := range definitions {
+		labels = append(labels, definition.Label)
+	}
+
+	return labels
+}
+
+// labelsConfig is the encoding of a set of label definitions.
+type labelsConfig struct {
+	Labels []*LabelDefinition `json:"labels"`
+}
+
+// LabelDefinitionsFromReader reads and validates a set of label definitions, encoded as a JSON
+// object listing them as labels.
+func LabelDefinitionsFromReader(reader io.Reader) ([]*LabelDefinition, error) {
+	var config labelsConfig
+	err := json.NewDecoder(reader).Decode(&config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse labels")
+	}
+
+	seen := make(map[string]bool)
+	for _, definition := range config.Labels {
+		if definition == nil || definition.Name == "" {
+			return nil, errors.New("every label must have a name")
+		}
+
+		name := strings.ToLower(definition.Name)
+		if seen[name] {
+			return nil, errors.Errorf("duplicate label %s", definition.Name)
+		}
+		seen[name] = true
+
+		err = definition.Localizations.validate()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid localizations for label %s", definition.Name)
+		}
+
+		for _, condition := range definition.When {
+			if condition == nil {
+				return nil, errors.Errorf("label %s has an empty condition", definition.Name)
+			}
+
+			err = condition.validate()
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid condition for label %s", definition.Name)
+			}
+		}
+	}
+
+	if config.Labels == nil {
+		config.Labels = []*LabelDefinition{}
+	}
+
+	return config.Labels, nil
+}
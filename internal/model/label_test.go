@@ -0,0 +1,95 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelDefinitions(t *testing.T) {
+	labelNames := func(plugin *Plugin) []string {
+		names := []string{}
+		for _, label := range plugin.Labels {
+			names = append(names, label.Name)
+		}
+
+		return names
+	}
+
+	t.Run("defaults match the default labels", func(t *testing.T) {
+		assert.Equal(t, AllLabels, DefinedLabels(DefaultLabelDefinitions))
+
+		plugin := &Plugin{AuthorType: Partner, ReleaseStage: Beta, Enterprise: true}
+		plugin.AddLabels(DefaultLabelDefinitions)
+		assert.Equal(t, []string{PartnerLabel.Name, BetaLabel.Name, EnterpriseLabel.Name}, labelNames(plugin))
+
+		plugin = &Plugin{AuthorType: Mattermost, ReleaseStage: Production}
+		plugin.AddLabels(DefaultLabelDefinitions)
+		assert.Empty(t, plugin.Labels)
+	})
+
+	t.Run("configured labels", func(t *testing.T) {
+		definitions, err := LabelDefinitionsFromReader(strings.NewReader(`{
+			"labels": [
+				{
+					"name": "Approved by IT",
+					"color": "#3db887",
+					"url": "https://intranet.example.com/approved",
+					"when": [
+						{"author_type": ["partner"], "hosting": ["cloud"]},
+						{"plugin_id": ["com.example.*"]}
+					]
+				},
+				{"name": "Internal", "when": [{"enterprise": false, "release_stage": ["beta", "experimental"]}]},
+				{"name": "Featured"}
+			]
+		}`))
+		require.NoError(t, err)
+		require.Len(t, definitions, 3)
+
+		assert.Equal(t, []Label{
+			{Name: "Approved by IT", Color: "#3db887", URL: "https://intranet.example.com/approved"},
+			{Name: "Internal"},
+			{Name: "Featured"},
+		}, DefinedLabels(definitions))
+
+		testCases := []struct {
+			Description string
+			Plugin      *Plugin
+			Expected    []string
+		}{
+			{"partner cloud plugin", &Plugin{AuthorType: Partner, Hosting: Cloud}, []string{"Approved by IT"}},
+			{"partner plugin without hosting", &Plugin{AuthorType: Partner}, []string{}},
+			{"matching plugin id", &Plugin{Manifest: &mattermostModel.Manifest{Id: "com.example.demo"}}, []string{"Approved by IT"}},
+			{"beta plugin", &Plugin{ReleaseStage: Beta}, []string{"Internal"}},
+			{"enterprise beta plugin", &Plugin{ReleaseStage: Beta, Enterprise: true}, []string{}},
+			{"explicit label", &Plugin{Labels: []Label{{Name: "Featured"}}}, []string{"Featured"}},
+		}
+
+		for _, testCase := range testCases {
+			testCase := testCase
+			t.Run(testCase.Description, func(t *testing.T) {
+				testCase.Plugin.AddLabels(definitions)
+				assert.Equal(t, testCase.Expected, labelNames(testCase.Plugin))
+			})
+		}
+	})
+
+	t.Run("invalid definitions", func(t *testing.T) {
+		for _, config := range []string{
+			`not json`,
+			`{"labels": [{"description": "unnamed"}]}`,
+			`{"labels": [{"name": "Twice"}, {"name": "twice"}]}`,
+			`{"labels": [{"name": "Bad", "when": [{"author_type": ["unknown"]}]}]}`,
+			`{"labels": [{"name": "Bad", "when": [{"hosting": ["mainframe"]}]}]}`,
+			`{"labels": [{"name": "Bad", "when": [{"plugin_id": ["["]}]}]}`,
+			`{"labels": [{"name": "Bad", "when": [null]}]}`,
+		} {
+			_, err := LabelDefinitionsFromReader(strings.NewReader(config))
+			assert.Error(t, err, config)
+		}
+	})
+}
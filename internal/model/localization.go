@@ -0,0 +1,164 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultLocale is the locale of the text of plugins and labels, used whenever no localization
+// matches the locales preferred by a caller.
+const DefaultLocale = "en"
+
+// localePattern matches the language tags accepted as locales, such as "de", "ja" or "pt-BR".
+var localePattern = regexp.MustCompile(`^[a-zA-Z]{2,8}([-_][a-zA-Z0-9]{1,8})*$`)
+
+// Localization holds the text of a plugin or label translated into a single locale. Empty fields
+// fall back to the text in the default locale.
+type Localization struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Localizations holds the translations of a plugin or label keyed by locale.
+type Localizations map[string]*Localization
+
+// NormalizeLocale returns the canonical form of the given locale, lowercase and separated by
+// hyphens, or an empty string if the locale is malformed.
+func NormalizeLocale(locale string) string {
+	locale = strings.TrimSpace(locale)
+	if !localePattern.MatchString(locale) {
+		return ""
+	}
+
+	return strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+}
+
+// Lookup returns the localization best matching the given locales, most preferred first, or nil if
+// the text in the default locale should be used.
+//
+// Each locale matches a localization for the same locale, with any fields it leaves empty taken
+// from the localization for its language alone, such that "de-AT" falls back to "de". Locales
+// preferred after the default locale are ignored.
+func (l Localizations) Lookup(locales []string) *Localization {
+	if len(l) == 0 {
+		return nil
+	}
+
+	for _, locale := range locales {
+		var result *Localization
+		for candidate := NormalizeLocale(locale); candidate != ""; candidate = parentLocale(candidate) {
+			if candidate == DefaultLocale {
+				return result
+			}
+
+			localization := l.find(candidate)
+			if localization == nil {
+				continue
+			}
+
+			if result == nil {
+				result = &Localization{}
+			}
+			if result.Name == "" {
+				result.Name = localization.Name
+			}
+			if result.Description == "" {
+				result.Description = localization.Description
+			}
+		}
+
+		if result != nil {
+			return result
+		}
+	}
+
+	return nil
+}
+
+// find returns the localization for exactly the given normalized locale, if any.
+func (l Localizations) find(locale string) *Localization {
+	for key, localization := range l {
+		if localization != nil && NormalizeLocale(key) == locale {
+			return localization
+		}
+	}
+
+	return nil
+}
+
+// validate checks that every localization is keyed by a well formed locale.
+func (l Localizations) validate() error {
+	for locale, localization := range l {
+		if NormalizeLocale(locale) == "" {
+			return errors.Errorf("invalid locale %q", locale)
+		}
+		if localization == nil {
+			return errors.Errorf("empty localization for locale %s", locale)
+		}
+	}
+
+	return nil
+}
+
+// parentLocale returns the given normalized locale without its last subtag, or an empty string
+// for a bare language.
+func parentLocale(locale string) string {
+	i := strings.LastIndex(locale, "-")
+	if i < 0 {
+		return ""
+	}
+
+	return locale[:i]
+}
+
+// Localize returns a copy of the label with its name and description translated into the best
+// matching of the given locales, most preferred first.
+func (l Label) Localize(locales []string) Label {
+	localization := l.Localizations.Lookup(locales)
+	if localization == nil {
+		return l
+	}
+
+	if localization.Name != "" {
+		l.Name = localization.Name
+	}
+	if localization.Description != "" {
+		l.Description = localization.Description
+	}
+
+	return l
+}
+
+// Localize returns a copy of the plugin with the name and description of its manifest, and those
+// of its labels, translated into the best matching of the given locales, most preferred first.
+//
+// The plugin itself is returned if no locales are given.
+func (p *Plugin) Localize(locales []string) *Plugin {
+	if len(locales) == 0 {
+		return p
+	}
+
+	localized := *p
+
+	if localization := p.Localizations.Lookup(locales); localization != nil && p.Manifest != nil {
+		manifest := *p.Manifest
+		if localization.Name != "" {
+			manifest.Name = localization.Name
+		}
+		if localization.Description != "" {
+			manifest.Description = localization.Description
+		}
+		localized.Manifest = &manifest
+	}
+
+	if len(p.Labels) > 0 {
+		localized.Labels = make([]Label, 0, len(p.Labels))
+		for _, label := range p.Labels {
+			localized.Labels = append(localized.Labels, label.Localize(locales))
+		}
+	}
+
+	return &localized
+}
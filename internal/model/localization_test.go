@@ -0,0 +1,106 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalizationsLookup(t *testing.T) {
+	localizations := Localizations{
+		"de":    {Name: "Abstimmungen", Description: "Umfragen erstellen."},
+		"de_CH": {Description: "Umfragen erstellen, auf Schweizerdeutsch."},
+		"pt-BR": {Name: "Enquetes"},
+	}
+
+	testCases := map[string]struct {
+		locales  []string
+		expected *Localization
+	}{
+		"none":              {nil, nil},
+		"exact":             {[]string{"de"}, &Localization{Name: "Abstimmungen", Description: "Umfragen erstellen."}},
+		"language":          {[]string{"de-AT"}, &Localization{Name: "Abstimmungen", Description: "Umfragen erstellen."}},
+		"fields fall back":  {[]string{"DE-ch"}, &Localization{Name: "Abstimmungen", Description: "Umfragen erstellen, auf Schweizerdeutsch."}},
+		"region only":       {[]string{"pt"}, nil},
+		"next preference":   {[]string{"fr", "pt-BR"}, &Localization{Name: "Enquetes"}},
+		"default preferred": {[]string{"en-US", "de"}, nil},
+		"malformed":         {[]string{"*", "de"}, &Localization{Name: "Abstimmungen", Description: "Umfragen erstellen."}},
+		"nothing matches":   {[]string{"ja"}, nil},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, localizations.Lookup(testCase.locales))
+		})
+	}
+
+	t.Run("no localizations", func(t *testing.T) {
+		assert.Nil(t, Localizations(nil).Lookup([]string{"de"}))
+	})
+}
+
+func TestPluginLocalize(t *testing.T) {
+	plugin := &Plugin{
+		Manifest: &mattermostModel.Manifest{
+			Id:          "matterpoll",
+			Name:        "Matterpoll",
+			Description: "Create polls and surveys.",
+			Version:     "1.3.0",
+		},
+		Labels: []Label{{
+			Name:          "Community",
+			Description:   "This plugin is maintained by the Open Source Community.",
+			Localizations: Localizations{"ja": {Name: "コミュニティ"}},
+		}},
+		Localizations: Localizations{
+			"ja": {Name: "投票"},
+		},
+	}
+
+	t.Run("no locales", func(t *testing.T) {
+		assert.Same(t, plugin, plugin.Localize(nil))
+	})
+
+	t.Run("translated copy", func(t *testing.T) {
+		localized := plugin.Localize([]string{"ja-JP"})
+		assert.Equal(t, "投票", localized.Manifest.Name)
+		assert.Equal(t, "Create polls and surveys.", localized.Manifest.Description)
+		assert.Equal(t, "コミュニティ", localized.Labels[0].Name)
+		assert.Equal(t, "This plugin is maintained by the Open Source Community.", localized.Labels[0].Description)
+
+		assert.Equal(t, "Matterpoll", plugin.Manifest.Name)
+		assert.Equal(t, "Community", plugin.Labels[0].Name)
+	})
+
+	t.Run("default locale", func(t *testing.T) {
+		localized := plugin.Localize([]string{"en"})
+		assert.Equal(t, plugin, localized)
+	})
+}
+
+func TestLocalizationsValidate(t *testing.T) {
+	plugin := &Plugin{
+		Manifest: &mattermostModel.Manifest{
+			Id:      "matterpoll",
+			Name:    "Matterpoll",
+			Version: "1.3.0",
+		},
+		Localizations: Localizations{"de": {Name: "Abstimmungen"}},
+	}
+	require.NoError(t, plugin.Validate())
+
+	plugin.Localizations = Localizations{"not a locale": {Name: "Abstimmungen"}}
+	require.Error(t, plugin.Validate())
+
+	plugin.Localizations = Localizations{"de": nil}
+	require.Error(t, plugin.Validate())
+
+	_, err := LabelDefinitionsFromReader(strings.NewReader(`{
+		"labels": [{"name": "Beta", "localizations": {"de!": {"name": "Beta"}}}]
+	}`))
+	require.Error(t, err)
+}
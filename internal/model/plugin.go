@@ -2,10 +2,13 @@ package model
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
 )
 
 type HostingType string
@@ -15,6 +18,11 @@ const (
 	Cloud  HostingType = "cloud"
 )
 
+// IsValid reports whether the hosting type is one of the known values.
+func (h HostingType) IsValid() bool {
+	return h == OnPrem || h == Cloud
+}
+
 type AuthorType string
 
 const (
@@ -23,6 +31,11 @@ const (
 	Community  AuthorType = "community"
 )
 
+// IsValid reports whether the author type is one of the known values.
+func (a AuthorType) IsValid() bool {
+	return a == Mattermost || a == Partner || a == Community
+}
+
 type ReleaseStage string
 
 const (
@@ -31,6 +44,11 @@ const (
 	Experimental ReleaseStage = "experimental"
 )
 
+// IsValid reports whether the release stage is one of the known values.
+func (r ReleaseStage) IsValid() bool {
+	return r == Production || r == Beta || r == Experimental
+}
+
 // Plugin represents a Mattermost plugin in the Plugin Marketplace.
 type Plugin struct {
 	HomepageURL     string                    `json:"homepage_url"`
@@ -44,9 +62,22 @@ type Plugin struct {
 	Enterprise      bool                      `json:"enterprise"`    // Indicated if the plugin is an enterprise plugin
 	Signature       string                    `json:"signature"`     // A signature of a plugin saved in base64 encoding.
 	RepoName        string                    `json:"repo_name"`
+	Keywords        []string                  `json:"keywords,omitempty"` // Additional terms by which the plugin may be found when searching
 	Manifest        *mattermostModel.Manifest `json:"manifest"`
 	Platforms       PlatformBundles           `json:"platforms"`
-	UpdatedAt       time.Time                 `json:"updated_at"` // The point in time this release of the plugin was added to the Plugin Marketplace
+	UpdatedAt       time.Time                 `json:"updated_at"`               // The point in time this release of the plugin was added to the Plugin Marketplace
+	DownloadCount   *int64                    `json:"download_count,omitempty"` // The number of downloads of all versions through the marketplace, if known
+	Localizations   Localizations             `json:"localizations,omitempty"`  // Translations of the name and description of the manifest, keyed by locale
+	Yanked          *Yank                     `json:"yanked,omitempty"`         // Set if the release has been withdrawn from the Plugin Marketplace
+	Warning         string                    `json:"warning,omitempty"`        // Warns of a yanked release served on explicit request, set when serving the plugin
+	Advisories      []*Advisory               `json:"advisories,omitempty"`     // The security advisories affecting this release, set when serving the plugin
+}
+
+// Yank describes the withdrawal of a release, such as for a security issue. A yanked release is
+// never selected as the latest version of a plugin, but may still be requested explicitly.
+type Yank struct {
+	Reason   string    `json:"reason"`
+	YankedAt time.Time `json:"yanked_at"`
 }
 
 // PlatformBundleMetadata holds the necessary data to fetch and verify a plugin built for a specific platform
@@ -106,26 +137,153 @@ func PluginsToWriter(w io.Writer, plugins []*Plugin) error {
 	return nil
 }
 
-func (p *Plugin) AddLabels() {
-	if p.AuthorType == Partner {
-		p.Labels = append(p.Labels, PartnerLabel)
+// Validate checks that the plugin has a valid manifest specifying a version, as required of every
+// plugin in a catalog.
+func (p *Plugin) Validate() error {
+	if p.Manifest == nil {
+		return errors.New("missing manifest")
+	}
+
+	err := p.Manifest.IsValid()
+	if err != nil {
+		return errors.Wrapf(err, "invalid manifest for plugin %s", p.Manifest.Id)
+	}
+
+	if p.Manifest.Version == "" {
+		return errors.Errorf("missing version in manifest for plugin %s", p.Manifest.Id)
+	}
+
+	err = p.Localizations.validate()
+	if err != nil {
+		return errors.Wrapf(err, "invalid localizations for plugin %s", p.Manifest.Id)
+	}
+
+	return nil
+}
+
+// AddLabels adds the labels whose given definitions match the plugin, such as by its author,
+// release stage and enterprise flag. Labels the plugin already carries are not added again.
+func (p *Plugin) AddLabels(definitions []*LabelDefinition) {
+	for _, definition := range definitions {
+		if definition.Matches(p) {
+			p.addLabel(definition.Label)
+		}
 	}
+}
 
-	if p.AuthorType == Community {
-		p.Labels = append(p.Labels, CommunityLabel)
+func (p *Plugin) addLabel(label Label) {
+	if p.HasLabel(label.Name) {
+		return
 	}
 
-	if p.ReleaseStage == Beta {
-		p.Labels = append(p.Labels, BetaLabel)
+	// Copy the labels to avoid modifying a slice shared with another copy of the plugin.
+	labels := make([]Label, 0, len(p.Labels)+1)
+	labels = append(labels, p.Labels...)
+	p.Labels = append(labels, label)
+}
+
+// HasLabel reports whether the plugin carries a label with the given name, ignoring case.
+func (p *Plugin) HasLabel(name string) bool {
+	for _, label := range p.Labels {
+		if strings.EqualFold(label.Name, name) {
+			return true
+		}
 	}
 
-	if p.ReleaseStage == Experimental {
-		p.Labels = append(p.Labels, ExperimentalLabel)
+	return false
+}
+
+// IsYanked reports whether the release has been withdrawn.
+func (p *Plugin) IsYanked() bool {
+	return p.Yanked != nil
+}
+
+// YankWarning describes the withdrawal of the release, or returns an empty string if the release
+// has not been withdrawn.
+func (p *Plugin) YankWarning() string {
+	if p.Yanked == nil || p.Manifest == nil {
+		return ""
 	}
 
-	if p.Enterprise {
-		p.Labels = append(p.Labels, EnterpriseLabel)
+	warning := fmt.Sprintf("version %s of plugin %s was yanked", p.Manifest.Version, p.Manifest.Id)
+	if !p.Yanked.YankedAt.IsZero() {
+		warning += " on " + p.Yanked.YankedAt.UTC().Format("2006-01-02")
+	}
+	if p.Yanked.Reason != "" {
+		warning += ": " + p.Yanked.Reason
 	}
+
+	return warning
+}
+
+// AvailablePlatforms returns the platforms for which the plugin provides a specific bundle.
+func (p *Plugin) AvailablePlatforms() []string {
+	var platforms []string
+	if p.Platforms.LinuxAmd64.DownloadURL != "" {
+		platforms = append(platforms, LinuxAmd64)
+	}
+	if p.Platforms.DarwinAmd64.DownloadURL != "" {
+		platforms = append(platforms, DarwinAmd64)
+	}
+	if p.Platforms.WindowsAmd64.DownloadURL != "" {
+		platforms = append(platforms, WindowsAmd64)
+	}
+
+	return platforms
+}
+
+// IsAvailableFor reports whether the plugin may be installed with the given hosting type.
+func (p *Plugin) IsAvailableFor(hosting HostingType) bool {
+	return p.Hosting == "" || p.Hosting == hosting
+}
+
+// PluginSort is a field by which a list of plugins may be sorted.
+type PluginSort string
+
+const (
+	SortByName      PluginSort = "name"
+	SortByUpdatedAt PluginSort = "updated_at"
+	SortByID        PluginSort = "id"
+	SortByRelevance PluginSort = "relevance" // How closely the plugin matches PluginFilter.Filter
+)
+
+// SortDirection is the direction in which a list of plugins is sorted.
+type SortDirection string
+
+const (
+	SortAscending  SortDirection = "asc"
+	SortDescending SortDirection = "desc"
+)
+
+// IsValid reports whether the sort is one of the supported fields, treating empty as the default.
+func (s PluginSort) IsValid() bool {
+	switch s {
+	case "", SortByName, SortByUpdatedAt, SortByID, SortByRelevance:
+		return true
+	}
+
+	return false
+}
+
+// DefaultDirection returns the direction used when sorting by this field without specifying one:
+// descending for recency and relevance, and ascending otherwise.
+func (s PluginSort) DefaultDirection() SortDirection {
+	switch s {
+	case SortByUpdatedAt, SortByRelevance:
+		return SortDescending
+	}
+
+	return SortAscending
+}
+
+// IsValid reports whether the direction is supported, treating empty as the default.
+func (d SortDirection) IsValid() bool {
+	switch d {
+	case "", SortAscending, SortDescending:
+		return true
+	}
+
+	return false
 }
 
 // PluginFilter describes the parameters used to constrain a set of plugins.
@@ -138,5 +296,81 @@ type PluginFilter struct {
 	Cloud             bool
 	Platform          string
 	ReturnAllVersions bool
+	IncludeYanked     bool // Whether to include yanked versions, only honoured when returning all versions
 	PluginID          string
+	Cursor            string // An encoded PluginCursor. If set, Page is ignored.
+	Sort              PluginSort
+	SortDirection     SortDirection
+
+	// The following facets constrain the plugins returned to those matching at least one of the
+	// given values. An empty facet places no constraint.
+	AuthorTypes   []AuthorType
+	ReleaseStages []ReleaseStage
+	Hosting       []HostingType // Matches plugins available for the hosting type, including those unrestricted
+	Labels        []string      // Matches label names, ignoring case
+
+	Facets         bool // Whether to count the matching plugins per facet value
+	DownloadCounts bool // Whether to annotate plugins with their download counts, ignored by stores
+
+	// Fields restricts the encoded plugins to a sparse fieldset of dotted JSON paths, excluding
+	// any path prefixed with a hyphen. Stores ignore the fields, which only affect the encoding.
+	Fields []string
+}
+
+// PluginsPage is a single page of plugins matching a PluginFilter.
+type PluginsPage struct {
+	Plugins []*Plugin
+
+	// Total is the number of plugins matching the filter across all pages, or -1 if unknown.
+	Total int
+
+	// NextCursor is the encoded PluginCursor from which to fetch the next page, if any remain.
+	NextCursor string
+
+	// Facets counts the matching plugins per facet value, if requested by the filter.
+	Facets *PluginFacets
+}
+
+// PluginFacets counts the plugins matching a PluginFilter per facet value.
+//
+// Each facet is counted as if the filter placed no constraint on that facet itself, so that a
+// count reflects the plugins that would match were the value added to the filter. Values matching
+// no plugins are omitted.
+type PluginFacets struct {
+	AuthorType   map[AuthorType]int   `json:"author_type"`
+	ReleaseStage map[ReleaseStage]int `json:"release_stage"`
+	Hosting      map[HostingType]int  `json:"hosting"`  // Plugins available for the hosting type, including those unrestricted
+	Label        map[string]int       `json:"label"`    // Keyed by label name
+	Platform     map[string]int       `json:"platform"` // Plugins providing a bundle specific to the platform
+}
+
+// NewPluginFacets constructs an empty set of facet counts.
+func NewPluginFacets() *PluginFacets {
+	return &PluginFacets{
+		AuthorType:   make(map[AuthorType]int),
+		ReleaseStage: make(map[ReleaseStage]int),
+		Hosting:      make(map[HostingType]int),
+		Label:        make(map[string]int),
+		Platform:     make(map[string]int),
+	}
+}
+
+// SortOrder returns the field and direction by which plugins matching the filter are sorted,
+// substituting the defaults for any left unspecified. Plugins are sorted by relevance when
+// searching, and by name otherwise.
+func (f *PluginFilter) SortOrder() (PluginSort, SortDirection) {
+	sort := f.Sort
+	if sort == "" {
+		sort = SortByName
+		if strings.TrimSpace(f.Filter) != "" {
+			sort = SortByRelevance
+		}
+	}
+
+	direction := f.SortDirection
+	if direction == "" {
+		direction = sort.DefaultDirection()
+	}
+
+	return sort, direction
 }
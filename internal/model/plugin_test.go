@@ -3,6 +3,7 @@ package model
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
 
@@ -236,3 +237,21 @@ func TestPluginsToWriter(t *testing.T) {
 		assert.Equal(t, expectedResult, b.String())
 	})
 }
+
+func TestPluginYankWarning(t *testing.T) {
+	plugin := &Plugin{
+		Manifest: &mattermostModel.Manifest{
+			Id:      "matterpoll",
+			Version: "1.3.0",
+		},
+	}
+	assert.False(t, plugin.IsYanked())
+	assert.Empty(t, plugin.YankWarning())
+
+	plugin.Yanked = &Yank{
+		Reason:   "Fails to start on Mattermost 6.0",
+		YankedAt: time.Date(2021, time.October, 4, 12, 0, 0, 0, time.UTC),
+	}
+	assert.True(t, plugin.IsYanked())
+	assert.Equal(t, "version 1.3.0 of plugin matterpoll was yanked on 2021-10-04: Fails to start on Mattermost 6.0", plugin.YankWarning())
+}
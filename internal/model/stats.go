@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// DayFormat is the layout of the days by which downloads are counted, in UTC.
+const DayFormat = "2006-01-02"
+
+// Download describes a single download of a plugin bundle through the marketplace.
+type Download struct {
+	PluginID string    `json:"plugin_id"`
+	Version  string    `json:"version"`
+	Platform string    `json:"platform,omitempty"` // The platform requested by the client, if any
+	Time     time.Time `json:"time"`
+}
+
+// DownloadStats counts the downloads of a single version of a plugin for a platform on a day.
+type DownloadStats struct {
+	Version  string `json:"version"`
+	Platform string `json:"platform,omitempty"`
+	Day      string `json:"day"` // Formatted using DayFormat
+	Count    int64  `json:"count"`
+}
+
+// PluginStats summarizes the downloads of a plugin.
+type PluginStats struct {
+	PluginID      string           `json:"plugin_id"`
+	DownloadCount int64            `json:"download_count"`
+	Downloads     []*DownloadStats `json:"downloads"` // Ordered by day, version and platform
+}
@@ -0,0 +1,308 @@
+package search
+
+import (
+	"math"
+	"strings"
+)
+
+// Field identifies the part of a document in which a term appears.
+type Field int
+
+// The fields of a document, from least to most significant.
+const (
+	FieldKeywords Field = iota
+	FieldDescription
+	FieldName
+	FieldID
+)
+
+// fieldBoosts weights a term by the fields of the document in which it appears.
+var fieldBoosts = map[Field]float64{
+	FieldID:          8,
+	FieldName:        4,
+	FieldDescription: 2,
+	FieldKeywords:    1,
+}
+
+const (
+	// exactIDBonus and exactNameBonus rank a document above all others when the whole query is
+	// exactly its id or name.
+	exactIDBonus   = 1000
+	exactNameBonus = 500
+
+	// prefixQuality and typoQuality discount terms matching a token only by prefix or by
+	// tolerating typos, relative to an exact match.
+	prefixQuality = 0.5
+	typoQuality   = 0.7
+
+	// minPrefixLength is the shortest token that may match terms by prefix.
+	minPrefixLength = 3
+)
+
+// Document is the searchable text of a single item.
+type Document struct {
+	ID          string
+	Name        string
+	Description string
+	Keywords    []string
+
+	// LocalizedNames and LocalizedDescriptions hold translations of the name and description,
+	// searched as if part of the name and description respectively.
+	LocalizedNames        []string
+	LocalizedDescriptions []string
+}
+
+// indexedDocument retains the text of a document needed to recognize exact matches.
+type indexedDocument struct {
+	id    string
+	names []string // The name followed by any localized names
+}
+
+// Index is an in-memory inverted index over a fixed set of documents.
+//
+// Documents are identified by their position in the slice from which the index was built.
+type Index struct {
+	documents []indexedDocument
+
+	// postings maps each term to the documents containing it, weighted by the fields in which
+	// the term appears.
+	postings map[string]map[int]float64
+}
+
+// NewIndex builds an index over the given documents.
+func NewIndex(documents []Document) *Index {
+	index := &Index{
+		documents: make([]indexedDocument, 0, len(documents)),
+		postings:  make(map[string]map[int]float64),
+	}
+
+	for i, document := range documents {
+		indexed := indexedDocument{
+			id: strings.ToLower(strings.TrimSpace(document.ID)),
+		}
+		for _, name := range append([]string{document.Name}, document.LocalizedNames...) {
+			indexed.names = append(indexed.names, strings.ToLower(strings.TrimSpace(name)))
+		}
+		index.documents = append(index.documents, indexed)
+
+		fields := map[Field]string{
+			FieldID:          document.ID,
+			FieldName:        strings.Join(append([]string{document.Name}, document.LocalizedNames...), " "),
+			FieldDescription: strings.Join(append([]string{document.Description}, document.LocalizedDescriptions...), " "),
+			FieldKeywords:    strings.Join(document.Keywords, " "),
+		}
+
+		// Weight each term once per field, regardless of how often it is repeated within it.
+		weights := make(map[string]float64)
+		for field, text := range fields {
+			seen := make(map[string]bool)
+			for _, term := range Tokenize(text) {
+				if seen[term] {
+					continue
+				}
+				seen[term] = true
+
+				weights[term] += fieldBoosts[field]
+			}
+		}
+
+		for term, weight := range weights {
+			if index.postings[term] == nil {
+				index.postings[term] = make(map[int]float64)
+			}
+			index.postings[term][i] = weight
+		}
+	}
+
+	return index
+}
+
+// Search scores every document matching the given query, keyed by the document's position.
+//
+// The query is split into words on whitespace. A word matches a document if every token within
+// it does, such that an identifier like "com.mattermost.demo" must match in full. Tokens match
+// terms exactly after stemming, by prefix, or with a small number of typos. A document matches if
+// at least half of the words do, with documents matching more words ranking higher.
+//
+// A nil result is returned for an empty query, which places no constraint on the documents.
+func (index *Index) Search(query string) map[int]float64 {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+
+	var words [][]string
+	for _, word := range strings.Fields(query) {
+		if tokens := Tokenize(word); len(tokens) > 0 {
+			words = append(words, tokens)
+		}
+	}
+
+	scores := make(map[int]float64)
+	matchedWords := make(map[int]int)
+	for _, tokens := range words {
+		for document, score := range index.matchWord(tokens) {
+			scores[document] += score
+			matchedWords[document]++
+		}
+	}
+
+	required := (len(words) + 1) / 2
+	results := make(map[int]float64)
+	for document, score := range scores {
+		if matchedWords[document] < required {
+			continue
+		}
+
+		results[document] = score * float64(matchedWords[document]) / float64(len(words))
+	}
+
+	lowerQuery := strings.ToLower(query)
+	for i, document := range index.documents {
+		if document.id == lowerQuery {
+			results[i] += exactIDBonus
+		} else if document.hasName(lowerQuery) {
+			results[i] += exactNameBonus
+		}
+	}
+
+	return results
+}
+
+// hasName reports whether the given lowercase query is exactly the name of the document, in any
+// locale.
+func (document indexedDocument) hasName(query string) bool {
+	for _, name := range document.names {
+		if name == query {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchWord scores the documents matching every one of the given tokens.
+func (index *Index) matchWord(tokens []string) map[int]float64 {
+	var scores map[int]float64
+	for _, token := range tokens {
+		tokenScores := index.matchToken(token)
+		if scores == nil {
+			scores = tokenScores
+			continue
+		}
+
+		for document := range scores {
+			if tokenScore, ok := tokenScores[document]; ok {
+				scores[document] += tokenScore
+			} else {
+				delete(scores, document)
+			}
+		}
+	}
+
+	return scores
+}
+
+// matchToken scores the documents containing a term matching the given token, taking the best
+// matching term for each document.
+func (index *Index) matchToken(token string) map[int]float64 {
+	scores := make(map[int]float64)
+	maxTypos := allowedTypos(token)
+
+	for term, postings := range index.postings {
+		var quality float64
+		switch {
+		case term == token:
+			quality = 1
+		case maxTypos > 0 && editDistance(term, token, maxTypos) <= maxTypos:
+			quality = typoQuality
+		case len(token) >= minPrefixLength && strings.HasPrefix(term, token):
+			quality = prefixQuality
+		default:
+			continue
+		}
+
+		idf := math.Log(1 + float64(len(index.documents))/float64(len(postings)))
+		for document, weight := range postings {
+			if score := quality * idf * weight; score > scores[document] {
+				scores[document] = score
+			}
+		}
+	}
+
+	return scores
+}
+
+// allowedTypos returns the number of typos tolerated when matching the given token, with longer
+// tokens tolerating more.
+func allowedTypos(token string) int {
+	switch length := len([]rune(token)); {
+	case length >= 8:
+		return 2
+	case length >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// editDistance returns the optimal string alignment distance between a and b, counting
+// insertions, deletions, substitutions and transpositions of adjacent characters. Distances
+// beyond max are reported as max+1.
+func editDistance(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if diff := len(ra) - len(rb); diff > max || -diff > max {
+		return max + 1
+	}
+
+	// Only the previous two rows are needed to compute each row of the distance matrix.
+	previous2 := make([]int, len(rb)+1)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		rowMin := current[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				current[j] = minInt(current[j], previous2[j-2]+1)
+			}
+
+			if current[j] < rowMin {
+				rowMin = current[j]
+			}
+		}
+
+		if rowMin > max {
+			return max + 1
+		}
+
+		previous2, previous, current = previous, current, previous2
+	}
+
+	if previous[len(rb)] > max {
+		return max + 1
+	}
+
+	return previous[len(rb)]
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+
+	return result
+}
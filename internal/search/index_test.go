@@ -0,0 +1,110 @@
+package search
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexSearch(t *testing.T) {
+	documents := []Document{
+		{ID: "jenkins", Name: "Jenkins", Description: "Jenkins plugin for Mattermost"},
+		{ID: "zoom", Name: "Zoom", Description: "Zoom audio and video conferencing plugin for Mattermost."},
+		{
+			ID:                    "com.mattermost.agenda",
+			Name:                  "Agenda",
+			Description:           "Plugin to handle meeting agendas for Mattermost channels.",
+			LocalizedNames:        []string{"Tagesordnung", "議題"},
+			LocalizedDescriptions: []string{"Besprechungen in Kanälen vorbereiten.", "チャンネルの会議の議題を管理します。"},
+		},
+		{ID: "jira", Name: "Jira", Description: "Atlassian Jira integration."},
+		{ID: "jira-server", Name: "Jira Server", Description: "For Jira Server."},
+		{ID: "com.mattermost.demo-plugin", Name: "Demo Plugin", Description: "Demonstrates the capabilities of a plugin.", Keywords: []string{"example"}},
+	}
+	index := NewIndex(documents)
+
+	// ranked returns the ids of the matching documents, from most to least relevant, breaking ties
+	// by id.
+	ranked := func(results map[int]float64) []string {
+		matches := make([]int, 0, len(results))
+		for document := range results {
+			matches = append(matches, document)
+		}
+		sort.Slice(matches, func(i, j int) bool {
+			if results[matches[i]] != results[matches[j]] {
+				return results[matches[i]] > results[matches[j]]
+			}
+			return documents[matches[i]].ID < documents[matches[j]].ID
+		})
+
+		ids := make([]string, 0, len(matches))
+		for _, document := range matches {
+			ids = append(ids, documents[document].ID)
+		}
+
+		return ids
+	}
+
+	testCases := map[string]struct {
+		query    string
+		expected []string
+	}{
+		"stemmed":                    {"jenkin", []string{"jenkins"}},
+		"partial words":              {"jenkin ci", []string{"jenkins"}},
+		"ranked by words matched":    {"zoom meeting", []string{"zoom", "com.mattermost.agenda"}},
+		"typo":                       {"jria", []string{"jira", "jira-server"}},
+		"transposition":              {"agneda", []string{"com.mattermost.agenda"}},
+		"prefix":                     {"confer", []string{"zoom"}},
+		"exact id ranks first":       {"jira", []string{"jira", "jira-server"}},
+		"exact name ranks first":     {"jira server", []string{"jira-server", "jira"}},
+		"identifier matches in full": {"com.mattermost.demo-plugin", []string{"com.mattermost.demo-plugin"}},
+		"keywords":                   {"example", []string{"com.mattermost.demo-plugin"}},
+		"localized name":             {"tagesordnung", []string{"com.mattermost.agenda"}},
+		"localized description":      {"besprechung", []string{"com.mattermost.agenda"}},
+		"ideographs":                 {"会議", []string{"com.mattermost.agenda"}},
+		"no match":                   {"gitlab", []string{}},
+		"most words must match":      {"zoom jira agenda", []string{}},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, ranked(index.Search(testCase.query)))
+		})
+	}
+
+	t.Run("field boosts", func(t *testing.T) {
+		// The demo plugin matches by id, name and description, but Jenkins only by description.
+		results := index.Search("plugin")
+		assert.Greater(t, results[5], results[0])
+
+		// The agenda plugin matches by id and description, but Jenkins only by description.
+		results = index.Search("mattermost")
+		assert.Greater(t, results[2], results[0])
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		assert.Nil(t, index.Search("  "))
+	})
+}
+
+func TestEditDistance(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		max      int
+		expected int
+	}{
+		{"jira", "jira", 1, 0},
+		{"jira", "jria", 1, 1},
+		{"jira", "jir", 1, 1},
+		{"jira", "jiras", 1, 1},
+		{"jira", "zoom", 1, 2},
+		{"kitten", "sitting", 3, 3},
+		{"kitten", "sitting", 1, 2},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, editDistance(testCase.a, testCase.b, testCase.max), "%s %s", testCase.a, testCase.b)
+	}
+}
@@ -0,0 +1,97 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokenize splits the given text into lowercase, stemmed tokens, breaking on any character that
+// is neither a letter nor a digit.
+func Tokenize(text string) []string {
+	var tokens []string
+	for _, word := range splitWords(text) {
+		tokens = append(tokens, Stem(word))
+	}
+
+	return tokens
+}
+
+// splitWords splits the given text into lowercase words, breaking on any character that is
+// neither a letter nor a digit.
+//
+// Since Chinese and Japanese text is written without spaces, each ideograph and kana is treated
+// as a word of its own, allowing a query to match part of a longer phrase.
+func splitWords(text string) []string {
+	var words []string
+	for _, field := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		start := 0
+		for i, r := range field {
+			if !isIdeographic(r) {
+				continue
+			}
+
+			if start < i {
+				words = append(words, field[start:i])
+			}
+			words = append(words, string(r))
+			start = i + utf8.RuneLen(r)
+		}
+		if start < len(field) {
+			words = append(words, field[start:])
+		}
+	}
+
+	return words
+}
+
+// isIdeographic reports whether the given rune belongs to a script written without spaces
+// between words.
+func isIdeographic(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
+
+// Stem reduces the given lowercase word to its stem by stripping common English inflections, such
+// that "meetings", "meeting" and "meet" all share the stem "meet".
+//
+// This is deliberately lighter than a full Porter stemmer: it only needs to be consistent, since
+// both indexed text and queries are stemmed the same way.
+func Stem(word string) string {
+	// Leave short words and anything containing digits, such as versions, untouched.
+	if len(word) <= 3 || strings.IndexFunc(word, unicode.IsDigit) >= 0 {
+		return word
+	}
+
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		word = word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "sses"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is"):
+		word = word[:len(word)-1]
+	}
+
+	for _, suffix := range []string{"ing", "ed"} {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 3 && hasVowel(word[:len(word)-len(suffix)]) {
+			word = word[:len(word)-len(suffix)]
+
+			// Undouble a trailing consonant, such that "running" stems to "run".
+			if n := len(word); n >= 2 && word[n-1] == word[n-2] && !isVowel(rune(word[n-1])) && word[n-1] != 'l' && word[n-1] != 's' {
+				word = word[:n-1]
+			}
+			break
+		}
+	}
+
+	return word
+}
+
+func hasVowel(word string) bool {
+	return strings.IndexFunc(word, isVowel) >= 0
+}
+
+func isVowel(r rune) bool {
+	return strings.ContainsRune("aeiouy", r)
+}
@@ -0,0 +1,51 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	testCases := map[string]struct {
+		text     string
+		expected []string
+	}{
+		"empty":       {"", nil},
+		"punctuation": {"com.mattermost.demo-plugin", []string{"com", "mattermost", "demo", "plugin"}},
+		"mixed case":  {"Jenkins CI", []string{"jenkin", "ci"}},
+		"versions":    {"Mattermost 5.2+", []string{"mattermost", "5", "2"}},
+		"ideographs":  {"Jira連携プラグイン", []string{"jira", "連", "携", "プ", "ラ", "グ", "イ", "ン"}},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, Tokenize(testCase.text))
+		})
+	}
+}
+
+func TestStem(t *testing.T) {
+	testCases := map[string]string{
+		"meet":         "meet",
+		"meeting":      "meet",
+		"meetings":     "meet",
+		"running":      "run",
+		"agendas":      "agenda",
+		"capabilities": "capability",
+		"addresses":    "address",
+		"status":       "status",
+		"analysis":     "analysis",
+		"ci":           "ci",
+		"bed":          "bed",
+		"v5s":          "v5s",
+	}
+
+	for word, expected := range testCases {
+		word, expected := word, expected
+		t.Run(word, func(t *testing.T) {
+			assert.Equal(t, expected, Stem(word))
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package stats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// File is a sink persisting downloads to a local file, allowing the counts to survive a restart.
+//
+// Each download is appended to the file as a line of JSON, and the counts are rebuilt in memory
+// by replaying the file when opened. The file is never compacted, growing by one line per download.
+type File struct {
+	*Memory
+
+	lock sync.Mutex
+	file *os.File
+}
+
+// NewFile opens the sink persisted at the given path, creating the file if it does not exist.
+//
+// A partial last line, as left behind by a crash while appending, is truncated with a warning.
+func NewFile(path string, logger logrus.FieldLogger) (*File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open %s", path)
+	}
+
+	memory := NewMemory()
+	reader := bufio.NewReader(file)
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			file.Close()
+			return nil, errors.Wrapf(err, "failed to read %s", path)
+		}
+		partial := err == io.EOF
+
+		if len(bytes.TrimSpace(line)) > 0 {
+			var download model.Download
+			if err = json.Unmarshal(line, &download); err != nil {
+				if !partial {
+					file.Close()
+					return nil, errors.Wrapf(err, "failed to parse %s at offset %d", path, offset)
+				}
+
+				logger.WithError(err).WithField("stats_file", path).Warn("Truncating partial download record at the end of the stats file")
+				if err = file.Truncate(offset); err != nil {
+					file.Close()
+					return nil, errors.Wrapf(err, "failed to truncate %s", path)
+				}
+				break
+			}
+
+			_ = memory.RecordDownload(&download)
+
+			// Terminate a complete record interrupted just before its newline, so that the
+			// next download is appended on a line of its own.
+			if partial {
+				if _, err = file.Write([]byte{'\n'}); err != nil {
+					file.Close()
+					return nil, errors.Wrapf(err, "failed to repair %s", path)
+				}
+			}
+		}
+
+		if partial {
+			break
+		}
+		offset += int64(len(line))
+	}
+
+	return &File{
+		Memory: memory,
+		file:   file,
+	}, nil
+}
+
+// RecordDownload persists and counts the given download.
+func (f *File) RecordDownload(download *model.Download) error {
+	data, err := json.Marshal(download)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode download")
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	_, err = f.file.Write(append(data, '\n'))
+	if err != nil {
+		return errors.Wrap(err, "failed to persist download")
+	}
+
+	return f.Memory.RecordDownload(download)
+}
+
+// Close closes the underlying file.
+func (f *File) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.file.Close()
+}
@@ -0,0 +1,91 @@
+package stats
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/testlib"
+)
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.jsonl")
+	now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	file, err := NewFile(path, testlib.MakeLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, file.RecordDownload(&model.Download{PluginID: "demo", Version: "1.0.0", Time: now}))
+	require.NoError(t, file.RecordDownload(&model.Download{PluginID: "demo", Version: "1.0.0", Time: now}))
+	require.NoError(t, file.Close())
+
+	t.Run("counts survive reopening", func(t *testing.T) {
+		file, err := NewFile(path, testlib.MakeLogger(t))
+		require.NoError(t, err)
+		defer file.Close()
+
+		require.NoError(t, file.RecordDownload(&model.Download{PluginID: "demo", Version: "2.0.0", Time: now}))
+
+		stats, err := file.GetPluginStats("demo")
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), stats.DownloadCount)
+		assert.Equal(t, []*model.DownloadStats{
+			{Version: "1.0.0", Day: "2020-01-01", Count: 2},
+			{Version: "2.0.0", Day: "2020-01-01", Count: 1},
+		}, stats.Downloads)
+	})
+
+	t.Run("corrupt file", func(t *testing.T) {
+		corruptPath := filepath.Join(t.TempDir(), "corrupt.jsonl")
+		require.NoError(t, ioutil.WriteFile(corruptPath, []byte("not json\n{}\n"), 0600))
+
+		_, err := NewFile(corruptPath, testlib.MakeLogger(t))
+		require.Error(t, err)
+	})
+
+	t.Run("partial last record", func(t *testing.T) {
+		data, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+
+		tornPath := filepath.Join(t.TempDir(), "torn.jsonl")
+		require.NoError(t, ioutil.WriteFile(tornPath, append(data, `{"plugin_id":"de`...), 0600))
+
+		file, err := NewFile(tornPath, testlib.MakeLogger(t))
+		require.NoError(t, err)
+		require.NoError(t, file.RecordDownload(&model.Download{PluginID: "demo", Version: "2.0.0", Time: now}))
+		require.NoError(t, file.Close())
+
+		file, err = NewFile(tornPath, testlib.MakeLogger(t))
+		require.NoError(t, err)
+		defer file.Close()
+
+		stats, err := file.GetPluginStats("demo")
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), stats.DownloadCount)
+	})
+
+	t.Run("last record missing its newline", func(t *testing.T) {
+		data, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+
+		unterminatedPath := filepath.Join(t.TempDir(), "unterminated.jsonl")
+		require.NoError(t, ioutil.WriteFile(unterminatedPath, data[:len(data)-1], 0600))
+
+		file, err := NewFile(unterminatedPath, testlib.MakeLogger(t))
+		require.NoError(t, err)
+		require.NoError(t, file.RecordDownload(&model.Download{PluginID: "demo", Version: "2.0.0", Time: now}))
+		require.NoError(t, file.Close())
+
+		file, err = NewFile(unterminatedPath, testlib.MakeLogger(t))
+		require.NoError(t, err)
+		defer file.Close()
+
+		stats, err := file.GetPluginStats("demo")
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), stats.DownloadCount)
+	})
+}
@@ -0,0 +1,97 @@
+package stats
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// downloadKey identifies the bucket in which a download is counted.
+type downloadKey struct {
+	pluginID string
+	version  string
+	platform string
+	day      string
+}
+
+// Memory is a sink counting downloads in memory, losing them on restart.
+type Memory struct {
+	lock   sync.RWMutex
+	counts map[downloadKey]int64
+}
+
+// NewMemory creates a new, empty instance of an in-memory sink.
+func NewMemory() *Memory {
+	return &Memory{
+		counts: make(map[downloadKey]int64),
+	}
+}
+
+// RecordDownload counts the given download.
+func (m *Memory) RecordDownload(download *model.Download) error {
+	key := downloadKey{
+		pluginID: download.PluginID,
+		version:  download.Version,
+		platform: download.Platform,
+		day:      download.Time.UTC().Format(model.DayFormat),
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.counts[key]++
+
+	return nil
+}
+
+// GetPluginStats summarizes the downloads of the given plugin.
+func (m *Memory) GetPluginStats(pluginID string) (*model.PluginStats, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	stats := &model.PluginStats{
+		PluginID:  pluginID,
+		Downloads: []*model.DownloadStats{},
+	}
+	for key, count := range m.counts {
+		if key.pluginID != pluginID {
+			continue
+		}
+
+		stats.DownloadCount += count
+		stats.Downloads = append(stats.Downloads, &model.DownloadStats{
+			Version:  key.version,
+			Platform: key.platform,
+			Day:      key.day,
+			Count:    count,
+		})
+	}
+
+	sort.Slice(stats.Downloads, func(i, j int) bool {
+		a, b := stats.Downloads[i], stats.Downloads[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		if a.Version != b.Version {
+			return a.Version < b.Version
+		}
+		return a.Platform < b.Platform
+	})
+
+	return stats, nil
+}
+
+// GetDownloadCounts returns the number of downloads of every version of each plugin, keyed by
+// plugin id.
+func (m *Memory) GetDownloadCounts() (map[string]int64, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	counts := make(map[string]int64)
+	for key, count := range m.counts {
+		counts[key.pluginID] += count
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,56 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+func TestMemory(t *testing.T) {
+	day1 := time.Date(2020, time.January, 1, 23, 0, 0, 0, time.UTC)
+	day2 := time.Date(2020, time.January, 2, 1, 0, 0, 0, time.FixedZone("EST", -5*60*60))
+
+	memory := NewMemory()
+	for _, download := range []*model.Download{
+		{PluginID: "demo", Version: "1.0.0", Time: day2},
+		{PluginID: "demo", Version: "1.0.0", Platform: model.LinuxAmd64, Time: day1},
+		{PluginID: "demo", Version: "1.0.0", Platform: model.LinuxAmd64, Time: day1},
+		{PluginID: "demo", Version: "0.9.0", Time: day1},
+		{PluginID: "other", Version: "1.0.0", Time: day1},
+	} {
+		require.NoError(t, memory.RecordDownload(download))
+	}
+
+	t.Run("plugin stats", func(t *testing.T) {
+		stats, err := memory.GetPluginStats("demo")
+		require.NoError(t, err)
+		assert.Equal(t, &model.PluginStats{
+			PluginID:      "demo",
+			DownloadCount: 4,
+			Downloads: []*model.DownloadStats{
+				{Version: "0.9.0", Day: "2020-01-01", Count: 1},
+				{Version: "1.0.0", Platform: model.LinuxAmd64, Day: "2020-01-01", Count: 2},
+				{Version: "1.0.0", Day: "2020-01-02", Count: 1},
+			},
+		}, stats)
+	})
+
+	t.Run("unknown plugin", func(t *testing.T) {
+		stats, err := memory.GetPluginStats("unknown")
+		require.NoError(t, err)
+		assert.Equal(t, &model.PluginStats{
+			PluginID:  "unknown",
+			Downloads: []*model.DownloadStats{},
+		}, stats)
+	})
+
+	t.Run("download counts", func(t *testing.T) {
+		counts, err := memory.GetDownloadCounts()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"demo": 4, "other": 1}, counts)
+	})
+}
@@ -0,0 +1,19 @@
+// Package stats counts the downloads of plugins through the marketplace.
+package stats
+
+import (
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// Sink records downloads and reports the resulting statistics.
+type Sink interface {
+	// RecordDownload counts the given download.
+	RecordDownload(download *model.Download) error
+
+	// GetPluginStats summarizes the downloads of the given plugin.
+	GetPluginStats(pluginID string) (*model.PluginStats, error)
+
+	// GetDownloadCounts returns the number of downloads of every version of each plugin, keyed
+	// by plugin id.
+	GetDownloadCounts() (map[string]int64, error)
+}
@@ -0,0 +1,143 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// StaticAdvisories provides access to a static set of security advisories.
+type StaticAdvisories struct {
+	advisories []*model.Advisory
+	revision   string
+}
+
+// NewStaticAdvisories constructs a new set of advisories, validating each advisory.
+func NewStaticAdvisories(advisories []*model.Advisory) (*StaticAdvisories, error) {
+	seen := make(map[string]bool, len(advisories))
+	for _, advisory := range advisories {
+		err := advisory.Validate()
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[advisory.ID] {
+			return nil, errors.Errorf("duplicate advisory %s", advisory.ID)
+		}
+		seen[advisory.ID] = true
+	}
+
+	data, err := json.Marshal(advisories)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode advisories")
+	}
+	hash := sha256.Sum256(data)
+
+	return &StaticAdvisories{
+		advisories: advisories,
+		revision:   hex.EncodeToString(hash[:]),
+	}, nil
+}
+
+// NewStaticAdvisoriesFromReader constructs a new set of advisories, parsing them from the given
+// reader.
+func NewStaticAdvisoriesFromReader(reader io.Reader) (*StaticAdvisories, error) {
+	advisories, err := model.AdvisoriesFromReader(reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse advisories")
+	}
+
+	return NewStaticAdvisories(advisories)
+}
+
+// GetAdvisories returns the advisories matching the given filter, in the order given.
+func (a *StaticAdvisories) GetAdvisories(filter *model.AdvisoryFilter) ([]*model.Advisory, error) {
+	result := []*model.Advisory{}
+	for _, advisory := range a.advisories {
+		if filter.Matches(advisory) {
+			result = append(result, advisory)
+		}
+	}
+
+	return result, nil
+}
+
+// Revision returns a digest of the advisories, changing whenever any advisory changes.
+func (a *StaticAdvisories) Revision() string {
+	return a.revision
+}
+
+// AdvisoriesFile provides access to the security advisories read from a JSON file, such as the
+// advisories.json maintained alongside plugins.json. A missing file holds no advisories.
+type AdvisoriesFile struct {
+	path string
+
+	lock       sync.RWMutex
+	advisories *StaticAdvisories
+}
+
+// NewAdvisoriesFile reads the advisories from the given file.
+func NewAdvisoriesFile(path string) (*AdvisoriesFile, error) {
+	file := &AdvisoriesFile{
+		path: path,
+	}
+
+	err := file.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// Reload rereads the advisories from the file. On failure, the current advisories are retained.
+func (f *AdvisoriesFile) Reload() error {
+	advisories, err := f.load()
+	if err != nil {
+		return errors.Wrapf(err, "failed to load advisories from %s", f.path)
+	}
+
+	f.lock.Lock()
+	f.advisories = advisories
+	f.lock.Unlock()
+
+	return nil
+}
+
+// load reads the advisories currently in the file.
+func (f *AdvisoriesFile) load() (*StaticAdvisories, error) {
+	file, err := os.Open(f.path)
+	if os.IsNotExist(err) {
+		return NewStaticAdvisories(nil)
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return NewStaticAdvisoriesFromReader(file)
+}
+
+// current returns the most recently loaded advisories.
+func (f *AdvisoriesFile) current() *StaticAdvisories {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.advisories
+}
+
+// GetAdvisories returns the advisories matching the given filter, in the order given by the file.
+func (f *AdvisoriesFile) GetAdvisories(filter *model.AdvisoryFilter) ([]*model.Advisory, error) {
+	return f.current().GetAdvisories(filter)
+}
+
+// Revision returns a digest of the advisories, changing whenever a reload changes any advisory.
+func (f *AdvisoriesFile) Revision() string {
+	return f.current().Revision()
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+func TestStaticAdvisories(t *testing.T) {
+	jira := &model.Advisory{
+		ID:               "MMSA-2021-0001",
+		PluginID:         "jira",
+		AffectedVersions: []string{"<2.4.1"},
+		Severity:         model.SeverityHigh,
+	}
+	github := &model.Advisory{
+		ID:               "MMSA-2021-0002",
+		PluginID:         "github",
+		AffectedVersions: []string{"<1.0.0"},
+		Severity:         model.SeverityLow,
+	}
+
+	advisories, err := NewStaticAdvisories([]*model.Advisory{jira, github})
+	require.NoError(t, err)
+	assert.NotEmpty(t, advisories.Revision())
+
+	result, err := advisories.GetAdvisories(&model.AdvisoryFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Advisory{jira, github}, result)
+
+	result, err = advisories.GetAdvisories(&model.AdvisoryFilter{Severity: model.SeverityHigh})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Advisory{jira}, result)
+
+	result, err = advisories.GetAdvisories(&model.AdvisoryFilter{PluginID: "unknown"})
+	require.NoError(t, err)
+	assert.Empty(t, result)
+
+	t.Run("invalid advisory", func(t *testing.T) {
+		_, err := NewStaticAdvisories([]*model.Advisory{{ID: "MMSA-2021-0003"}})
+		require.Error(t, err)
+	})
+
+	t.Run("duplicate advisory", func(t *testing.T) {
+		_, err := NewStaticAdvisories([]*model.Advisory{jira, jira})
+		require.Error(t, err)
+	})
+}
+
+func TestAdvisoriesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "advisories.json")
+
+	t.Run("missing file holds no advisories", func(t *testing.T) {
+		advisories, err := NewAdvisoriesFile(path)
+		require.NoError(t, err)
+
+		result, err := advisories.GetAdvisories(&model.AdvisoryFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, result)
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		require.NoError(t, ioutil.WriteFile(path, []byte(`[]`), 0644))
+		advisories, err := NewAdvisoriesFile(path)
+		require.NoError(t, err)
+		revision := advisories.Revision()
+
+		require.NoError(t, ioutil.WriteFile(path, []byte(`[{"id": "a", "plugin_id": "jira", "affected_versions": ["<1.0.0"], "severity": "low"}]`), 0644))
+		require.NoError(t, advisories.Reload())
+		assert.NotEqual(t, revision, advisories.Revision())
+
+		result, err := advisories.GetAdvisories(&model.AdvisoryFilter{})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+
+		// A malformed file retains the current advisories.
+		require.NoError(t, ioutil.WriteFile(path, []byte(`[{"id": "b"}]`), 0644))
+		require.Error(t, advisories.Reload())
+
+		result, err = advisories.GetAdvisories(&model.AdvisoryFilter{})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, "a", result[0].ID)
+	})
+
+	t.Run("malformed file", func(t *testing.T) {
+		require.NoError(t, ioutil.WriteFile(path, []byte(`{`), 0644))
+		_, err := NewAdvisoriesFile(path)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,144 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// GetPluginCompatibility explains which versions of the plugin identified by the filter's
+// PluginID match the filter, naming the rule that included or excluded each version.
+func (store *StaticStore) GetPluginCompatibility(pluginFilter *model.PluginFilter) (*model.PluginCompatibility, error) {
+	var versions []*model.VersionCompatibility
+	for _, storePlugin := range store.plugins {
+		if storePlugin.Manifest.Id != pluginFilter.PluginID {
+			continue
+		}
+
+		rule, err := checkCompatibility(storePlugin, pluginFilter.ServerVersion, pluginFilter.EnterprisePlugins, pluginFilter.Cloud)
+		if err != nil {
+			return nil, err
+		}
+		if rule.Includes() && storePlugin.IsYanked() && (!pluginFilter.ReturnAllVersions || !pluginFilter.IncludeYanked) {
+			rule = model.RuleYanked
+		}
+
+		plugin := *storePlugin
+		plugin.AddLabels(store.labels)
+
+		versions = append(versions, &model.VersionCompatibility{
+			Version:        plugin.Manifest.Version,
+			Rule:           rule,
+			PlatformBundle: selectPlatformBundle(&plugin, pluginFilter.Platform),
+			Plugin:         &plugin,
+		})
+	}
+
+	return explainCompatibility(pluginFilter, versions)
+}
+
+// explainCompatibility completes the explanation of the given versions of a plugin, each already
+// checked for compatibility with the server described by the filter.
+//
+// Mirroring GetPluginsPage, compatible versions other than the latest are superseded unless all
+// versions are requested, and only then are the facets of the filter applied. Versions are
+// considered in the order given, with later versions preferred when the same version is repeated.
+func explainCompatibility(pluginFilter *model.PluginFilter, versions []*model.VersionCompatibility) (*model.PluginCompatibility, error) {
+	parsed := make([]semver.Version, 0, len(versions))
+	for _, version := range versions {
+		v, err := semver.Parse(version.Version)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse version %s", version.Version)
+		}
+		parsed = append(parsed, v)
+	}
+
+	if !pluginFilter.ReturnAllVersions {
+		latest := -1
+		for i, version := range versions {
+			if version.Rule.Includes() && (latest == -1 || parsed[i].GTE(parsed[latest])) {
+				latest = i
+			}
+		}
+
+		for i, version := range versions {
+			if version.Rule.Includes() && i != latest {
+				version.Rule = model.RuleSuperseded
+			}
+		}
+	}
+
+	for _, version := range versions {
+		if version.Rule.Includes() && !pluginMatchesFacets(version.Plugin, pluginFilter) {
+			version.Rule = model.RuleFiltered
+		}
+
+		version.Compatible = version.Rule.Includes()
+		version.Reason = describeRule(version, pluginFilter)
+	}
+
+	order := make([]int, len(versions))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return parsed[order[i]].GT(parsed[order[j]])
+	})
+
+	result := &model.PluginCompatibility{
+		PluginID: pluginFilter.PluginID,
+		Versions: make([]*model.VersionCompatibility, 0, len(versions)),
+	}
+	for _, i := range order {
+		result.Versions = append(result.Versions, versions[i])
+	}
+
+	return result, nil
+}
+
+// describeRule explains the rule that included or excluded the given version in human readable
+// terms.
+func describeRule(version *model.VersionCompatibility, pluginFilter *model.PluginFilter) string {
+	var reason string
+	switch version.Rule {
+	case model.RuleCompatible:
+		reason = "compatible with the requested server"
+	case model.RuleEnterpriseLegacyServer:
+		reason = fmt.Sprintf("enterprise plugin included since server %s predates the enterprise_plugins flag introduced in %s", pluginFilter.ServerVersion, minVersionSupportingEnterpriseFlags)
+	case model.RuleEnterprise:
+		reason = "enterprise plugin excluded since enterprise_plugins was not requested"
+	case model.RuleOnPremOnly:
+		reason = "only available for on-prem servers, but cloud was requested"
+	case model.RuleCloudOnly:
+		reason = "only available for cloud servers, but cloud was not requested"
+	case model.RuleMinServerVersion:
+		reason = fmt.Sprintf("requires server %s or later, but server %s was requested", version.Plugin.Manifest.MinServerVersion, pluginFilter.ServerVersion)
+	case model.RuleSuperseded:
+		reason = "superseded by a newer compatible version, since return_all_versions was not requested"
+	case model.RulePinned:
+		reason = "excluded in favour of the version to which the plugin is pinned, since return_all_versions was not requested"
+	case model.RuleYanked:
+		reason = "withdrawn from the marketplace"
+		if version.Plugin != nil && version.Plugin.Yanked != nil && version.Plugin.Yanked.Reason != "" {
+			reason += ": " + version.Plugin.Yanked.Reason
+		}
+	case model.RuleFiltered:
+		reason = "does not match the requested author_type, release_stage, hosting or label"
+	default:
+		reason = string(version.Rule)
+	}
+
+	if version.Compatible && pluginFilter.Platform != "" {
+		if version.PlatformBundle {
+			reason += fmt.Sprintf("; serving the %s bundle", pluginFilter.Platform)
+		} else {
+			reason += fmt.Sprintf("; no %s bundle, serving the platform-independent bundle", pluginFilter.Platform)
+		}
+	}
+
+	return reason
+}
@@ -0,0 +1,234 @@
+package store
+
+import (
+	"testing"
+
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/testlib"
+)
+
+func TestGetPluginCompatibility(t *testing.T) {
+	newPlugin := func(version, minServerVersion string) *model.Plugin {
+		return &model.Plugin{
+			DownloadURL: "https://example.com/jira-" + version + ".tar.gz",
+			Manifest: &mattermostModel.Manifest{
+				Id:               "jira",
+				Name:             "Jira",
+				Version:          version,
+				MinServerVersion: minServerVersion,
+			},
+			AuthorType:   model.Mattermost,
+			ReleaseStage: model.Production,
+		}
+	}
+
+	v1 := newPlugin("1.0.0", "")
+	v2 := newPlugin("2.0.0", "5.20.0")
+	v2.Platforms.LinuxAmd64 = model.PlatformBundleMetadata{
+		DownloadURL: "https://example.com/jira-2.0.0-linux-amd64.tar.gz",
+		Signature:   "signature",
+	}
+	v3 := newPlugin("3.0.0", "5.30.0")
+	enterprise := newPlugin("2.1.0", "")
+	enterprise.Enterprise = true
+	cloud := newPlugin("2.2.0", "")
+	cloud.Hosting = model.Cloud
+	beta := newPlugin("2.3.0", "")
+	beta.ReleaseStage = model.Beta
+	other := newPlugin("9.0.0", "")
+	other.Manifest.Id = "other"
+
+	logger := testlib.MakeLogger(t)
+	staticStore, err := NewStatic([]*model.Plugin{v1, v2, v3, enterprise, cloud, beta, other}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+
+	rules := func(compatibility *model.PluginCompatibility) map[string]model.CompatibilityRule {
+		result := make(map[string]model.CompatibilityRule)
+		for _, version := range compatibility.Versions {
+			result[version.Version] = version.Rule
+			assert.Equal(t, version.Rule.Includes(), version.Compatible)
+			assert.NotEmpty(t, version.Reason)
+		}
+
+		return result
+	}
+
+	testCases := []struct {
+		Description string
+		Filter      *model.PluginFilter
+		Expected    map[string]model.CompatibilityRule
+	}{
+		{
+			"latest version for recent server",
+			&model.PluginFilter{PluginID: "jira", ServerVersion: "5.26.0"},
+			map[string]model.CompatibilityRule{
+				"3.0.0": model.RuleMinServerVersion,
+				"2.3.0": model.RuleCompatible,
+				"2.2.0": model.RuleCloudOnly,
+				"2.1.0": model.RuleEnterprise,
+				"2.0.0": model.RuleSuperseded,
+				"1.0.0": model.RuleSuperseded,
+			},
+		},
+		{
+			"enterprise plugins on legacy server",
+			&model.PluginFilter{PluginID: "jira", ServerVersion: "5.24.0", ReturnAllVersions: true},
+			map[string]model.CompatibilityRule{
+				"3.0.0": model.RuleMinServerVersion,
+				"2.3.0": model.RuleCompatible,
+				"2.2.0": model.RuleCloudOnly,
+				"2.1.0": model.RuleEnterpriseLegacyServer,
+				"2.0.0": model.RuleCompatible,
+				"1.0.0": model.RuleCompatible,
+			},
+		},
+		{
+			"cloud",
+			&model.PluginFilter{PluginID: "jira", Cloud: true, EnterprisePlugins: true, ReturnAllVersions: true},
+			map[string]model.CompatibilityRule{
+				"3.0.0": model.RuleCompatible,
+				"2.3.0": model.RuleCompatible,
+				"2.2.0": model.RuleCompatible,
+				"2.1.0": model.RuleCompatible,
+				"2.0.0": model.RuleCompatible,
+				"1.0.0": model.RuleCompatible,
+			},
+		},
+		{
+			"facets applied after selecting the latest version",
+			&model.PluginFilter{PluginID: "jira", ServerVersion: "5.26.0", ReleaseStages: []model.ReleaseStage{model.Production}},
+			map[string]model.CompatibilityRule{
+				"3.0.0": model.RuleMinServerVersion,
+				"2.3.0": model.RuleFiltered,
+				"2.2.0": model.RuleCloudOnly,
+				"2.1.0": model.RuleEnterprise,
+				"2.0.0": model.RuleSuperseded,
+				"1.0.0": model.RuleSuperseded,
+			},
+		},
+		{
+			"unknown plugin",
+			&model.PluginFilter{PluginID: "unknown"},
+			map[string]model.CompatibilityRule{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.Description, func(t *testing.T) {
+			compatibility, err := staticStore.GetPluginCompatibility(testCase.Filter)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.Filter.PluginID, compatibility.PluginID)
+			assert.Equal(t, testCase.Expected, rules(compatibility))
+
+			// The explanation must agree with the plugins actually returned.
+			filter := *testCase.Filter
+			filter.PerPage = model.AllPerPage
+			plugins, err := staticStore.GetPlugins(&filter)
+			require.NoError(t, err)
+
+			var returned, explained []string
+			for _, plugin := range plugins {
+				returned = append(returned, plugin.Manifest.Version)
+			}
+			for _, version := range compatibility.Versions {
+				if version.Compatible {
+					explained = append(explained, version.Version)
+				}
+			}
+			assert.Equal(t, returned, explained)
+		})
+	}
+
+	t.Run("versions ordered descending", func(t *testing.T) {
+		compatibility, err := staticStore.GetPluginCompatibility(&model.PluginFilter{PluginID: "jira"})
+		require.NoError(t, err)
+
+		var versions []string
+		for _, version := range compatibility.Versions {
+			versions = append(versions, version.Version)
+		}
+		assert.Equal(t, []string{"3.0.0", "2.3.0", "2.2.0", "2.1.0", "2.0.0", "1.0.0"}, versions)
+	})
+
+	t.Run("platform bundle", func(t *testing.T) {
+		compatibility, err := staticStore.GetPluginCompatibility(&model.PluginFilter{
+			PluginID:          "jira",
+			Platform:          model.LinuxAmd64,
+			ReturnAllVersions: true,
+		})
+		require.NoError(t, err)
+
+		for _, version := range compatibility.Versions {
+			if version.Version == "2.0.0" {
+				assert.True(t, version.PlatformBundle)
+				assert.Equal(t, v2.Platforms.LinuxAmd64.DownloadURL, version.Plugin.DownloadURL)
+				assert.Contains(t, version.Reason, "serving the linux-amd64 bundle")
+			} else if version.Version == "1.0.0" {
+				assert.False(t, version.PlatformBundle)
+				assert.Equal(t, v1.DownloadURL, version.Plugin.DownloadURL)
+				assert.Contains(t, version.Reason, "platform-independent bundle")
+			}
+		}
+	})
+
+	t.Run("yanked versions", func(t *testing.T) {
+		yanked := newPlugin("4.0.0", "")
+		yanked.Yanked = &model.Yank{Reason: "Leaks credentials"}
+		yankedStore, err := NewStatic([]*model.Plugin{v1, yanked}, model.DefaultLabelDefinitions, logger)
+		require.NoError(t, err)
+
+		compatibility, err := yankedStore.GetPluginCompatibility(&model.PluginFilter{PluginID: "jira"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]model.CompatibilityRule{
+			"4.0.0": model.RuleYanked,
+			"1.0.0": model.RuleCompatible,
+		}, rules(compatibility))
+		assert.Contains(t, compatibility.Versions[0].Reason, "Leaks credentials")
+
+		compatibility, err = yankedStore.GetPluginCompatibility(&model.PluginFilter{PluginID: "jira", ReturnAllVersions: true, IncludeYanked: true})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]model.CompatibilityRule{
+			"4.0.0": model.RuleCompatible,
+			"1.0.0": model.RuleCompatible,
+		}, rules(compatibility))
+	})
+
+	t.Run("merged", func(t *testing.T) {
+		newerStore, err := NewStatic([]*model.Plugin{newPlugin("4.0.0", "")}, model.DefaultLabelDefinitions, logger)
+		require.NoError(t, err)
+
+		merged := NewMerged(logger, staticStore, newerStore)
+		compatibility, err := merged.GetPluginCompatibility(&model.PluginFilter{PluginID: "jira", ServerVersion: "5.26.0"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]model.CompatibilityRule{
+			"4.0.0": model.RuleCompatible,
+			"3.0.0": model.RuleMinServerVersion,
+			"2.3.0": model.RuleSuperseded,
+			"2.2.0": model.RuleCloudOnly,
+			"2.1.0": model.RuleEnterprise,
+			"2.0.0": model.RuleSuperseded,
+			"1.0.0": model.RuleSuperseded,
+		}, rules(compatibility))
+	})
+
+	t.Run("restricted versions are omitted", func(t *testing.T) {
+		restricted := NewRestricted(staticStore, &model.PluginFilter{
+			ReleaseStages: []model.ReleaseStage{model.Production},
+		})
+
+		compatibility, err := restricted.GetPluginCompatibility(&model.PluginFilter{PluginID: "jira", ServerVersion: "5.26.0"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]model.CompatibilityRule{
+			"3.0.0": model.RuleMinServerVersion,
+			"2.2.0": model.RuleCloudOnly,
+			"2.1.0": model.RuleEnterprise,
+			"2.0.0": model.RuleSuperseded,
+			"1.0.0": model.RuleSuperseded,
+		}, rules(compatibility))
+	})
+}
@@ -0,0 +1,229 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// FileStore is a writable store backed by a JSON database file, such as the plugins.json
+// maintained by the generator.
+//
+// Every change is validated and written back to the file before being served.
+type FileStore struct {
+	path   string
+	labels []*model.LabelDefinition
+	logger logrus.FieldLogger
+
+	lock   sync.RWMutex
+	static *StaticStore
+}
+
+// NewFile constructs a new instance of a file store, reading the plugins from the given path and
+// assigning them the labels whose given definitions match.
+func NewFile(path string, labels []*model.LabelDefinition, logger logrus.FieldLogger) (*FileStore, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open %s", path)
+	}
+	defer file.Close()
+
+	static, err := NewStaticFromReader(file, labels, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileStore{
+		path:   path,
+		labels: labels,
+		logger: logger,
+		static: static,
+	}, nil
+}
+
+// current returns the static store serving the catalog as last written.
+func (store *FileStore) current() *StaticStore {
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	return store.static
+}
+
+// GetPlugins fetches the given page of plugins. The first page is 0.
+func (store *FileStore) GetPlugins(pluginFilter *model.PluginFilter) ([]*model.Plugin, error) {
+	return store.current().GetPlugins(pluginFilter)
+}
+
+// GetPluginsPage fetches the given page of plugins alongside the total number of matching plugins.
+func (store *FileStore) GetPluginsPage(pluginFilter *model.PluginFilter) (*model.PluginsPage, error) {
+	return store.current().GetPluginsPage(pluginFilter)
+}
+
+// GetPluginCompatibility explains which versions of the plugin identified by the filter's
+// PluginID match the filter, naming the rule that included or excluded each version.
+func (store *FileStore) GetPluginCompatibility(pluginFilter *model.PluginFilter) (*model.PluginCompatibility, error) {
+	return store.current().GetPluginCompatibility(pluginFilter)
+}
+
+// Revision returns a digest of the plugins backing the store, changing with every write.
+func (store *FileStore) Revision() string {
+	return store.current().Revision()
+}
+
+// AddPlugin adds a new version of a plugin to the catalog.
+func (store *FileStore) AddPlugin(plugin *model.Plugin) error {
+	err := plugin.Validate()
+	if err != nil {
+		return err
+	}
+
+	return store.update(func(plugins []*model.Plugin) ([]*model.Plugin, error) {
+		if findPlugin(plugins, plugin.Manifest.Id, plugin.Manifest.Version) >= 0 {
+			return nil, errors.Wrapf(model.ErrPluginExists, "plugin %s version %s", plugin.Manifest.Id, plugin.Manifest.Version)
+		}
+
+		return append(plugins, plugin), nil
+	})
+}
+
+// UpdatePlugin replaces an existing version of a plugin in the catalog.
+func (store *FileStore) UpdatePlugin(plugin *model.Plugin) error {
+	err := plugin.Validate()
+	if err != nil {
+		return err
+	}
+
+	return store.update(func(plugins []*model.Plugin) ([]*model.Plugin, error) {
+		i := findPlugin(plugins, plugin.Manifest.Id, plugin.Manifest.Version)
+		if i < 0 {
+			return nil, errors.Wrapf(model.ErrPluginNotFound, "plugin %s version %s", plugin.Manifest.Id, plugin.Manifest.Version)
+		}
+
+		plugins[i] = plugin
+		return plugins, nil
+	})
+}
+
+// DeletePlugin removes a version of a plugin from the catalog, or every version if the given
+// version is empty.
+func (store *FileStore) DeletePlugin(pluginID, version string) error {
+	return store.update(func(plugins []*model.Plugin) ([]*model.Plugin, error) {
+		result := make([]*model.Plugin, 0, len(plugins))
+		for _, plugin := range plugins {
+			if plugin.Manifest.Id == pluginID && (version == "" || plugin.Manifest.Version == version) {
+				continue
+			}
+			result = append(result, plugin)
+		}
+
+		if len(result) == len(plugins) {
+			return nil, errors.Wrapf(model.ErrPluginNotFound, "plugin %s version %s", pluginID, version)
+		}
+
+		return result, nil
+	})
+}
+
+// update applies the given change to a copy of the catalog, validating and writing the result
+// back to the file before serving it.
+func (store *FileStore) update(change func([]*model.Plugin) ([]*model.Plugin, error)) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	plugins := append([]*model.Plugin{}, store.static.plugins...)
+	plugins, err := change(plugins)
+	if err != nil {
+		return err
+	}
+
+	err = validatePlugins(plugins)
+	if err != nil {
+		return errors.Wrap(err, "failed to validate plugins")
+	}
+	sortCatalog(plugins)
+
+	static, err := NewStatic(plugins, store.labels, store.logger)
+	if err != nil {
+		return err
+	}
+
+	err = store.write(plugins)
+	if err != nil {
+		return err
+	}
+
+	store.static = static
+
+	return nil
+}
+
+// write atomically replaces the database file with the given plugins.
+func (store *FileStore) write(plugins []*model.Plugin) error {
+	file, err := ioutil.TempFile(filepath.Dir(store.path), filepath.Base(store.path)+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary database")
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	// Preserve the permissions of the existing database, rather than those of a temporary file.
+	info, err := os.Stat(store.path)
+	if err == nil {
+		err = file.Chmod(info.Mode())
+		if err != nil {
+			return errors.Wrap(err, "failed to set permissions of temporary database")
+		}
+	}
+
+	err = model.PluginsToWriter(file, plugins)
+	if err != nil {
+		return errors.Wrap(err, "failed to write temporary database")
+	}
+
+	err = file.Close()
+	if err != nil {
+		return errors.Wrap(err, "failed to close temporary database")
+	}
+
+	err = os.Rename(file.Name(), store.path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to replace database %s", store.path)
+	}
+
+	return nil
+}
+
+// findPlugin returns the index of the given version of a plugin, or -1 if not found.
+func findPlugin(plugins []*model.Plugin, pluginID, version string) int {
+	for i, plugin := range plugins {
+		if plugin.Manifest.Id == pluginID && plugin.Manifest.Version == version {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// sortCatalog orders the given plugins as the generator does: by id ascending, then by version
+// descending.
+func sortCatalog(plugins []*model.Plugin) {
+	sort.SliceStable(plugins, func(i, j int) bool {
+		switch strings.Compare(plugins[i].Manifest.Id, plugins[j].Manifest.Id) {
+		case -1:
+			return true
+		case 1:
+			return false
+		default:
+			return semver.MustParse(plugins[i].Manifest.Version).GT(semver.MustParse(plugins[j].Manifest.Version))
+		}
+	})
+}
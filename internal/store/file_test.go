@@ -0,0 +1,134 @@
+package store
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/testlib"
+)
+
+func TestFileStore(t *testing.T) {
+	newPlugin := func(id, version string) *model.Plugin {
+		return &model.Plugin{
+			Manifest: &mattermostModel.Manifest{Id: id, Name: id, Version: version},
+		}
+	}
+
+	setup := func(t *testing.T, plugins ...*model.Plugin) (*FileStore, string) {
+		path := filepath.Join(t.TempDir(), "plugins.json")
+
+		var buf bytes.Buffer
+		require.NoError(t, model.PluginsToWriter(&buf, plugins))
+		require.NoError(t, ioutil.WriteFile(path, buf.Bytes(), 0644))
+
+		fileStore, err := NewFile(path, model.DefaultLabelDefinitions, testlib.MakeLogger(t))
+		require.NoError(t, err)
+
+		return fileStore, path
+	}
+
+	readVersions := func(t *testing.T, path string) []string {
+		file, err := os.Open(path)
+		require.NoError(t, err)
+		defer file.Close()
+
+		plugins, err := model.PluginsFromReader(file)
+		require.NoError(t, err)
+
+		var versions []string
+		for _, plugin := range plugins {
+			versions = append(versions, plugin.Manifest.Id+"@"+plugin.Manifest.Version)
+		}
+
+		return versions
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := NewFile(filepath.Join(t.TempDir(), "missing.json"), model.DefaultLabelDefinitions, testlib.MakeLogger(t))
+		require.Error(t, err)
+	})
+
+	t.Run("add plugin", func(t *testing.T) {
+		fileStore, path := setup(t, newPlugin("demo", "1.0.0"))
+		revision := fileStore.Revision()
+
+		require.NoError(t, fileStore.AddPlugin(newPlugin("demo", "2.0.0")))
+		require.NoError(t, fileStore.AddPlugin(newPlugin("another", "1.0.0")))
+
+		assert.Equal(t, []string{"another@1.0.0", "demo@2.0.0", "demo@1.0.0"}, readVersions(t, path))
+		assert.NotEqual(t, revision, fileStore.Revision())
+
+		plugins, err := fileStore.GetPlugins(&model.PluginFilter{PerPage: model.AllPerPage, PluginID: "demo"})
+		require.NoError(t, err)
+		require.Len(t, plugins, 1)
+		assert.Equal(t, "2.0.0", plugins[0].Manifest.Version)
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+	})
+
+	t.Run("add existing plugin", func(t *testing.T) {
+		fileStore, path := setup(t, newPlugin("demo", "1.0.0"))
+
+		err := fileStore.AddPlugin(newPlugin("demo", "1.0.0"))
+		require.True(t, errors.Is(err, model.ErrPluginExists))
+		assert.Equal(t, []string{"demo@1.0.0"}, readVersions(t, path))
+	})
+
+	t.Run("add invalid plugin", func(t *testing.T) {
+		fileStore, path := setup(t, newPlugin("demo", "1.0.0"))
+
+		require.Error(t, fileStore.AddPlugin(newPlugin("demo", "")))
+		require.Error(t, fileStore.AddPlugin(newPlugin("demo", "not-semver")))
+		require.Error(t, fileStore.AddPlugin(&model.Plugin{}))
+		assert.Equal(t, []string{"demo@1.0.0"}, readVersions(t, path))
+	})
+
+	t.Run("update plugin", func(t *testing.T) {
+		fileStore, _ := setup(t, newPlugin("demo", "1.0.0"))
+
+		updated := newPlugin("demo", "1.0.0")
+		updated.ReleaseStage = model.Beta
+		require.NoError(t, fileStore.UpdatePlugin(updated))
+
+		plugins, err := fileStore.GetPlugins(&model.PluginFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, plugins, 1)
+		assert.Equal(t, model.Beta, plugins[0].ReleaseStage)
+
+		err = fileStore.UpdatePlugin(newPlugin("demo", "2.0.0"))
+		require.True(t, errors.Is(err, model.ErrPluginNotFound))
+	})
+
+	t.Run("delete plugin", func(t *testing.T) {
+		fileStore, path := setup(t, newPlugin("demo", "1.0.0"), newPlugin("demo", "2.0.0"), newPlugin("other", "1.0.0"))
+
+		require.NoError(t, fileStore.DeletePlugin("demo", "1.0.0"))
+		assert.Equal(t, []string{"demo@2.0.0", "other@1.0.0"}, readVersions(t, path))
+
+		err := fileStore.DeletePlugin("demo", "1.0.0")
+		require.True(t, errors.Is(err, model.ErrPluginNotFound))
+
+		require.NoError(t, fileStore.DeletePlugin("other", ""))
+		assert.Equal(t, []string{"demo@2.0.0"}, readVersions(t, path))
+	})
+
+	t.Run("written catalog can be reopened", func(t *testing.T) {
+		fileStore, path := setup(t, newPlugin("demo", "1.0.0"))
+		require.NoError(t, fileStore.AddPlugin(newPlugin("demo", "1.1.0")))
+
+		reopened, err := NewFile(path, model.DefaultLabelDefinitions, testlib.MakeLogger(t))
+		require.NoError(t, err)
+		assert.Equal(t, fileStore.Revision(), reopened.Revision())
+	})
+}
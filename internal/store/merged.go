@@ -1,6 +1,9 @@
 package store
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
@@ -32,13 +35,70 @@ func (store *Merged) GetPlugins(pluginFilter *model.PluginFilter) ([]*model.Plug
 		return store.stores[0].GetPlugins(pluginFilter)
 	}
 
+	staticStore, err := store.merge(pluginFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return staticStore.GetPlugins(pluginFilter)
+}
+
+// GetPluginsPage fetches the given page of plugins alongside the total number of matching plugins
+// across all stores.
+func (store *Merged) GetPluginsPage(pluginFilter *model.PluginFilter) (*model.PluginsPage, error) {
+	// Short-circuit if only one store is configured.
+	if len(store.stores) == 1 {
+		return store.stores[0].GetPluginsPage(pluginFilter)
+	}
+
+	staticStore, err := store.merge(pluginFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return staticStore.GetPluginsPage(pluginFilter)
+}
+
+// GetPluginCompatibility explains which versions of the plugin identified by the filter's PluginID
+// match the filter across all stores. Stores unable to explain their plugins are skipped.
+func (store *Merged) GetPluginCompatibility(pluginFilter *model.PluginFilter) (*model.PluginCompatibility, error) {
+	// Each store only checks compatibility, leaving the latest version and facets to be selected
+	// once the versions are combined.
 	filter := *pluginFilter
-	filter.Page = 0
-	filter.PerPage = model.AllPerPage
+	filter.ReturnAllVersions = true
+	filter.AuthorTypes = nil
+	filter.ReleaseStages = nil
+	filter.Hosting = nil
+	filter.Labels = nil
+	filter.Facets = false
+
+	var versions []*model.VersionCompatibility
+	for i, s := range store.stores {
+		explainer, ok := s.(CompatibilityExplainer)
+		if !ok {
+			store.logger.Debugf("store %d cannot explain compatibility", i)
+			continue
+		}
+
+		compatibility, err := explainer.GetPluginCompatibility(&filter)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to query store %d", i)
+		}
+
+		versions = append(versions, compatibility.Versions...)
+	}
+
+	return explainCompatibility(pluginFilter, versions)
+}
+
+// merge queries every store for all plugins matching the filter, returning a static store of the
+// combined results from which the requested page can then be selected.
+func (store *Merged) merge(pluginFilter *model.PluginFilter) (*StaticStore, error) {
+	filter := unpagedFilter(pluginFilter)
 
 	plugins := []*model.Plugin{}
 	for i, store := range store.stores {
-		storePlugins, err := store.GetPlugins(&filter)
+		storePlugins, err := store.GetPlugins(filter)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to query store %d", i)
 		}
@@ -46,10 +106,55 @@ func (store *Merged) GetPlugins(pluginFilter *model.PluginFilter) ([]*model.Plug
 		plugins = append(plugins, storePlugins...)
 	}
 
-	staticStore, err := NewStatic(plugins, store.logger)
+	// The plugins already carry the labels assigned by the wrapped stores.
+	staticStore, err := NewStatic(plugins, nil, store.logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to initialize static store")
 	}
 
-	return staticStore.GetPlugins(pluginFilter)
+	return staticStore, nil
+}
+
+// unpagedFilter returns a copy of the given filter matching every plugin on any page, from which
+// the requested page can be selected once the results are combined.
+func unpagedFilter(pluginFilter *model.PluginFilter) *model.PluginFilter {
+	filter := *pluginFilter
+	filter.Page = 0
+	filter.PerPage = model.AllPerPage
+	filter.Cursor = ""
+
+	// Facets are counted over plugins that do not match the facet being counted, so the facet
+	// constraints are only applied once the results are combined.
+	if filter.Facets {
+		filter.AuthorTypes = nil
+		filter.ReleaseStages = nil
+		filter.Hosting = nil
+		filter.Labels = nil
+		filter.Facets = false
+	}
+
+	return &filter
+}
+
+// Revision returns a digest combining the revisions of the merged stores.
+//
+// An empty string is returned if any of the merged stores cannot identify its revision, such as
+// when proxying to a remote marketplace.
+func (store *Merged) Revision() string {
+	hash := sha256.New()
+	for _, s := range store.stores {
+		revisioner, ok := s.(Revisioner)
+		if !ok {
+			return ""
+		}
+
+		revision := revisioner.Revision()
+		if revision == "" {
+			return ""
+		}
+
+		_, _ = hash.Write([]byte(revision))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
 }
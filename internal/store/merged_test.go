@@ -1,7 +1,9 @@
 package store
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -134,7 +136,7 @@ func TestMerged(t *testing.T) {
 	t.Run("single empty store", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 
-		static1, err := NewStatic([]*model.Plugin{}, logger)
+		static1, err := NewStatic([]*model.Plugin{}, model.DefaultLabelDefinitions, logger)
 		require.NoError(t, err)
 
 		store := NewMerged(logger, static1)
@@ -152,9 +154,9 @@ func TestMerged(t *testing.T) {
 	t.Run("multiple empty stores", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 
-		static1, err := NewStatic([]*model.Plugin{}, logger)
+		static1, err := NewStatic([]*model.Plugin{}, model.DefaultLabelDefinitions, logger)
 		require.NoError(t, err)
-		static2, err := NewStatic([]*model.Plugin{}, logger)
+		static2, err := NewStatic([]*model.Plugin{}, model.DefaultLabelDefinitions, logger)
 		require.NoError(t, err)
 
 		store := NewMerged(logger, static1, static2)
@@ -172,7 +174,7 @@ func TestMerged(t *testing.T) {
 	t.Run("single, populated store", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 
-		static1, err := NewStatic([]*model.Plugin{plugin1V3, plugin2V1, plugin3V3, plugin4V1}, logger)
+		static1, err := NewStatic([]*model.Plugin{plugin1V3, plugin2V1, plugin3V3, plugin4V1}, model.DefaultLabelDefinitions, logger)
 		require.NoError(t, err)
 
 		store := NewMerged(logger, static1)
@@ -195,9 +197,9 @@ func TestMerged(t *testing.T) {
 	t.Run("conflict-free merge", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 
-		static1, err := NewStatic([]*model.Plugin{plugin1V1, plugin1V2, plugin1V3}, logger)
+		static1, err := NewStatic([]*model.Plugin{plugin1V1, plugin1V2, plugin1V3}, model.DefaultLabelDefinitions, logger)
 		require.NoError(t, err)
-		static2, err := NewStatic([]*model.Plugin{plugin2V1, plugin3V1, plugin3V2, plugin3V3, plugin4V1}, logger)
+		static2, err := NewStatic([]*model.Plugin{plugin2V1, plugin3V1, plugin3V2, plugin3V3, plugin4V1}, model.DefaultLabelDefinitions, logger)
 		require.NoError(t, err)
 
 		store := NewMerged(logger, static1, static2)
@@ -220,9 +222,9 @@ func TestMerged(t *testing.T) {
 	t.Run("newer versions win across stores", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 
-		static1, err := NewStatic([]*model.Plugin{plugin1V1, plugin2V1, plugin3V1, plugin4V1}, logger)
+		static1, err := NewStatic([]*model.Plugin{plugin1V1, plugin2V1, plugin3V1, plugin4V1}, model.DefaultLabelDefinitions, logger)
 		require.NoError(t, err)
-		static2, err := NewStatic([]*model.Plugin{plugin1V3, plugin2V1, plugin3V3, plugin4V1}, logger)
+		static2, err := NewStatic([]*model.Plugin{plugin1V3, plugin2V1, plugin3V3, plugin4V1}, model.DefaultLabelDefinitions, logger)
 		require.NoError(t, err)
 
 		store := NewMerged(logger, static1, static2)
@@ -245,11 +247,11 @@ func TestMerged(t *testing.T) {
 	t.Run("later stores win across versions", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 
-		static1, err := NewStatic([]*model.Plugin{plugin4V1}, logger)
+		static1, err := NewStatic([]*model.Plugin{plugin4V1}, model.DefaultLabelDefinitions, logger)
 		require.NoError(t, err)
-		static2, err := NewStatic([]*model.Plugin{plugin4V1Later}, logger)
+		static2, err := NewStatic([]*model.Plugin{plugin4V1Later}, model.DefaultLabelDefinitions, logger)
 		require.NoError(t, err)
-		static3, err := NewStatic([]*model.Plugin{plugin1V3}, logger)
+		static3, err := NewStatic([]*model.Plugin{plugin1V3}, model.DefaultLabelDefinitions, logger)
 		require.NoError(t, err)
 
 		store := NewMerged(logger, static1, static2, static3)
@@ -267,3 +269,168 @@ func TestMerged(t *testing.T) {
 		}, plugins)
 	})
 }
+
+func TestMergedGetPluginsPage(t *testing.T) {
+	newPlugin := func(id, version string) *model.Plugin {
+		return &model.Plugin{
+			Manifest: &mattermostModel.Manifest{
+				Id:      id,
+				Name:    id,
+				Version: version,
+			},
+		}
+	}
+
+	logger := testlib.MakeLogger(t)
+	static1, err := NewStatic([]*model.Plugin{newPlugin("alpha", "1.0.0"), newPlugin("bravo", "1.0.0")}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+	static2, err := NewStatic([]*model.Plugin{newPlugin("alpha", "2.0.0"), newPlugin("charlie", "1.0.0")}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+
+	store := NewMerged(logger, static1, static2)
+
+	page, err := store.GetPluginsPage(&model.PluginFilter{
+		PerPage: 2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Total)
+	assert.Equal(t, []*model.Plugin{newPlugin("alpha", "2.0.0"), newPlugin("bravo", "1.0.0")}, page.Plugins)
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = store.GetPluginsPage(&model.PluginFilter{
+		PerPage: 2,
+		Cursor:  page.NextCursor,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Total)
+	assert.Equal(t, []*model.Plugin{newPlugin("charlie", "1.0.0")}, page.Plugins)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestMergedGetPluginsFacets(t *testing.T) {
+	newPlugin := func(id string, authorType model.AuthorType) *model.Plugin {
+		return &model.Plugin{
+			Manifest: &mattermostModel.Manifest{
+				Id:      id,
+				Name:    id,
+				Version: "1.0.0",
+			},
+			AuthorType: authorType,
+		}
+	}
+
+	logger := testlib.MakeLogger(t)
+	static1, err := NewStatic([]*model.Plugin{newPlugin("alpha", model.Mattermost), newPlugin("bravo", model.Partner)}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+	static2, err := NewStatic([]*model.Plugin{newPlugin("charlie", model.Mattermost)}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+
+	store := NewMerged(logger, static1, static2)
+
+	page, err := store.GetPluginsPage(&model.PluginFilter{
+		PerPage:     model.AllPerPage,
+		Facets:      true,
+		AuthorTypes: []model.AuthorType{model.Partner},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, page.Total)
+	require.Len(t, page.Plugins, 1)
+	assert.Equal(t, "bravo", page.Plugins[0].Manifest.Id)
+	require.NotNil(t, page.Facets)
+	assert.Equal(t, map[model.AuthorType]int{model.Mattermost: 2, model.Partner: 1}, page.Facets.AuthorType)
+}
+
+func TestMergedGetPluginsSort(t *testing.T) {
+	newPlugin := func(id, version string, updatedAt time.Time) *model.Plugin {
+		return &model.Plugin{
+			Manifest: &mattermostModel.Manifest{
+				Id:      id,
+				Name:    id,
+				Version: version,
+			},
+			UpdatedAt: updatedAt,
+		}
+	}
+
+	alpha := newPlugin("alpha", "1.0.0", time.Date(2020, time.January, 3, 0, 0, 0, 0, time.UTC))
+	bravo := newPlugin("bravo", "1.0.0", time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC))
+	charlie := newPlugin("charlie", "1.0.0", time.Date(2020, time.January, 2, 0, 0, 0, 0, time.UTC))
+
+	logger := testlib.MakeLogger(t)
+	static1, err := NewStatic([]*model.Plugin{alpha, bravo}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+	static2, err := NewStatic([]*model.Plugin{charlie}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+
+	plugins, err := NewMerged(logger, static1, static2).GetPlugins(&model.PluginFilter{
+		PerPage: model.AllPerPage,
+		Sort:    model.SortByUpdatedAt,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Plugin{alpha, charlie, bravo}, plugins)
+}
+
+func TestMergedRevision(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	plugin := &model.Plugin{
+		Manifest: &mattermostModel.Manifest{
+			Id:      "test",
+			Name:    "Test",
+			Version: "0.1.0",
+		},
+	}
+
+	static1, err := NewStatic([]*model.Plugin{plugin}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+	static2, err := NewStatic([]*model.Plugin{}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+
+	t.Run("static stores", func(t *testing.T) {
+		assert.NotEmpty(t, NewMerged(logger, static1, static2).Revision())
+		assert.NotEqual(t, NewMerged(logger, static1, static2).Revision(), NewMerged(logger, static2, static1).Revision())
+	})
+
+	t.Run("proxy store", func(t *testing.T) {
+		proxy, err := NewProxy("http://localhost", logger)
+		require.NoError(t, err)
+
+		assert.Empty(t, NewMerged(logger, static1, proxy).Revision())
+	})
+}
+
+func BenchmarkMergedGetPlugins(b *testing.B) {
+	logger := testlib.MakeLogger(b)
+
+	makeStatic := func(prefix string) *StaticStore {
+		plugins := make([]*model.Plugin, 0, 500)
+		for i := 0; i < 500; i++ {
+			id := fmt.Sprintf("%s-plugin-%d", prefix, i)
+			plugins = append(plugins, &model.Plugin{
+				HomepageURL: "https://github.com/mattermost/" + id,
+				Manifest: &mattermostModel.Manifest{
+					Id:          id,
+					Name:        id,
+					Description: fmt.Sprintf("The %s integration number %d", prefix, i),
+					Version:     "1.0.0",
+				},
+			})
+		}
+
+		static, err := NewStatic(plugins, model.DefaultLabelDefinitions, logger)
+		require.NoError(b, err)
+
+		return static
+	}
+
+	store := NewMerged(logger, makeStatic("community"), makeStatic("enterprise"))
+
+	for name, filter := range map[string]string{"unfiltered": "", "filtered": "integration 42"} {
+		filter := filter
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, err := store.GetPlugins(&model.PluginFilter{PerPage: 50, Filter: filter})
+				require.NoError(b, err)
+			}
+		})
+	}
+}
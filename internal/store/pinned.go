@@ -0,0 +1,153 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// Pinned is a store that serves a fixed version of some plugins in place of their latest
+// compatible version, such as one approved for use within an organization.
+//
+// A pinned plugin is only returned if its pinned version matches the filter: a newer version is
+// never served instead. Pins are ignored when all versions are requested.
+type Pinned struct {
+	store  Store
+	pins   map[string]string // Versions keyed by plugin id
+	logger logrus.FieldLogger
+}
+
+// NewPinned creates a new instance of a pinned store wrapping the given store, serving the given
+// versions keyed by plugin id.
+func NewPinned(store Store, pins map[string]string, logger logrus.FieldLogger) *Pinned {
+	return &Pinned{
+		store:  store,
+		pins:   pins,
+		logger: logger,
+	}
+}
+
+// GetPlugins fetches the given page of plugins. The first page is 0.
+func (store *Pinned) GetPlugins(pluginFilter *model.PluginFilter) ([]*model.Plugin, error) {
+	page, err := store.GetPluginsPage(pluginFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return page.Plugins, nil
+}
+
+// affects reports whether any pin could change the results of the given filter.
+func (store *Pinned) affects(pluginFilter *model.PluginFilter) bool {
+	if len(store.pins) == 0 || pluginFilter.ReturnAllVersions {
+		return false
+	}
+
+	if pluginFilter.PluginID != "" {
+		_, ok := store.pins[pluginFilter.PluginID]
+		return ok
+	}
+
+	return true
+}
+
+// GetPluginsPage fetches the given page of plugins alongside the total number of matching plugins.
+//
+// Every version of every matching plugin is fetched, and the requested page selected after
+// discarding all but the pinned version of each pinned plugin, so that the latest remaining
+// version of each plugin is the one pinned.
+func (store *Pinned) GetPluginsPage(pluginFilter *model.PluginFilter) (*model.PluginsPage, error) {
+	if !store.affects(pluginFilter) {
+		return store.store.GetPluginsPage(pluginFilter)
+	}
+
+	filter := unpagedFilter(pluginFilter)
+	filter.ReturnAllVersions = true
+
+	plugins, err := store.store.GetPlugins(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	permitted := make([]*model.Plugin, 0, len(plugins))
+	for _, plugin := range plugins {
+		version, ok := store.pins[plugin.Manifest.Id]
+		if !ok || version == plugin.Manifest.Version {
+			permitted = append(permitted, plugin)
+		}
+	}
+
+	// The plugins already carry the labels assigned by the wrapped store.
+	staticStore, err := NewStatic(permitted, nil, store.logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to initialize static store")
+	}
+
+	return staticStore.GetPluginsPage(pluginFilter)
+}
+
+// GetPluginCompatibility explains which versions of the plugin identified by the filter's PluginID
+// match the filter. Unless all versions are requested, compatible versions of a pinned plugin
+// other than the pinned version are explained as excluded by the pin.
+func (store *Pinned) GetPluginCompatibility(pluginFilter *model.PluginFilter) (*model.PluginCompatibility, error) {
+	explainer, ok := store.store.(CompatibilityExplainer)
+	if !ok {
+		return nil, errors.New("pinned store cannot explain compatibility")
+	}
+
+	if !store.affects(pluginFilter) {
+		return explainer.GetPluginCompatibility(pluginFilter)
+	}
+
+	filter := *pluginFilter
+	filter.ReturnAllVersions = true
+
+	compatibility, err := explainer.GetPluginCompatibility(&filter)
+	if err != nil {
+		return nil, err
+	}
+
+	pinnedVersion := store.pins[pluginFilter.PluginID]
+	for _, version := range compatibility.Versions {
+		if version.Compatible && version.Version != pinnedVersion {
+			version.Rule = model.RulePinned
+			version.Compatible = false
+			version.Reason = describeRule(version, pluginFilter)
+		}
+	}
+
+	return compatibility, nil
+}
+
+// Revision returns a digest of the revision of the wrapped store and the pins.
+func (store *Pinned) Revision() string {
+	revisioner, ok := store.store.(Revisioner)
+	if !ok {
+		return ""
+	}
+
+	revision := revisioner.Revision()
+	if revision == "" {
+		return ""
+	}
+
+	pluginIDs := make([]string, 0, len(store.pins))
+	for pluginID := range store.pins {
+		pluginIDs = append(pluginIDs, pluginID)
+	}
+	sort.Strings(pluginIDs)
+
+	hash := sha256.New()
+	_, _ = hash.Write([]byte(revision))
+	for _, pluginID := range pluginIDs {
+		_, _ = hash.Write([]byte{0})
+		_, _ = hash.Write([]byte(pluginID + "@" + store.pins[pluginID]))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
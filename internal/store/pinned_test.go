@@ -0,0 +1,122 @@
+package store
+
+import (
+	"testing"
+
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/testlib"
+)
+
+func TestPinned(t *testing.T) {
+	newPlugin := func(id, version, minServerVersion string) *model.Plugin {
+		return &model.Plugin{
+			Manifest: &mattermostModel.Manifest{
+				Id:               id,
+				Name:             id,
+				Version:          version,
+				MinServerVersion: minServerVersion,
+			},
+		}
+	}
+
+	versions := func(plugins []*model.Plugin) []string {
+		result := []string{}
+		for _, plugin := range plugins {
+			result = append(result, plugin.Manifest.Id+"@"+plugin.Manifest.Version)
+		}
+
+		return result
+	}
+
+	logger := testlib.MakeLogger(t)
+	staticStore, err := NewStatic([]*model.Plugin{
+		newPlugin("jira", "1.0.0", ""),
+		newPlugin("jira", "2.0.0", ""),
+		newPlugin("jira", "3.0.0", ""),
+		newPlugin("github", "1.0.0", ""),
+		newPlugin("github", "2.0.0", "6.0.0"),
+		newPlugin("zoom", "1.0.0", ""),
+	}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+
+	pinned := NewPinned(staticStore, map[string]string{
+		"jira":   "2.0.0",
+		"github": "2.0.0",
+	}, logger)
+
+	byID := &model.PluginFilter{PerPage: model.AllPerPage, Sort: model.SortByID}
+
+	t.Run("pinned versions replace the latest version", func(t *testing.T) {
+		plugins, err := pinned.GetPlugins(byID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"github@2.0.0", "jira@2.0.0", "zoom@1.0.0"}, versions(plugins))
+
+		filter := *byID
+		filter.PluginID = "jira"
+		plugins, err = pinned.GetPlugins(&filter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"jira@2.0.0"}, versions(plugins))
+	})
+
+	t.Run("incompatible pinned versions are not replaced", func(t *testing.T) {
+		filter := *byID
+		filter.ServerVersion = "5.0.0"
+
+		plugins, err := pinned.GetPlugins(&filter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"jira@2.0.0", "zoom@1.0.0"}, versions(plugins))
+	})
+
+	t.Run("pins are ignored when all versions are requested", func(t *testing.T) {
+		filter := *byID
+		filter.ReturnAllVersions = true
+		filter.PluginID = "jira"
+
+		plugins, err := pinned.GetPlugins(&filter)
+		require.NoError(t, err)
+		assert.Len(t, plugins, 3)
+	})
+
+	t.Run("pages and totals", func(t *testing.T) {
+		filter := *byID
+		filter.PerPage = 2
+		filter.Page = 1
+
+		page, err := pinned.GetPluginsPage(&filter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"zoom@1.0.0"}, versions(page.Plugins))
+		assert.Equal(t, 3, page.Total)
+	})
+
+	t.Run("compatibility explains the pin", func(t *testing.T) {
+		compatibility, err := pinned.GetPluginCompatibility(&model.PluginFilter{PluginID: "jira"})
+		require.NoError(t, err)
+		require.Len(t, compatibility.Versions, 3)
+
+		rules := map[string]model.CompatibilityRule{}
+		for _, version := range compatibility.Versions {
+			rules[version.Version] = version.Rule
+			assert.Equal(t, version.Rule.Includes(), version.Compatible)
+		}
+		assert.Equal(t, map[string]model.CompatibilityRule{
+			"3.0.0": model.RulePinned,
+			"2.0.0": model.RuleCompatible,
+			"1.0.0": model.RulePinned,
+		}, rules)
+
+		compatibility, err = pinned.GetPluginCompatibility(&model.PluginFilter{PluginID: "zoom"})
+		require.NoError(t, err)
+		require.Len(t, compatibility.Versions, 1)
+		assert.True(t, compatibility.Versions[0].Compatible)
+	})
+
+	t.Run("revision depends on pins", func(t *testing.T) {
+		assert.NotEmpty(t, pinned.Revision())
+		assert.NotEqual(t, staticStore.Revision(), pinned.Revision())
+		assert.NotEqual(t, pinned.Revision(), NewPinned(staticStore, map[string]string{"jira": "1.0.0"}, logger).Revision())
+	})
+}
@@ -9,24 +9,71 @@ import (
 )
 
 // Proxy is a store that fetches its result from some remote marketplace server.
+//
+// A single client is shared by every request, reusing connections to the remote server.
 type Proxy struct {
 	marketplaceURL string
+	client         *api.Client
 	logger         logrus.FieldLogger
 }
 
-// NewProxy creates a new instance of a proxy store.
-func NewProxy(marketplaceURL string, logger logrus.FieldLogger) (*Proxy, error) {
+// NewProxy creates a new instance of a proxy store, configuring its client with the given options.
+func NewProxy(marketplaceURL string, logger logrus.FieldLogger, options ...api.ClientOption) (*Proxy, error) {
 	return &Proxy{
 		marketplaceURL: marketplaceURL,
+		client:         api.NewClient(marketplaceURL, options...),
 		logger:         logger.WithField("marketplace_url", marketplaceURL),
 	}, nil
 }
 
 // GetPlugins fetches the given page of plugins. The first page is 0.
 func (store *Proxy) GetPlugins(pluginFilter *model.PluginFilter) ([]*model.Plugin, error) {
-	client := api.NewClient(store.marketplaceURL)
+	// Facets are only returned alongside a page.
+	request := newGetPluginsRequest(pluginFilter)
+	request.Facets = false
 
-	plugins, err := client.GetPlugins(&api.GetPluginsRequest{
+	plugins, err := store.client.GetPlugins(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to reach upstream store")
+	}
+
+	return plugins, nil
+}
+
+// GetPluginsPage fetches the given page of plugins alongside the total number of matching plugins
+// reported by the upstream server.
+func (store *Proxy) GetPluginsPage(pluginFilter *model.PluginFilter) (*model.PluginsPage, error) {
+	response, err := store.client.GetPluginsPage(newGetPluginsRequest(pluginFilter))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to reach upstream store")
+	}
+
+	return &model.PluginsPage{
+		Plugins:    response.Plugins,
+		Total:      response.Total,
+		NextCursor: response.NextCursor,
+		Facets:     response.Facets,
+	}, nil
+}
+
+// GetPluginCompatibility explains which versions of the plugin identified by the filter's PluginID
+// match the filter, as reported by the upstream server.
+func (store *Proxy) GetPluginCompatibility(pluginFilter *model.PluginFilter) (*model.PluginCompatibility, error) {
+	request := newGetPluginsRequest(pluginFilter)
+	request.PluginID = ""
+	request.Facets = false
+
+	compatibility, err := store.client.GetPluginCompatibility(request, pluginFilter.PluginID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to reach upstream store")
+	}
+
+	return compatibility, nil
+}
+
+// newGetPluginsRequest translates the given filter into a request to the upstream server.
+func newGetPluginsRequest(pluginFilter *model.PluginFilter) *api.GetPluginsRequest {
+	return &api.GetPluginsRequest{
 		Page:              pluginFilter.Page,
 		PerPage:           pluginFilter.PerPage,
 		Filter:            pluginFilter.Filter,
@@ -35,11 +82,15 @@ func (store *Proxy) GetPlugins(pluginFilter *model.PluginFilter) ([]*model.Plugi
 		Cloud:             pluginFilter.Cloud,
 		Platform:          pluginFilter.Platform,
 		ReturnAllVersions: pluginFilter.ReturnAllVersions,
+		IncludeYanked:     pluginFilter.IncludeYanked,
 		PluginID:          pluginFilter.PluginID,
-	})
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to reach upstream store")
+		Cursor:            pluginFilter.Cursor,
+		Sort:              pluginFilter.Sort,
+		SortDirection:     pluginFilter.SortDirection,
+		AuthorTypes:       pluginFilter.AuthorTypes,
+		ReleaseStages:     pluginFilter.ReleaseStages,
+		Hosting:           pluginFilter.Hosting,
+		Labels:            pluginFilter.Labels,
+		Facets:            pluginFilter.Facets,
 	}
-
-	return plugins, nil
 }
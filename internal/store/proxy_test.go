@@ -92,6 +92,12 @@ func TestProxyGetPlugins(t *testing.T) {
 			assert.Equal(t, "linux-amd64", filter.Platform)
 			assert.Equal(t, true, filter.ReturnAllVersions)
 			assert.Equal(t, "demo", filter.PluginID)
+			assert.Equal(t, model.SortByUpdatedAt, filter.Sort)
+			assert.Equal(t, model.SortAscending, filter.SortDirection)
+			assert.Equal(t, []model.AuthorType{model.Mattermost, model.Partner}, filter.AuthorTypes)
+			assert.Equal(t, []model.ReleaseStage{model.Production}, filter.ReleaseStages)
+			assert.Equal(t, []model.HostingType{model.Cloud}, filter.Hosting)
+			assert.Equal(t, []string{"Enterprise"}, filter.Labels)
 
 			w.WriteHeader(http.StatusOK)
 			_, err = w.Write([]byte(`[{"homepage_url":"https://github.com/mattermost/mattermost-plugin-demo","icon_data":"icon-data.svg","download_url":"https://github.com/mattermost/mattermost-plugin-demo/releases/download/v0.1.0/com.mattermost.demo-plugin-0.1.0.tar.gz","signature":"signature1", "release_notes_url":"https://github.com/mattermost/mattermost-plugin-demo/releases/v0.1.0","manifest":{}}]`))
@@ -112,6 +118,12 @@ func TestProxyGetPlugins(t *testing.T) {
 			Platform:          "linux-amd64",
 			ReturnAllVersions: true,
 			PluginID:          "demo",
+			Sort:              model.SortByUpdatedAt,
+			SortDirection:     model.SortAscending,
+			AuthorTypes:       []model.AuthorType{model.Mattermost, model.Partner},
+			ReleaseStages:     []model.ReleaseStage{model.Production},
+			Hosting:           []model.HostingType{model.Cloud},
+			Labels:            []string{"Enterprise"},
 		})
 		require.NoError(t, err)
 		require.Equal(t, []*model.Plugin{{
@@ -123,4 +135,108 @@ func TestProxyGetPlugins(t *testing.T) {
 			Manifest:        &mattermostModel.Manifest{},
 		}}, plugins)
 	})
+
+	t.Run("page with envelope", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "true", r.URL.Query().Get("envelope"))
+			assert.Equal(t, "some-cursor", r.URL.Query().Get("cursor"))
+
+			w.WriteHeader(http.StatusOK)
+			_, err := w.Write([]byte(`{"plugins":[{"manifest":{"id":"demo"}}],"total":12,"page":0,"per_page":1,"next_cursor":"next-cursor"}`))
+			require.NoError(t, err)
+		}))
+		t.Cleanup(ts.Close)
+
+		proxyStore, err := NewProxy(ts.URL, logger)
+		require.NoError(t, err)
+
+		page, err := proxyStore.GetPluginsPage(&model.PluginFilter{
+			PerPage: 1,
+			Cursor:  "some-cursor",
+		})
+		require.NoError(t, err)
+		require.Equal(t, &model.PluginsPage{
+			Plugins:    []*model.Plugin{{Manifest: &mattermostModel.Manifest{Id: "demo"}}},
+			Total:      12,
+			NextCursor: "next-cursor",
+		}, page)
+	})
+
+	t.Run("page with facets", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "true", r.URL.Query().Get("envelope"))
+			assert.Equal(t, "true", r.URL.Query().Get("facets"))
+
+			w.WriteHeader(http.StatusOK)
+			_, err := w.Write([]byte(`{"plugins":[],"total":3,"page":0,"per_page":0,"facets":{"author_type":{"mattermost":2,"community":1},"label":{"Community":1}}}`))
+			require.NoError(t, err)
+		}))
+		t.Cleanup(ts.Close)
+
+		proxyStore, err := NewProxy(ts.URL, logger)
+		require.NoError(t, err)
+
+		page, err := proxyStore.GetPluginsPage(&model.PluginFilter{
+			PerPage: 0,
+			Facets:  true,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, page.Facets)
+		assert.Equal(t, map[model.AuthorType]int{model.Mattermost: 2, model.Community: 1}, page.Facets.AuthorType)
+		assert.Equal(t, map[string]int{"Community": 1}, page.Facets.Label)
+	})
+
+	t.Run("page without envelope support", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_, err := w.Write([]byte(`[{"manifest":{"id":"demo"}}]`))
+			require.NoError(t, err)
+		}))
+		t.Cleanup(ts.Close)
+
+		proxyStore, err := NewProxy(ts.URL, logger)
+		require.NoError(t, err)
+
+		page, err := proxyStore.GetPluginsPage(&model.PluginFilter{
+			PerPage: model.AllPerPage,
+		})
+		require.NoError(t, err)
+		require.Equal(t, &model.PluginsPage{
+			Plugins: []*model.Plugin{{Manifest: &mattermostModel.Manifest{Id: "demo"}}},
+			Total:   -1,
+		}, page)
+	})
+
+	t.Run("compatibility", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v1/plugins/demo/compatibility", r.URL.Path)
+			assert.Equal(t, "5.20.0", r.URL.Query().Get("server_version"))
+
+			w.WriteHeader(http.StatusOK)
+			_, err := w.Write([]byte(`{"plugin_id":"demo","versions":[{"version":"1.0.0","compatible":false,"rule":"min_server_version","reason":"requires server 5.30.0 or later"}]}`))
+			require.NoError(t, err)
+		}))
+		t.Cleanup(ts.Close)
+
+		proxyStore, err := NewProxy(ts.URL, logger)
+		require.NoError(t, err)
+
+		compatibility, err := proxyStore.GetPluginCompatibility(&model.PluginFilter{
+			PluginID:      "demo",
+			ServerVersion: "5.20.0",
+		})
+		require.NoError(t, err)
+		require.Equal(t, &model.PluginCompatibility{
+			PluginID: "demo",
+			Versions: []*model.VersionCompatibility{{
+				Version: "1.0.0",
+				Rule:    model.RuleMinServerVersion,
+				Reason:  "requires server 5.30.0 or later",
+			}},
+		}, compatibility)
+	})
 }
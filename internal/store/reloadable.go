@@ -0,0 +1,130 @@
+package store
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// Reloadable is a store that delegates to a store constructed on demand, allowing the catalog to
+// be replaced while serving, such as after regenerating the backing database.
+type Reloadable struct {
+	load func() (Store, error)
+
+	lock  sync.RWMutex
+	store Store
+}
+
+// NewReloadable creates a new instance of a reloadable store, loading the initial store from the
+// given function.
+func NewReloadable(load func() (Store, error)) (*Reloadable, error) {
+	store := &Reloadable{
+		load: load,
+	}
+
+	err := store.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Reload replaces the current store with a newly loaded one. On failure, the current store is
+// retained.
+func (store *Reloadable) Reload() error {
+	loaded, err := store.load()
+	if err != nil {
+		return errors.Wrap(err, "failed to load store")
+	}
+
+	store.lock.Lock()
+	store.store = loaded
+	store.lock.Unlock()
+
+	return nil
+}
+
+// current returns the most recently loaded store.
+func (store *Reloadable) current() Store {
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	return store.store
+}
+
+// GetPlugins fetches the given page of plugins. The first page is 0.
+func (store *Reloadable) GetPlugins(pluginFilter *model.PluginFilter) ([]*model.Plugin, error) {
+	return store.current().GetPlugins(pluginFilter)
+}
+
+// GetPluginsPage fetches the given page of plugins alongside the total number of matching plugins.
+func (store *Reloadable) GetPluginsPage(pluginFilter *model.PluginFilter) (*model.PluginsPage, error) {
+	return store.current().GetPluginsPage(pluginFilter)
+}
+
+// GetPluginCompatibility explains which versions of the plugin identified by the filter's
+// PluginID match the filter, if supported by the current store.
+func (store *Reloadable) GetPluginCompatibility(pluginFilter *model.PluginFilter) (*model.PluginCompatibility, error) {
+	explainer, ok := store.current().(CompatibilityExplainer)
+	if !ok {
+		return nil, errors.New("reloadable store cannot explain compatibility")
+	}
+
+	return explainer.GetPluginCompatibility(pluginFilter)
+}
+
+// AddPlugin adds a new version of a plugin to the catalog, if the current store is writable.
+func (store *Reloadable) AddPlugin(plugin *model.Plugin) error {
+	writable, err := store.writable()
+	if err != nil {
+		return err
+	}
+
+	return writable.AddPlugin(plugin)
+}
+
+// UpdatePlugin replaces an existing version of a plugin in the catalog, if the current store is
+// writable.
+func (store *Reloadable) UpdatePlugin(plugin *model.Plugin) error {
+	writable, err := store.writable()
+	if err != nil {
+		return err
+	}
+
+	return writable.UpdatePlugin(plugin)
+}
+
+// DeletePlugin removes a version of a plugin from the catalog, or every version if the given
+// version is empty, if the current store is writable.
+func (store *Reloadable) DeletePlugin(pluginID, version string) error {
+	writable, err := store.writable()
+	if err != nil {
+		return err
+	}
+
+	return writable.DeletePlugin(pluginID, version)
+}
+
+// writable returns the current store if it is writable.
+func (store *Reloadable) writable() (WritableStore, error) {
+	writable, ok := store.current().(WritableStore)
+	if !ok {
+		return nil, errors.New("reloadable store is not writable")
+	}
+
+	return writable, nil
+}
+
+// Revision returns the revision of the current store, changing whenever a reload changes the
+// catalog.
+func (store *Reloadable) Revision() string {
+	revisioner, ok := store.current().(Revisioner)
+	if !ok {
+		return ""
+	}
+
+	return revisioner.Revision()
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"testing"
+
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/testlib"
+)
+
+func TestReloadable(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	newStore := func(version string) Store {
+		staticStore, err := NewStatic([]*model.Plugin{{
+			Manifest: &mattermostModel.Manifest{Id: "demo", Name: "demo", Version: version},
+		}}, model.DefaultLabelDefinitions, logger)
+		require.NoError(t, err)
+
+		return staticStore
+	}
+
+	versions := []string{"1.0.0", "2.0.0"}
+	var loadErr error
+	reloadable, err := NewReloadable(func() (Store, error) {
+		if loadErr != nil {
+			return nil, loadErr
+		}
+
+		version := versions[0]
+		versions = versions[1:]
+		return newStore(version), nil
+	})
+	require.NoError(t, err)
+
+	latestVersion := func() string {
+		plugins, err := reloadable.GetPlugins(&model.PluginFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, plugins, 1)
+
+		return plugins[0].Manifest.Version
+	}
+
+	assert.Equal(t, "1.0.0", latestVersion())
+	revision := reloadable.Revision()
+	assert.NotEmpty(t, revision)
+
+	require.NoError(t, reloadable.Reload())
+	assert.Equal(t, "2.0.0", latestVersion())
+	assert.NotEqual(t, revision, reloadable.Revision())
+
+	t.Run("failed reload retains the current store", func(t *testing.T) {
+		loadErr = errors.New("failed")
+		require.Error(t, reloadable.Reload())
+		assert.Equal(t, "2.0.0", latestVersion())
+	})
+
+	t.Run("failed initial load", func(t *testing.T) {
+		_, err := NewReloadable(func() (Store, error) {
+			return nil, errors.New("failed")
+		})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,269 @@
+package store
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// Restricted is a store that only ever returns plugins matching a fixed set of facets, regardless
+// of the filter requested. This allows an operator to enforce a policy, such as only serving
+// production plugins, for every client of the marketplace.
+type Restricted struct {
+	store        Store
+	restrictions *model.PluginFilter
+}
+
+// NewRestricted creates a new instance of a restricted store wrapping the given store.
+//
+// Only the AuthorTypes, ReleaseStages, Hosting and Labels facets of the restrictions are honoured.
+func NewRestricted(store Store, restrictions *model.PluginFilter) *Restricted {
+	return &Restricted{
+		store:        store,
+		restrictions: restrictions,
+	}
+}
+
+// PluginVisible reports whether any version of the plugin with the given id is permitted by the
+// restrictions, and may be seen through any store it wraps. A plugin whose versions cannot be
+// fetched is treated as hidden.
+func (store *Restricted) PluginVisible(pluginID string) bool {
+	if wrapped, ok := store.store.(pluginVisibility); ok && !wrapped.PluginVisible(pluginID) {
+		return false
+	}
+
+	for _, cloud := range []bool{false, true} {
+		filter, ok := store.restrict(&model.PluginFilter{
+			PerPage:           model.AllPerPage,
+			PluginID:          pluginID,
+			EnterprisePlugins: true,
+			Cloud:             cloud,
+			ReturnAllVersions: true,
+			IncludeYanked:     true,
+		})
+		if !ok {
+			return false
+		}
+
+		plugins, err := store.store.GetPlugins(filter)
+		if err == nil && len(plugins) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetPlugins fetches the given page of plugins. The first page is 0.
+func (store *Restricted) GetPlugins(pluginFilter *model.PluginFilter) ([]*model.Plugin, error) {
+	filter, ok := store.restrict(pluginFilter)
+	if !ok {
+		return nil, nil
+	}
+
+	return store.store.GetPlugins(filter)
+}
+
+// GetPluginsPage fetches the given page of plugins alongside the total number of matching plugins.
+func (store *Restricted) GetPluginsPage(pluginFilter *model.PluginFilter) (*model.PluginsPage, error) {
+	filter, ok := store.restrict(pluginFilter)
+	if !ok {
+		page := &model.PluginsPage{}
+		if pluginFilter.Facets {
+			page.Facets = model.NewPluginFacets()
+		}
+
+		return page, nil
+	}
+
+	page, err := store.store.GetPluginsPage(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.Facets != nil {
+		store.restrictFacets(page.Facets)
+	}
+
+	return page, nil
+}
+
+// GetPluginCompatibility explains which versions of the plugin identified by the filter's PluginID
+// match the filter. Versions not permitted by the restrictions are omitted entirely, rather than
+// explained, so as not to reveal them.
+func (store *Restricted) GetPluginCompatibility(pluginFilter *model.PluginFilter) (*model.PluginCompatibility, error) {
+	explainer, ok := store.store.(CompatibilityExplainer)
+	if !ok {
+		return nil, errors.New("restricted store cannot explain compatibility")
+	}
+
+	result := &model.PluginCompatibility{
+		PluginID: pluginFilter.PluginID,
+		Versions: []*model.VersionCompatibility{},
+	}
+
+	filter, ok := store.restrict(pluginFilter)
+	if !ok {
+		return result, nil
+	}
+
+	compatibility, err := explainer.GetPluginCompatibility(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, version := range compatibility.Versions {
+		if pluginMatchesFacets(version.Plugin, store.restrictions) {
+			result.Versions = append(result.Versions, version)
+		}
+	}
+
+	return result, nil
+}
+
+// restrictFacets omits the counts of any facet value not permitted by the restrictions. Since each
+// facet is counted ignoring its own constraint, these would otherwise reveal restricted plugins.
+func (store *Restricted) restrictFacets(facets *model.PluginFacets) {
+	restrictions := store.restrictions
+
+	if len(restrictions.AuthorTypes) > 0 {
+		permitted := make(map[string]bool)
+		for _, authorType := range restrictions.AuthorTypes {
+			permitted[strings.ToLower(string(authorType))] = true
+		}
+
+		for authorType := range facets.AuthorType {
+			if !permitted[strings.ToLower(string(authorType))] {
+				delete(facets.AuthorType, authorType)
+			}
+		}
+	}
+
+	if len(restrictions.ReleaseStages) > 0 {
+		permitted := make(map[string]bool)
+		for _, releaseStage := range restrictions.ReleaseStages {
+			permitted[strings.ToLower(string(releaseStage))] = true
+		}
+
+		for releaseStage := range facets.ReleaseStage {
+			if !permitted[strings.ToLower(string(releaseStage))] {
+				delete(facets.ReleaseStage, releaseStage)
+			}
+		}
+	}
+
+	if len(restrictions.Hosting) > 0 {
+		permitted := make(map[string]bool)
+		for _, hosting := range restrictions.Hosting {
+			permitted[strings.ToLower(string(hosting))] = true
+		}
+
+		for hosting := range facets.Hosting {
+			if !permitted[strings.ToLower(string(hosting))] {
+				delete(facets.Hosting, hosting)
+			}
+		}
+	}
+
+	if len(restrictions.Labels) > 0 {
+		permitted := make(map[string]bool)
+		for _, label := range restrictions.Labels {
+			permitted[strings.ToLower(label)] = true
+		}
+
+		for label := range facets.Label {
+			if !permitted[strings.ToLower(label)] {
+				delete(facets.Label, label)
+			}
+		}
+	}
+}
+
+// Revision returns the revision of the wrapped store, since the restrictions never change.
+func (store *Restricted) Revision() string {
+	revisioner, ok := store.store.(Revisioner)
+	if !ok {
+		return ""
+	}
+
+	return revisioner.Revision()
+}
+
+// restrict narrows each facet of the given filter to the values permitted by the restrictions,
+// returning false if a facet no longer permits any value.
+func (store *Restricted) restrict(pluginFilter *model.PluginFilter) (*model.PluginFilter, bool) {
+	filter := *pluginFilter
+	restrictions := store.restrictions
+
+	if len(restrictions.AuthorTypes) > 0 {
+		requested := make(map[string]bool)
+		for _, authorType := range pluginFilter.AuthorTypes {
+			requested[strings.ToLower(string(authorType))] = true
+		}
+
+		filter.AuthorTypes = nil
+		for _, authorType := range restrictions.AuthorTypes {
+			if len(requested) == 0 || requested[strings.ToLower(string(authorType))] {
+				filter.AuthorTypes = append(filter.AuthorTypes, authorType)
+			}
+		}
+		if len(filter.AuthorTypes) == 0 {
+			return nil, false
+		}
+	}
+
+	if len(restrictions.ReleaseStages) > 0 {
+		requested := make(map[string]bool)
+		for _, releaseStage := range pluginFilter.ReleaseStages {
+			requested[strings.ToLower(string(releaseStage))] = true
+		}
+
+		filter.ReleaseStages = nil
+		for _, releaseStage := range restrictions.ReleaseStages {
+			if len(requested) == 0 || requested[strings.ToLower(string(releaseStage))] {
+				filter.ReleaseStages = append(filter.ReleaseStages, releaseStage)
+			}
+		}
+		if len(filter.ReleaseStages) == 0 {
+			return nil, false
+		}
+	}
+
+	if len(restrictions.Hosting) > 0 {
+		requested := make(map[string]bool)
+		for _, hosting := range pluginFilter.Hosting {
+			requested[strings.ToLower(string(hosting))] = true
+		}
+
+		filter.Hosting = nil
+		for _, hosting := range restrictions.Hosting {
+			if len(requested) == 0 || requested[strings.ToLower(string(hosting))] {
+				filter.Hosting = append(filter.Hosting, hosting)
+			}
+		}
+		if len(filter.Hosting) == 0 {
+			return nil, false
+		}
+	}
+
+	if len(restrictions.Labels) > 0 {
+		requested := make(map[string]bool)
+		for _, label := range pluginFilter.Labels {
+			requested[strings.ToLower(label)] = true
+		}
+
+		filter.Labels = nil
+		for _, label := range restrictions.Labels {
+			if len(requested) == 0 || requested[strings.ToLower(label)] {
+				filter.Labels = append(filter.Labels, label)
+			}
+		}
+		if len(filter.Labels) == 0 {
+			return nil, false
+		}
+	}
+
+	return &filter, true
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"testing"
+
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/testlib"
+)
+
+func TestRestricted(t *testing.T) {
+	newPlugin := func(id string, authorType model.AuthorType, releaseStage model.ReleaseStage) *model.Plugin {
+		plugin := &model.Plugin{
+			Manifest: &mattermostModel.Manifest{
+				Id:      id,
+				Name:    id,
+				Version: "1.0.0",
+			},
+			AuthorType:   authorType,
+			ReleaseStage: releaseStage,
+		}
+		plugin.AddLabels(model.DefaultLabelDefinitions)
+
+		return plugin
+	}
+
+	core := newPlugin("core", model.Mattermost, model.Production)
+	partner := newPlugin("partner", model.Partner, model.Production)
+	community := newPlugin("community", model.Community, model.Beta)
+
+	logger := testlib.MakeLogger(t)
+	staticStore, err := NewStatic([]*model.Plugin{core, partner, community}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+
+	restricted := NewRestricted(staticStore, &model.PluginFilter{
+		ReleaseStages: []model.ReleaseStage{model.Production},
+	})
+
+	t.Run("restrictions apply without facets", func(t *testing.T) {
+		plugins, err := restricted.GetPlugins(&model.PluginFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Plugin{core, partner}, plugins)
+	})
+
+	t.Run("requested facets narrow the restrictions", func(t *testing.T) {
+		plugins, err := restricted.GetPlugins(&model.PluginFilter{
+			PerPage:     model.AllPerPage,
+			AuthorTypes: []model.AuthorType{model.Partner},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Plugin{partner}, plugins)
+	})
+
+	t.Run("requested facets cannot widen the restrictions", func(t *testing.T) {
+		plugins, err := restricted.GetPlugins(&model.PluginFilter{
+			PerPage:       model.AllPerPage,
+			ReleaseStages: []model.ReleaseStage{model.Production, model.Beta},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Plugin{core, partner}, plugins)
+
+		page, err := restricted.GetPluginsPage(&model.PluginFilter{
+			PerPage:       model.AllPerPage,
+			ReleaseStages: []model.ReleaseStage{model.Beta},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, page.Plugins)
+		assert.Equal(t, 0, page.Total)
+	})
+
+	t.Run("facets omit restricted values", func(t *testing.T) {
+		page, err := restricted.GetPluginsPage(&model.PluginFilter{
+			PerPage: 0,
+			Facets:  true,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, page.Facets)
+		assert.Equal(t, map[model.ReleaseStage]int{model.Production: 2}, page.Facets.ReleaseStage)
+		assert.Equal(t, map[model.AuthorType]int{model.Mattermost: 1, model.Partner: 1}, page.Facets.AuthorType)
+		assert.Equal(t, map[string]int{"Partner": 1}, page.Facets.Label)
+	})
+
+	t.Run("plugin visibility", func(t *testing.T) {
+		assert.True(t, restricted.PluginVisible("core"))
+		assert.True(t, restricted.PluginVisible("partner"))
+		assert.False(t, restricted.PluginVisible("community"))
+		assert.False(t, restricted.PluginVisible("missing"))
+
+		scoped := NewScoped(staticStore, &Visibility{Private: []string{"partner"}}, logger)
+		assert.False(t, NewRestricted(scoped, restricted.restrictions).PluginVisible("partner"))
+		assert.True(t, NewRestricted(scoped, restricted.restrictions).PluginVisible("core"))
+	})
+
+	t.Run("revision", func(t *testing.T) {
+		assert.Equal(t, staticStore.Revision(), restricted.Revision())
+	})
+}
@@ -0,0 +1,162 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// Visibility describes which private plugins may be seen by a caller of the marketplace.
+//
+// Plugins are identified by patterns matching their id, using the syntax of path.Match.
+type Visibility struct {
+	Private []string // Plugins hidden from every caller not granted access
+	Granted []string // Private plugins this caller may nonetheless see
+}
+
+// PluginVisible reports whether the plugin with the given id may be seen.
+func (v *Visibility) PluginVisible(pluginID string) bool {
+	return !matchesAny(v.Private, pluginID) || matchesAny(v.Granted, pluginID)
+}
+
+// matchesAny reports whether the given plugin id matches any of the given patterns. Malformed
+// patterns match nothing.
+func matchesAny(patterns []string, pluginID string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, pluginID); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pluginVisibility describes a store hiding some plugins from its callers.
+type pluginVisibility interface {
+	PluginVisible(pluginID string) bool
+}
+
+// Scoped is a store that hides private plugins from callers not granted access to them, as
+// described by a Visibility. Since every version of a plugin is hidden together, the latest
+// version of each visible plugin is unaffected.
+type Scoped struct {
+	store      Store
+	visibility *Visibility
+	logger     logrus.FieldLogger
+}
+
+// NewScoped creates a new instance of a scoped store wrapping the given store.
+func NewScoped(store Store, visibility *Visibility, logger logrus.FieldLogger) *Scoped {
+	return &Scoped{
+		store:      store,
+		visibility: visibility,
+		logger:     logger,
+	}
+}
+
+// PluginVisible reports whether the plugin with the given id may be seen through this store,
+// including through any scoped store it wraps.
+func (store *Scoped) PluginVisible(pluginID string) bool {
+	if wrapped, ok := store.store.(pluginVisibility); ok && !wrapped.PluginVisible(pluginID) {
+		return false
+	}
+
+	return store.visibility.PluginVisible(pluginID)
+}
+
+// GetPlugins fetches the given page of plugins. The first page is 0.
+func (store *Scoped) GetPlugins(pluginFilter *model.PluginFilter) ([]*model.Plugin, error) {
+	page, err := store.GetPluginsPage(pluginFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return page.Plugins, nil
+}
+
+// GetPluginsPage fetches the given page of visible plugins alongside the total number of matching
+// visible plugins.
+//
+// Unless the wrapped store could not return any private plugin, every matching plugin is fetched
+// and the requested page selected from those visible, keeping pages, totals and facets consistent.
+func (store *Scoped) GetPluginsPage(pluginFilter *model.PluginFilter) (*model.PluginsPage, error) {
+	if pluginFilter.PluginID != "" && !store.PluginVisible(pluginFilter.PluginID) {
+		page := &model.PluginsPage{Plugins: []*model.Plugin{}}
+		if pluginFilter.Facets {
+			page.Facets = model.NewPluginFacets()
+		}
+
+		return page, nil
+	}
+
+	if len(store.visibility.Private) == 0 || pluginFilter.PluginID != "" {
+		return store.store.GetPluginsPage(pluginFilter)
+	}
+
+	plugins, err := store.store.GetPlugins(unpagedFilter(pluginFilter))
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]*model.Plugin, 0, len(plugins))
+	for _, plugin := range plugins {
+		if store.PluginVisible(plugin.Manifest.Id) {
+			visible = append(visible, plugin)
+		}
+	}
+
+	// The plugins already carry the labels assigned by the wrapped store.
+	staticStore, err := NewStatic(visible, nil, store.logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to initialize static store")
+	}
+
+	return staticStore.GetPluginsPage(pluginFilter)
+}
+
+// GetPluginCompatibility explains which versions of the plugin identified by the filter's PluginID
+// match the filter. No versions are explained for a hidden plugin, so as not to reveal it.
+func (store *Scoped) GetPluginCompatibility(pluginFilter *model.PluginFilter) (*model.PluginCompatibility, error) {
+	if !store.PluginVisible(pluginFilter.PluginID) {
+		return &model.PluginCompatibility{
+			PluginID: pluginFilter.PluginID,
+			Versions: []*model.VersionCompatibility{},
+		}, nil
+	}
+
+	explainer, ok := store.store.(CompatibilityExplainer)
+	if !ok {
+		return nil, errors.New("scoped store cannot explain compatibility")
+	}
+
+	return explainer.GetPluginCompatibility(pluginFilter)
+}
+
+// Revision returns a digest of the revision of the wrapped store and the visibility, since the
+// same catalog yields different results to callers granted different access.
+func (store *Scoped) Revision() string {
+	revisioner, ok := store.store.(Revisioner)
+	if !ok {
+		return ""
+	}
+
+	revision := revisioner.Revision()
+	if revision == "" {
+		return ""
+	}
+
+	hash := sha256.New()
+	_, _ = hash.Write([]byte(revision))
+	_, _ = hash.Write([]byte{0})
+	_, _ = hash.Write([]byte(strings.Join(store.visibility.Private, "\n")))
+	_, _ = hash.Write([]byte{0})
+	_, _ = hash.Write([]byte(strings.Join(store.visibility.Granted, "\n")))
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,132 @@
+package store
+
+import (
+	"testing"
+
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/testlib"
+)
+
+func TestScoped(t *testing.T) {
+	newPlugin := func(id, version string) *model.Plugin {
+		plugin := &model.Plugin{
+			Manifest: &mattermostModel.Manifest{
+				Id:      id,
+				Name:    id,
+				Version: version,
+			},
+			AuthorType: model.Community,
+		}
+		plugin.AddLabels(model.DefaultLabelDefinitions)
+
+		return plugin
+	}
+
+	ids := func(plugins []*model.Plugin) []string {
+		result := []string{}
+		for _, plugin := range plugins {
+			result = append(result, plugin.Manifest.Id+"@"+plugin.Manifest.Version)
+		}
+
+		return result
+	}
+
+	logger := testlib.MakeLogger(t)
+	staticStore, err := NewStatic([]*model.Plugin{
+		newPlugin("a-public", "1.0.0"),
+		newPlugin("b-acme-internal", "1.0.0"),
+		newPlugin("b-acme-internal", "2.0.0"),
+		newPlugin("c-public", "1.0.0"),
+		newPlugin("d-globex-internal", "1.0.0"),
+		newPlugin("e-public", "1.0.0"),
+	}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+
+	private := []string{"*-acme-*", "*-globex-*"}
+	anonymous := NewScoped(staticStore, &Visibility{Private: private}, logger)
+	acme := NewScoped(staticStore, &Visibility{Private: private, Granted: []string{"b-acme-*"}}, logger)
+	everything := NewScoped(staticStore, &Visibility{Private: private, Granted: []string{"*"}}, logger)
+	unscoped := NewScoped(staticStore, &Visibility{}, logger)
+
+	byName := &model.PluginFilter{PerPage: model.AllPerPage, Sort: model.SortByID}
+
+	t.Run("visibility", func(t *testing.T) {
+		assert.True(t, anonymous.PluginVisible("a-public"))
+		assert.False(t, anonymous.PluginVisible("b-acme-internal"))
+		assert.True(t, acme.PluginVisible("b-acme-internal"))
+		assert.False(t, acme.PluginVisible("d-globex-internal"))
+		assert.True(t, everything.PluginVisible("d-globex-internal"))
+		assert.True(t, unscoped.PluginVisible("d-globex-internal"))
+	})
+
+	t.Run("private plugins are hidden unless granted", func(t *testing.T) {
+		plugins, err := anonymous.GetPlugins(byName)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a-public@1.0.0", "c-public@1.0.0", "e-public@1.0.0"}, ids(plugins))
+
+		plugins, err = acme.GetPlugins(byName)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a-public@1.0.0", "b-acme-internal@2.0.0", "c-public@1.0.0", "e-public@1.0.0"}, ids(plugins))
+
+		plugins, err = everything.GetPlugins(byName)
+		require.NoError(t, err)
+		assert.Len(t, plugins, 5)
+
+		plugins, err = unscoped.GetPlugins(byName)
+		require.NoError(t, err)
+		assert.Len(t, plugins, 5)
+	})
+
+	t.Run("pages and totals only count visible plugins", func(t *testing.T) {
+		filter := *byName
+		filter.PerPage = 2
+		filter.Page = 1
+
+		page, err := anonymous.GetPluginsPage(&filter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"e-public@1.0.0"}, ids(page.Plugins))
+		assert.Equal(t, 3, page.Total)
+
+		filter.Page = 0
+		filter.Facets = true
+		page, err = anonymous.GetPluginsPage(&filter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a-public@1.0.0", "c-public@1.0.0"}, ids(page.Plugins))
+		require.NotNil(t, page.Facets)
+		assert.Equal(t, 3, page.Facets.AuthorType[model.Community])
+	})
+
+	t.Run("hidden plugins cannot be requested by id", func(t *testing.T) {
+		filter := *byName
+		filter.PluginID = "b-acme-internal"
+		filter.ReturnAllVersions = true
+
+		plugins, err := anonymous.GetPlugins(&filter)
+		require.NoError(t, err)
+		assert.Empty(t, plugins)
+
+		plugins, err = acme.GetPlugins(&filter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b-acme-internal@2.0.0", "b-acme-internal@1.0.0"}, ids(plugins))
+	})
+
+	t.Run("compatibility of hidden plugins is not explained", func(t *testing.T) {
+		compatibility, err := anonymous.GetPluginCompatibility(&model.PluginFilter{PluginID: "b-acme-internal"})
+		require.NoError(t, err)
+		assert.Empty(t, compatibility.Versions)
+
+		compatibility, err = acme.GetPluginCompatibility(&model.PluginFilter{PluginID: "b-acme-internal"})
+		require.NoError(t, err)
+		assert.Len(t, compatibility.Versions, 2)
+	})
+
+	t.Run("revision depends on visibility", func(t *testing.T) {
+		assert.NotEmpty(t, anonymous.Revision())
+		assert.NotEqual(t, anonymous.Revision(), acme.Revision())
+		assert.Equal(t, acme.Revision(), NewScoped(staticStore, &Visibility{Private: private, Granted: []string{"b-acme-*"}}, logger).Revision())
+	})
+}
@@ -1,15 +1,21 @@
 package store
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"io"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/search"
 )
 
 var minVersionSupportingEnterpriseFlags = semver.MustParse("5.25.0")
@@ -17,74 +23,280 @@ var minVersionSupportingEnterpriseFlags = semver.MustParse("5.25.0")
 // StaticStore provides access to a store backed by a static set of plugins.
 type StaticStore struct {
 	plugins []*model.Plugin
+	labels  []*model.LabelDefinition
 	logger  logrus.FieldLogger
+
+	// The search index is only built once a search is requested, since stores combining the
+	// results of other stores construct a static store on every request.
+	indexOnce sync.Once
+	index     *search.Index
+
+	revisionOnce sync.Once
+	revision     string
 }
 
 // NewStatic constructs a new instance of a static store, parsing the plugins from the given reader.
-func NewStaticFromReader(reader io.Reader, logger logrus.FieldLogger) (*StaticStore, error) {
+func NewStaticFromReader(reader io.Reader, labels []*model.LabelDefinition, logger logrus.FieldLogger) (*StaticStore, error) {
 	plugins, err := model.PluginsFromReader(reader)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to parse stream")
 	}
 
-	return NewStatic(plugins, logger)
+	return NewStatic(plugins, labels, logger)
 }
 
-// NewStatic constructs a new instance of a static store using the given plugins.
-func NewStatic(plugins []*model.Plugin, logger logrus.FieldLogger) (*StaticStore, error) {
+// NewStatic constructs a new instance of a static store using the given plugins, assigning each
+// the labels whose definitions match it. Without definitions, plugins only carry their own labels.
+func NewStatic(plugins []*model.Plugin, labels []*model.LabelDefinition, logger logrus.FieldLogger) (*StaticStore, error) {
 	if err := validatePlugins(plugins); err != nil {
 		return nil, errors.Wrap(err, "failed to validate plugins")
 	}
 
 	return &StaticStore{
-		plugins,
-		logger,
+		plugins: plugins,
+		labels:  labels,
+		logger:  logger,
 	}, nil
 }
 
-func validatePlugins(plugins []*model.Plugin) error {
+// searchIndex returns the index of the searchable text of the store's plugins, built on first use.
+func (store *StaticStore) searchIndex() *search.Index {
+	store.indexOnce.Do(func() {
+		store.index = newSearchIndex(store.plugins, store.labels)
+	})
+
+	return store.index
+}
+
+// newSearchIndex indexes the searchable text of the given plugins, including the names of the
+// labels assigned to each plugin by the given definitions, and the localizations of both.
+func newSearchIndex(plugins []*model.Plugin, labels []*model.LabelDefinition) *search.Index {
+	documents := make([]search.Document, 0, len(plugins))
 	for _, plugin := range plugins {
-		err := plugin.Manifest.IsValid()
+		labelled := *plugin
+		labelled.AddLabels(labels)
+
+		keywords := append([]string{}, plugin.Keywords...)
+		for _, label := range labelled.Labels {
+			keywords = append(keywords, label.Name)
+			for _, localization := range label.Localizations {
+				if localization != nil {
+					keywords = append(keywords, localization.Name)
+				}
+			}
+		}
+
+		document := search.Document{
+			ID:          plugin.Manifest.Id,
+			Name:        plugin.Manifest.Name,
+			Description: plugin.Manifest.Description,
+			Keywords:    keywords,
+		}
+		for _, localization := range plugin.Localizations {
+			if localization != nil {
+				document.LocalizedNames = append(document.LocalizedNames, localization.Name)
+				document.LocalizedDescriptions = append(document.LocalizedDescriptions, localization.Description)
+			}
+		}
+
+		documents = append(documents, document)
+	}
+
+	return search.NewIndex(documents)
+}
+
+// Revision returns a digest of the plugins backing the store, computed on first use.
+//
+// The digest changes whenever any plugin in the catalog or the labels assigned to them do, making
+// it suitable for deriving cache validators. An empty string is returned if the digest cannot be
+// computed.
+func (store *StaticStore) Revision() string {
+	store.revisionOnce.Do(func() {
+		hash := sha256.New()
+		encoder := json.NewEncoder(hash)
+		err := encoder.Encode(store.plugins)
+		if err == nil {
+			err = encoder.Encode(store.labels)
+		}
 		if err != nil {
-			return errors.Wrapf(err, "invalid manifest for plugin %s", plugin.Manifest.Id)
+			store.logger.WithError(err).Warn("failed to compute store revision")
+			return
 		}
 
-		if plugin.Manifest.Version == "" {
-			return errors.Errorf("missing version in manifest for plugin%s", plugin.Manifest.Id)
+		store.revision = hex.EncodeToString(hash.Sum(nil))
+	})
+
+	return store.revision
+}
+
+func validatePlugins(plugins []*model.Plugin) error {
+	for _, plugin := range plugins {
+		err := plugin.Validate()
+		if err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-func pluginMatchesFilter(plugin *model.Plugin, filter string) bool {
-	filter = strings.ToLower(filter)
-	if strings.ToLower(plugin.Manifest.Id) == filter {
-		return true
+// pluginMatchesFacets reports whether the plugin matches every facet constraining the filter.
+func pluginMatchesFacets(plugin *model.Plugin, pluginFilter *model.PluginFilter) bool {
+	if len(pluginFilter.AuthorTypes) > 0 {
+		matched := false
+		for _, authorType := range pluginFilter.AuthorTypes {
+			if plugin.AuthorType == authorType {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
 	}
 
-	if strings.Contains(strings.ToLower(plugin.Manifest.Name), filter) {
-		return true
+	if len(pluginFilter.ReleaseStages) > 0 {
+		matched := false
+		for _, releaseStage := range pluginFilter.ReleaseStages {
+			if plugin.ReleaseStage == releaseStage {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
 	}
 
-	if strings.Contains(strings.ToLower(plugin.Manifest.Description), filter) {
-		return true
+	if len(pluginFilter.Hosting) > 0 {
+		matched := false
+		for _, hosting := range pluginFilter.Hosting {
+			if plugin.IsAvailableFor(hosting) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
 	}
 
-	return false
+	if len(pluginFilter.Labels) > 0 {
+		matched := false
+		for _, label := range pluginFilter.Labels {
+			if plugin.HasLabel(label) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// countFacets counts the given plugins per facet value. Each facet is counted over the plugins
+// matching every other facet of the filter, ignoring its constraint on the facet being counted.
+func countFacets(plugins []*model.Plugin, pluginFilter *model.PluginFilter) *model.PluginFacets {
+	facets := model.NewPluginFacets()
+
+	withoutAuthorTypes := *pluginFilter
+	withoutAuthorTypes.AuthorTypes = nil
+	withoutReleaseStages := *pluginFilter
+	withoutReleaseStages.ReleaseStages = nil
+	withoutHosting := *pluginFilter
+	withoutHosting.Hosting = nil
+	withoutLabels := *pluginFilter
+	withoutLabels.Labels = nil
+
+	for _, plugin := range plugins {
+		if plugin.AuthorType != "" && pluginMatchesFacets(plugin, &withoutAuthorTypes) {
+			facets.AuthorType[plugin.AuthorType]++
+		}
+
+		if plugin.ReleaseStage != "" && pluginMatchesFacets(plugin, &withoutReleaseStages) {
+			facets.ReleaseStage[plugin.ReleaseStage]++
+		}
+
+		if pluginMatchesFacets(plugin, &withoutHosting) {
+			for _, hosting := range []model.HostingType{model.OnPrem, model.Cloud} {
+				if plugin.IsAvailableFor(hosting) {
+					facets.Hosting[hosting]++
+				}
+			}
+		}
+
+		if pluginMatchesFacets(plugin, &withoutLabels) {
+			for _, label := range plugin.Labels {
+				facets.Label[label.Name]++
+			}
+		}
+
+		if pluginMatchesFacets(plugin, pluginFilter) {
+			for _, platform := range plugin.AvailablePlatforms() {
+				facets.Platform[platform]++
+			}
+		}
+	}
+
+	return facets
 }
 
 // GetPlugins fetches the given page of plugins. The first page is 0.
 func (store *StaticStore) GetPlugins(pluginFilter *model.PluginFilter) ([]*model.Plugin, error) {
-	if pluginFilter.PerPage == 0 {
-		return nil, nil
+	page, err := store.GetPluginsPage(pluginFilter)
+	if err != nil {
+		return nil, err
 	}
 
-	plugins, err := store.getPlugins(pluginFilter.ServerVersion, pluginFilter.EnterprisePlugins, pluginFilter.Cloud, pluginFilter.Platform)
+	return page.Plugins, nil
+}
+
+// GetPluginsPage fetches the given page of plugins alongside the total number of matching plugins.
+//
+// If the filter specifies a cursor, the page starts immediately after the plugin it identifies
+// instead of at the requested page offset.
+func (store *StaticStore) GetPluginsPage(pluginFilter *model.PluginFilter) (*model.PluginsPage, error) {
+	sortField, sortDirection := pluginFilter.SortOrder()
+	if !sortField.IsValid() || !sortDirection.IsValid() {
+		return nil, errors.Errorf("invalid sort %s %s", sortField, sortDirection)
+	}
+	sorter := pluginSorter{sort: sortField, direction: sortDirection}
+
+	var cursor *pluginSortKey
+	if pluginFilter.Cursor != "" {
+		pluginCursor, err := model.DecodePluginCursor(pluginFilter.Cursor)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid cursor")
+		}
+
+		if !pluginCursor.Matches(pluginFilter) {
+			return nil, errors.New("cursor was issued for a different sort order")
+		}
+
+		cursorKey, err := newCursorSortKey(pluginCursor)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid cursor")
+		}
+		cursor = &cursorKey
+	}
+
+	var scores map[int]float64
+	if strings.TrimSpace(pluginFilter.Filter) != "" {
+		scores = store.searchIndex().Search(pluginFilter.Filter)
+	}
+
+	plugins, relevance, err := store.getPlugins(pluginFilter.ServerVersion, pluginFilter.EnterprisePlugins, pluginFilter.Cloud, pluginFilter.Platform, scores)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get plugins")
 	}
 
+	if !pluginFilter.ReturnAllVersions || !pluginFilter.IncludeYanked {
+		plugins = withoutYanked(plugins)
+	}
+
 	if !pluginFilter.ReturnAllVersions {
 		plugins, err = filterToLatestVersion(plugins)
 		if err != nil {
@@ -92,48 +304,193 @@ func (store *StaticStore) GetPlugins(pluginFilter *model.PluginFilter) ([]*model
 		}
 	}
 
-	filter := strings.TrimSpace(pluginFilter.Filter)
-	var filteredPlugins []*model.Plugin
+	var candidatePlugins []*model.Plugin
 	for _, plugin := range plugins {
 		if pluginFilter.PluginID != "" && pluginFilter.PluginID != plugin.Manifest.Id {
 			continue
 		}
-		if filter == "" || pluginMatchesFilter(plugin, filter) {
+		candidatePlugins = append(candidatePlugins, plugin)
+	}
+
+	var filteredPlugins []*model.Plugin
+	for _, plugin := range candidatePlugins {
+		if pluginMatchesFacets(plugin, pluginFilter) {
 			filteredPlugins = append(filteredPlugins, plugin)
 		}
 	}
 	plugins = filteredPlugins
 
-	// Sort the final slice by plugin version decending then plugin name ascending.
-	sort.SliceStable(
-		plugins,
-		func(i, j int) bool {
-			if plugins[i].Manifest.Id == plugins[j].Manifest.Id {
-				iVersion := semver.MustParse(plugins[i].Manifest.Version)
-				jVersion := semver.MustParse(plugins[j].Manifest.Version)
-				return iVersion.GT(jVersion)
-			}
-			return strings.ToLower(plugins[i].Manifest.Name) < strings.ToLower(plugins[j].Manifest.Name)
-		},
-	)
+	keys := make([]pluginSortKey, 0, len(plugins))
+	for _, plugin := range plugins {
+		keys = append(keys, newPluginSortKey(plugin, relevance[plugin]))
+	}
+	sort.Stable(&sortablePlugins{plugins: plugins, keys: keys, sorter: sorter})
+
+	result := &model.PluginsPage{
+		Total: len(plugins),
+	}
+	if pluginFilter.Facets {
+		result.Facets = countFacets(candidatePlugins, pluginFilter)
+	}
 
-	if len(plugins) == 0 {
-		return nil, nil
+	if len(plugins) == 0 || pluginFilter.PerPage == 0 {
+		return result, nil
 	}
-	if pluginFilter.PerPage == model.AllPerPage {
-		return plugins, nil
+
+	start := 0
+	if cursor != nil {
+		start = sort.Search(len(keys), func(i int) bool {
+			return sorter.less(*cursor, keys[i])
+		})
+	} else if pluginFilter.PerPage != model.AllPerPage {
+		start = pluginFilter.Page * pluginFilter.PerPage
+	}
+
+	end := len(plugins)
+	if pluginFilter.PerPage != model.AllPerPage && start+pluginFilter.PerPage < end {
+		end = start + pluginFilter.PerPage
 	}
 
-	start := (pluginFilter.Page) * pluginFilter.PerPage
-	end := (pluginFilter.Page + 1) * pluginFilter.PerPage
 	if start >= len(plugins) {
-		return nil, nil
+		return result, nil
 	}
-	if end > len(plugins) {
-		end = len(plugins)
+
+	result.Plugins = plugins[start:end]
+	if end < len(plugins) {
+		result.NextCursor = keys[end-1].cursor(sorter).Encode()
 	}
 
-	return plugins[start:end], nil
+	return result, nil
+}
+
+// pluginSortKey captures the fields by which plugins are ordered.
+type pluginSortKey struct {
+	name      string
+	id        string
+	version   semver.Version
+	updatedAt time.Time
+	score     float64
+}
+
+// newPluginSortKey captures the sort key of the given plugin, given its relevance to the filter.
+func newPluginSortKey(plugin *model.Plugin, score float64) pluginSortKey {
+	return pluginSortKey{
+		name:      strings.ToLower(plugin.Manifest.Name),
+		id:        plugin.Manifest.Id,
+		version:   semver.MustParse(plugin.Manifest.Version),
+		updatedAt: plugin.UpdatedAt,
+		score:     score,
+	}
+}
+
+func newCursorSortKey(cursor *model.PluginCursor) (pluginSortKey, error) {
+	version, err := semver.Parse(cursor.Version)
+	if err != nil {
+		return pluginSortKey{}, errors.Wrapf(err, "failed to parse version %s", cursor.Version)
+	}
+
+	return pluginSortKey{
+		name:      strings.ToLower(cursor.Name),
+		id:        cursor.ID,
+		version:   version,
+		updatedAt: cursor.UpdatedAt,
+		score:     cursor.Score,
+	}, nil
+}
+
+// cursor returns a cursor positioned at the plugin with this key.
+func (k pluginSortKey) cursor(sorter pluginSorter) *model.PluginCursor {
+	return &model.PluginCursor{
+		Sort:      sorter.sort,
+		Direction: sorter.direction,
+		Name:      k.name,
+		ID:        k.id,
+		Version:   k.version.String(),
+		UpdatedAt: k.updatedAt,
+		Score:     k.score,
+	}
+}
+
+// pluginSorter orders plugins by the requested field and direction.
+//
+// Ties are broken by name then id ascending, with versions of the same plugin ordered by version
+// descending. When sorting by name or id, versions of the same plugin are always kept together.
+type pluginSorter struct {
+	sort      model.PluginSort
+	direction model.SortDirection
+}
+
+func (s pluginSorter) less(a, b pluginSortKey) bool {
+	var cmp int
+	switch s.sort {
+	case model.SortByUpdatedAt:
+		switch {
+		case a.updatedAt.Before(b.updatedAt):
+			cmp = -1
+		case a.updatedAt.After(b.updatedAt):
+			cmp = 1
+		}
+	case model.SortByRelevance:
+		switch {
+		case a.score < b.score:
+			cmp = -1
+		case a.score > b.score:
+			cmp = 1
+		}
+	case model.SortByID:
+		cmp = strings.Compare(a.id, b.id)
+	default:
+		if a.id != b.id {
+			cmp = strings.Compare(a.name, b.name)
+		}
+	}
+
+	if s.direction == model.SortDescending {
+		cmp = -cmp
+	}
+	if cmp != 0 {
+		return cmp < 0
+	}
+
+	if a.id == b.id {
+		return a.version.GT(b.version)
+	}
+	if a.name != b.name {
+		return a.name < b.name
+	}
+	return a.id < b.id
+}
+
+// sortablePlugins sorts plugins alongside their precomputed sort keys.
+type sortablePlugins struct {
+	plugins []*model.Plugin
+	keys    []pluginSortKey
+	sorter  pluginSorter
+}
+
+func (p *sortablePlugins) Len() int {
+	return len(p.plugins)
+}
+
+func (p *sortablePlugins) Less(i, j int) bool {
+	return p.sorter.less(p.keys[i], p.keys[j])
+}
+
+func (p *sortablePlugins) Swap(i, j int) {
+	p.plugins[i], p.plugins[j] = p.plugins[j], p.plugins[i]
+	p.keys[i], p.keys[j] = p.keys[j], p.keys[i]
+}
+
+// withoutYanked returns the given plugins except for any yanked versions.
+func withoutYanked(plugins []*model.Plugin) []*model.Plugin {
+	result := make([]*model.Plugin, 0, len(plugins))
+	for _, plugin := range plugins {
+		if !plugin.IsYanked() {
+			result = append(result, plugin)
+		}
+	}
+
+	return result
 }
 
 func filterToLatestVersion(plugins []*model.Plugin) ([]*model.Plugin, error) {
@@ -167,77 +524,112 @@ func filterToLatestVersion(plugins []*model.Plugin) ([]*model.Plugin, error) {
 }
 
 // getPlugins returns all plugins compatible with the given server version, sorted by name ascending.
-func (store *StaticStore) getPlugins(serverVersion string, includeEnterprisePlugins bool, isCloud bool, platform string) ([]*model.Plugin, error) {
+//
+// If scores is non-nil, only the plugins scored by a search of the index are returned, alongside
+// their relevance to the search.
+func (store *StaticStore) getPlugins(serverVersion string, includeEnterprisePlugins bool, isCloud bool, platform string, scores map[int]float64) ([]*model.Plugin, map[*model.Plugin]float64, error) {
 	var result []*model.Plugin
+	relevance := make(map[*model.Plugin]float64)
 
-	for _, storePlugin := range store.plugins {
-		if storePlugin.Enterprise && !includeEnterprisePlugins {
-			if serverVersion == "" {
-				continue
-			}
+	for i, storePlugin := range store.plugins {
+		score, matched := scores[i]
+		if scores != nil && !matched {
+			continue
+		}
 
-			sv, err := semver.Parse(serverVersion)
-			if err != nil {
-				return nil, errors.Wrapf(err, "failed to parse serverVersion %s", serverVersion)
-			}
+		rule, err := checkCompatibility(storePlugin, serverVersion, includeEnterprisePlugins, isCloud)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !rule.Includes() {
+			continue
+		}
 
-			// Honor enterprise flag for server version >= 5.25.0 only.
-			// Workaround for https://mattermost.atlassian.net/browse/MM-26507
+		// Create a copy as we want to modify only the returned one
+		newRef := *storePlugin
+		storePlugin = &newRef
 
-			if sv.GE(minVersionSupportingEnterpriseFlags) {
-				continue
-			}
+		storePlugin.AddLabels(store.labels)
+		selectPlatformBundle(storePlugin, platform)
+
+		result = append(result, storePlugin)
+		relevance[storePlugin] = score
+	}
+
+	return result, relevance, nil
+}
+
+// checkCompatibility returns the rule by which the given plugin is included in, or excluded from,
+// the plugins compatible with the given server.
+func checkCompatibility(plugin *model.Plugin, serverVersion string, includeEnterprisePlugins bool, isCloud bool) (model.CompatibilityRule, error) {
+	rule := model.RuleCompatible
+
+	if plugin.Enterprise && !includeEnterprisePlugins {
+		if serverVersion == "" {
+			return model.RuleEnterprise, nil
 		}
 
-		if isCloud && storePlugin.Hosting == model.OnPrem {
-			continue
+		sv, err := semver.Parse(serverVersion)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to parse serverVersion %s", serverVersion)
 		}
 
-		if !isCloud && storePlugin.Hosting == model.Cloud {
-			continue
+		// Honor enterprise flag for server version >= 5.25.0 only.
+		// Workaround for https://mattermost.atlassian.net/browse/MM-26507
+
+		if sv.GE(minVersionSupportingEnterpriseFlags) {
+			return model.RuleEnterprise, nil
 		}
 
-		if serverVersion != "" && storePlugin.Manifest.MinServerVersion != "" {
-			_, err := semver.Parse(serverVersion)
-			if err != nil {
-				return nil, errors.Wrapf(err, "failed to parse serverVersion %s", serverVersion)
-			}
+		rule = model.RuleEnterpriseLegacyServer
+	}
 
-			meetsMinServerVersion, err := storePlugin.Manifest.MeetMinServerVersion(serverVersion)
-			if err != nil {
-				return nil, errors.Wrapf(err, "failed to check minServerVersion for manifest.Id %s", storePlugin.Manifest.Id)
-			}
+	if isCloud && plugin.Hosting == model.OnPrem {
+		return model.RuleOnPremOnly, nil
+	}
 
-			if !meetsMinServerVersion {
-				continue
-			}
-		}
+	if !isCloud && plugin.Hosting == model.Cloud {
+		return model.RuleCloudOnly, nil
+	}
 
-		// Create a copy as we want to modify only the returned one
-		newRef := *storePlugin
-		storePlugin = &newRef
+	if serverVersion != "" && plugin.Manifest.MinServerVersion != "" {
+		_, err := semver.Parse(serverVersion)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to parse serverVersion %s", serverVersion)
+		}
 
-		storePlugin.AddLabels()
-
-		if platform != "" {
-			var bundle model.PlatformBundleMetadata
-			switch platform {
-			case model.LinuxAmd64:
-				bundle = storePlugin.Platforms.LinuxAmd64
-			case model.DarwinAmd64:
-				bundle = storePlugin.Platforms.DarwinAmd64
-			case model.WindowsAmd64:
-				bundle = storePlugin.Platforms.WindowsAmd64
-			}
+		meetsMinServerVersion, err := plugin.Manifest.MeetMinServerVersion(serverVersion)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to check minServerVersion for manifest.Id %s", plugin.Manifest.Id)
+		}
 
-			if bundle.DownloadURL != "" && bundle.Signature != "" {
-				storePlugin.DownloadURL = bundle.DownloadURL
-				storePlugin.Signature = bundle.Signature
-			}
+		if !meetsMinServerVersion {
+			return model.RuleMinServerVersion, nil
 		}
+	}
 
-		result = append(result, storePlugin)
+	return rule, nil
+}
+
+// selectPlatformBundle substitutes the bundle built for the given platform, if any, for the
+// platform-independent bundle of the plugin, returning true if substituted.
+func selectPlatformBundle(plugin *model.Plugin, platform string) bool {
+	var bundle model.PlatformBundleMetadata
+	switch platform {
+	case model.LinuxAmd64:
+		bundle = plugin.Platforms.LinuxAmd64
+	case model.DarwinAmd64:
+		bundle = plugin.Platforms.DarwinAmd64
+	case model.WindowsAmd64:
+		bundle = plugin.Platforms.WindowsAmd64
 	}
 
-	return result, nil
+	if bundle.DownloadURL == "" || bundle.Signature == "" {
+		return false
+	}
+
+	plugin.DownloadURL = bundle.DownloadURL
+	plugin.Signature = bundle.Signature
+
+	return true
 }
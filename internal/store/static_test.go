@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"testing"
+	"time"
 
 	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
 	"github.com/stretchr/testify/assert"
@@ -16,7 +17,7 @@ import (
 func TestNewStatic(t *testing.T) {
 	t.Run("empty stream", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
-		store, err := NewStatic([]*model.Plugin{}, logger)
+		store, err := NewStatic([]*model.Plugin{}, model.DefaultLabelDefinitions, logger)
 		assert.NoError(t, err)
 		require.NotNil(t, store)
 		assert.Empty(t, store.plugins)
@@ -40,7 +41,7 @@ func TestNewStatic(t *testing.T) {
 				ReleaseNotesURL: "https://github.com/mattermost/mattermost-plugin-starter-template/releases/v0.1.0",
 				Manifest:        &mattermostModel.Manifest{},
 			},
-		}, logger)
+		}, model.DefaultLabelDefinitions, logger)
 		assert.Error(t, err)
 		assert.Nil(t, store)
 	})
@@ -67,7 +68,7 @@ func TestNewStatic(t *testing.T) {
 					Id: "test",
 				},
 			},
-		}, logger)
+		}, model.DefaultLabelDefinitions, logger)
 		assert.Error(t, err)
 		assert.Nil(t, store)
 	})
@@ -98,7 +99,7 @@ func TestNewStatic(t *testing.T) {
 					Version: "0.1.0",
 				},
 			},
-		}, logger)
+		}, model.DefaultLabelDefinitions, logger)
 		assert.NoError(t, err)
 		assert.NotNil(t, store)
 	})
@@ -131,7 +132,7 @@ func TestNewStatic(t *testing.T) {
 					MinServerVersion: "5.23.0",
 				},
 			},
-		}, logger)
+		}, model.DefaultLabelDefinitions, logger)
 		assert.NoError(t, err)
 		assert.NotNil(t, store)
 	})
@@ -140,7 +141,7 @@ func TestNewStatic(t *testing.T) {
 func TestNewStaticFromReader(t *testing.T) {
 	t.Run("empty stream", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
-		store, err := NewStaticFromReader(bytes.NewReader([]byte{}), logger)
+		store, err := NewStaticFromReader(bytes.NewReader([]byte{}), model.DefaultLabelDefinitions, logger)
 		assert.NoError(t, err)
 		require.NotNil(t, store)
 		assert.Empty(t, store.plugins)
@@ -148,35 +149,35 @@ func TestNewStaticFromReader(t *testing.T) {
 
 	t.Run("invalid stream", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
-		store, err := NewStaticFromReader(bytes.NewReader([]byte(`{"invalid":`)), logger)
+		store, err := NewStaticFromReader(bytes.NewReader([]byte(`{"invalid":`)), model.DefaultLabelDefinitions, logger)
 		assert.EqualError(t, err, "failed to parse stream: unexpected EOF")
 		assert.Nil(t, store)
 	})
 
 	t.Run("missing manifest id", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
-		store, err := NewStaticFromReader(bytes.NewReader([]byte(`[{"HomepageURL":"https://github.com/mattermost/mattermost-plugin-demo","IconData":"icon-data.svg","DownloadURL":"https://github.com/mattermost/mattermost-plugin-demo/releases/download/v0.1.0/com.mattermost.demo-plugin-0.1.0.tar.gz","Signature":"c2lnbmF0dXJl","ReleaseNotesURL":"https://github.com/mattermost/mattermost-plugin-demo/releases/v0.1.0","Manifest":{}},{"HomepageURL":"https://github.com/mattermost/mattermost-plugin-starter-template","DownloadURL":"https://github.com/mattermost/mattermost-plugin-starter-template/releases/download/v0.1.0/com.mattermost.plugin-starter-template-0.1.0.tar.gz","Signature":"signature2","ReleaseNotesURL":"https://github.com/mattermost/mattermost-plugin-starter-template/releases/v0.1.0","Manifest":{}}]`)), logger)
+		store, err := NewStaticFromReader(bytes.NewReader([]byte(`[{"HomepageURL":"https://github.com/mattermost/mattermost-plugin-demo","IconData":"icon-data.svg","DownloadURL":"https://github.com/mattermost/mattermost-plugin-demo/releases/download/v0.1.0/com.mattermost.demo-plugin-0.1.0.tar.gz","Signature":"c2lnbmF0dXJl","ReleaseNotesURL":"https://github.com/mattermost/mattermost-plugin-demo/releases/v0.1.0","Manifest":{}},{"HomepageURL":"https://github.com/mattermost/mattermost-plugin-starter-template","DownloadURL":"https://github.com/mattermost/mattermost-plugin-starter-template/releases/download/v0.1.0/com.mattermost.plugin-starter-template-0.1.0.tar.gz","Signature":"signature2","ReleaseNotesURL":"https://github.com/mattermost/mattermost-plugin-starter-template/releases/v0.1.0","Manifest":{}}]`)), model.DefaultLabelDefinitions, logger)
 		assert.Error(t, err)
 		assert.Nil(t, store)
 	})
 
 	t.Run("missing manifest version", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
-		store, err := NewStaticFromReader(bytes.NewReader([]byte(`[{"HomepageURL":"https://github.com/mattermost/mattermost-plugin-demo","IconData":"icon-data.svg","DownloadURL":"https://github.com/mattermost/mattermost-plugin-demo/releases/download/v0.1.0/com.mattermost.demo-plugin-0.1.0.tar.gz","Signature":"c2lnbmF0dXJl","ReleaseNotesURL":"https://github.com/mattermost/mattermost-plugin-demo/releases/v0.1.0","Manifest":{"id": "test", "name": "Test", }},{"HomepageURL":"https://github.com/mattermost/mattermost-plugin-starter-template","DownloadURL":"https://github.com/mattermost/mattermost-plugin-starter-template/releases/download/v0.1.0/com.mattermost.plugin-starter-template-0.1.0.tar.gz","Signature":"signature2"],"ReleaseNotesURL":"https://github.com/mattermost/mattermost-plugin-starter-template/releases/v0.1.0","Manifest":{"id": "test", "name": "Test"}}]`)), logger)
+		store, err := NewStaticFromReader(bytes.NewReader([]byte(`[{"HomepageURL":"https://github.com/mattermost/mattermost-plugin-demo","IconData":"icon-data.svg","DownloadURL":"https://github.com/mattermost/mattermost-plugin-demo/releases/download/v0.1.0/com.mattermost.demo-plugin-0.1.0.tar.gz","Signature":"c2lnbmF0dXJl","ReleaseNotesURL":"https://github.com/mattermost/mattermost-plugin-demo/releases/v0.1.0","Manifest":{"id": "test", "name": "Test", }},{"HomepageURL":"https://github.com/mattermost/mattermost-plugin-starter-template","DownloadURL":"https://github.com/mattermost/mattermost-plugin-starter-template/releases/download/v0.1.0/com.mattermost.plugin-starter-template-0.1.0.tar.gz","Signature":"signature2"],"ReleaseNotesURL":"https://github.com/mattermost/mattermost-plugin-starter-template/releases/v0.1.0","Manifest":{"id": "test", "name": "Test"}}]`)), model.DefaultLabelDefinitions, logger)
 		assert.Error(t, err)
 		assert.Nil(t, store)
 	})
 
 	t.Run("missing min_server_version version is valid", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
-		store, err := NewStaticFromReader(bytes.NewReader([]byte(`[{"HomepageURL":"https://github.com/mattermost/mattermost-plugin-demo","IconData":"icon-data.svg","DownloadURL":"https://github.com/mattermost/mattermost-plugin-demo/releases/download/v0.1.0/com.mattermost.demo-plugin-0.1.0.tar.gz","Signature":"c2lnbmF0dXJl","ReleaseNotesURL":"https://github.com/mattermost/mattermost-plugin-demo/releases/v0.1.0","Manifest":{"id": "test", "name": "Test", "version": "0.1.0"}},{"HomepageURL":"https://github.com/mattermost/mattermost-plugin-starter-template","DownloadURL":"https://github.com/mattermost/mattermost-plugin-starter-template/releases/download/v0.1.0/com.mattermost.plugin-starter-template-0.1.0.tar.gz","Signature":"signature2","ReleaseNotesURL":"https://github.com/mattermost/mattermost-plugin-starter-template/releases/v0.1.0","Manifest":{"id": "test", "name": "Test", "version": "0.1.0"}}]`)), logger)
+		store, err := NewStaticFromReader(bytes.NewReader([]byte(`[{"HomepageURL":"https://github.com/mattermost/mattermost-plugin-demo","IconData":"icon-data.svg","DownloadURL":"https://github.com/mattermost/mattermost-plugin-demo/releases/download/v0.1.0/com.mattermost.demo-plugin-0.1.0.tar.gz","Signature":"c2lnbmF0dXJl","ReleaseNotesURL":"https://github.com/mattermost/mattermost-plugin-demo/releases/v0.1.0","Manifest":{"id": "test", "name": "Test", "version": "0.1.0"}},{"HomepageURL":"https://github.com/mattermost/mattermost-plugin-starter-template","DownloadURL":"https://github.com/mattermost/mattermost-plugin-starter-template/releases/download/v0.1.0/com.mattermost.plugin-starter-template-0.1.0.tar.gz","Signature":"signature2","ReleaseNotesURL":"https://github.com/mattermost/mattermost-plugin-starter-template/releases/v0.1.0","Manifest":{"id": "test", "name": "Test", "version": "0.1.0"}}]`)), model.DefaultLabelDefinitions, logger)
 		assert.NoError(t, err)
 		assert.NotNil(t, store)
 	})
 
 	t.Run("valid stream", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
-		store, err := NewStaticFromReader(bytes.NewReader([]byte(`[{"HomepageURL":"https://github.com/mattermost/mattermost-plugin-demo","IconData":"icon-data.svg","DownloadURL":"https://github.com/mattermost/mattermost-plugin-demo/releases/download/v0.1.0/com.mattermost.demo-plugin-0.1.0.tar.gz","Signature":"c2lnbmF0dXJl","ReleaseNotesURL":"https://github.com/mattermost/mattermost-plugin-demo/releases/v0.1.0","Manifest":{"id": "test", "name": "Test", "version": "0.1.0", "min_server_version":"5.23.0"}},{"HomepageURL":"https://github.com/mattermost/mattermost-plugin-starter-template","DownloadURL":"https://github.com/mattermost/mattermost-plugin-starter-template/releases/download/v0.1.0/com.mattermost.plugin-starter-template-0.1.0.tar.gz","Signature":"signature2","ReleaseNotesURL":"https://github.com/mattermost/mattermost-plugin-starter-template/releases/v0.1.0","Manifest":{"id": "test", "name": "Test", "version": "0.1.0", "min_server_version":"5.23.0"}}]`)), logger)
+		store, err := NewStaticFromReader(bytes.NewReader([]byte(`[{"HomepageURL":"https://github.com/mattermost/mattermost-plugin-demo","IconData":"icon-data.svg","DownloadURL":"https://github.com/mattermost/mattermost-plugin-demo/releases/download/v0.1.0/com.mattermost.demo-plugin-0.1.0.tar.gz","Signature":"c2lnbmF0dXJl","ReleaseNotesURL":"https://github.com/mattermost/mattermost-plugin-demo/releases/v0.1.0","Manifest":{"id": "test", "name": "Test", "version": "0.1.0", "min_server_version":"5.23.0"}},{"HomepageURL":"https://github.com/mattermost/mattermost-plugin-starter-template","DownloadURL":"https://github.com/mattermost/mattermost-plugin-starter-template/releases/download/v0.1.0/com.mattermost.plugin-starter-template-0.1.0.tar.gz","Signature":"signature2","ReleaseNotesURL":"https://github.com/mattermost/mattermost-plugin-starter-template/releases/v0.1.0","Manifest":{"id": "test", "name": "Test", "version": "0.1.0", "min_server_version":"5.23.0"}}]`)), model.DefaultLabelDefinitions, logger)
 		assert.NoError(t, err)
 		assert.NotNil(t, store)
 	})
@@ -249,7 +250,7 @@ func TestStaticGetPlugins(t *testing.T) {
 	require.NoError(t, err)
 
 	logger := testlib.MakeLogger(t)
-	staticStore, err := NewStaticFromReader(bytes.NewReader(data), logger)
+	staticStore, err := NewStaticFromReader(bytes.NewReader(data), model.DefaultLabelDefinitions, logger)
 	require.NoError(t, err)
 
 	t.Run("page 0, per page 0", func(t *testing.T) {
@@ -490,3 +491,480 @@ func TestStaticGetPlugins(t *testing.T) {
 		require.Nil(t, actualPlugins)
 	})
 }
+
+func TestStaticRevision(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	plugin1 := &model.Plugin{
+		Manifest: &mattermostModel.Manifest{
+			Id:      "test",
+			Name:    "Test",
+			Version: "0.1.0",
+		},
+	}
+	plugin2 := &model.Plugin{
+		Manifest: &mattermostModel.Manifest{
+			Id:      "test",
+			Name:    "Test",
+			Version: "0.2.0",
+		},
+	}
+
+	store1, err := NewStatic([]*model.Plugin{plugin1}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+	store2, err := NewStatic([]*model.Plugin{plugin1}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+	store3, err := NewStatic([]*model.Plugin{plugin1, plugin2}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, store1.Revision())
+	assert.Equal(t, store1.Revision(), store2.Revision())
+	assert.NotEqual(t, store1.Revision(), store3.Revision())
+}
+
+func TestStaticGetPluginsYanked(t *testing.T) {
+	newPlugin := func(version string) *model.Plugin {
+		return &model.Plugin{
+			Manifest: &mattermostModel.Manifest{
+				Id:      "test",
+				Name:    "Test",
+				Version: version,
+			},
+		}
+	}
+
+	v1 := newPlugin("0.1.0")
+	v2 := newPlugin("0.2.0")
+	v2.Yanked = &model.Yank{Reason: "Corrupts data"}
+
+	logger := testlib.MakeLogger(t)
+	staticStore, err := NewStatic([]*model.Plugin{v1, v2}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+
+	versions := func(filter *model.PluginFilter) []string {
+		filter.PerPage = model.AllPerPage
+		plugins, err := staticStore.GetPlugins(filter)
+		require.NoError(t, err)
+
+		var result []string
+		for _, plugin := range plugins {
+			result = append(result, plugin.Manifest.Version)
+		}
+		return result
+	}
+
+	testCases := []struct {
+		Description string
+		Filter      *model.PluginFilter
+		Expected    []string
+	}{
+		{"latest version skips yanked", &model.PluginFilter{}, []string{"0.1.0"}},
+		{"all versions omit yanked", &model.PluginFilter{ReturnAllVersions: true}, []string{"0.1.0"}},
+		{"include yanked requires all versions", &model.PluginFilter{IncludeYanked: true}, []string{"0.1.0"}},
+		{"all versions including yanked", &model.PluginFilter{ReturnAllVersions: true, IncludeYanked: true}, []string{"0.2.0", "0.1.0"}},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.Description, func(t *testing.T) {
+			assert.Equal(t, testCase.Expected, versions(testCase.Filter))
+		})
+	}
+}
+
+func TestStaticGetPluginsPage(t *testing.T) {
+	newPlugin := func(id, name, version string) *model.Plugin {
+		return &model.Plugin{
+			Manifest: &mattermostModel.Manifest{
+				Id:      id,
+				Name:    name,
+				Version: version,
+			},
+		}
+	}
+
+	alphaV1 := newPlugin("alpha", "Alpha", "1.0.0")
+	alphaV2 := newPlugin("alpha", "Alpha", "2.0.0")
+	bravo := newPlugin("bravo", "Bravo", "1.0.0")
+	charlie := newPlugin("charlie", "Charlie", "1.0.0")
+	delta := newPlugin("delta", "Delta", "1.0.0")
+
+	logger := testlib.MakeLogger(t)
+
+	t.Run("total ignores pagination", func(t *testing.T) {
+		staticStore, err := NewStatic([]*model.Plugin{alphaV1, alphaV2, bravo, charlie, delta}, model.DefaultLabelDefinitions, logger)
+		require.NoError(t, err)
+
+		page, err := staticStore.GetPluginsPage(&model.PluginFilter{
+			Page:    1,
+			PerPage: 3,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 4, page.Total)
+		assert.Equal(t, []*model.Plugin{delta}, page.Plugins)
+		assert.Empty(t, page.NextCursor)
+
+		page, err = staticStore.GetPluginsPage(&model.PluginFilter{
+			PerPage:           0,
+			ReturnAllVersions: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 5, page.Total)
+		assert.Empty(t, page.Plugins)
+	})
+
+	t.Run("walk by cursor", func(t *testing.T) {
+		staticStore, err := NewStatic([]*model.Plugin{alphaV1, alphaV2, bravo, charlie, delta}, model.DefaultLabelDefinitions, logger)
+		require.NoError(t, err)
+
+		page, err := staticStore.GetPluginsPage(&model.PluginFilter{
+			PerPage:           2,
+			ReturnAllVersions: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Plugin{alphaV2, alphaV1}, page.Plugins)
+		require.NotEmpty(t, page.NextCursor)
+
+		page, err = staticStore.GetPluginsPage(&model.PluginFilter{
+			PerPage:           2,
+			ReturnAllVersions: true,
+			Cursor:            page.NextCursor,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Plugin{bravo, charlie}, page.Plugins)
+		require.NotEmpty(t, page.NextCursor)
+
+		// Removing plugins already seen must not shift the remaining pages.
+		staticStore, err = NewStatic([]*model.Plugin{bravo, delta}, model.DefaultLabelDefinitions, logger)
+		require.NoError(t, err)
+
+		page, err = staticStore.GetPluginsPage(&model.PluginFilter{
+			PerPage:           2,
+			ReturnAllVersions: true,
+			Cursor:            page.NextCursor,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Plugin{delta}, page.Plugins)
+		assert.Equal(t, 2, page.Total)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		staticStore, err := NewStatic([]*model.Plugin{alphaV1}, model.DefaultLabelDefinitions, logger)
+		require.NoError(t, err)
+
+		page, err := staticStore.GetPluginsPage(&model.PluginFilter{
+			PerPage: 2,
+			Cursor:  "invalid",
+		})
+		require.Error(t, err)
+		require.Nil(t, page)
+	})
+}
+
+func TestStaticGetPluginsSort(t *testing.T) {
+	newPlugin := func(id, name, version, description string, updatedAt time.Time) *model.Plugin {
+		return &model.Plugin{
+			Manifest: &mattermostModel.Manifest{
+				Id:          id,
+				Name:        name,
+				Version:     version,
+				Description: description,
+			},
+			UpdatedAt: updatedAt,
+		}
+	}
+
+	day := func(d int) time.Time {
+		return time.Date(2020, time.January, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	jiraV1 := newPlugin("jira", "Jira", "1.0.0", "Atlassian Jira integration.", day(1))
+	jiraV2 := newPlugin("jira", "Jira", "2.0.0", "Atlassian Jira integration.", day(5))
+	github := newPlugin("github", "GitHub", "1.0.0", "Jira-like issue tracking with GitHub.", day(3))
+	autolink := newPlugin("mattermost-autolink", "Autolink", "1.0.0", "Automatically link Jira issues.", day(4))
+	jiraServer := newPlugin("jira-server", "Jira Server", "1.0.0", "For Jira Server.", day(2))
+
+	logger := testlib.MakeLogger(t)
+	staticStore, err := NewStatic([]*model.Plugin{jiraV1, jiraV2, github, autolink, jiraServer}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		filter   *model.PluginFilter
+		expected []*model.Plugin
+	}{
+		"default is name ascending": {
+			filter:   &model.PluginFilter{},
+			expected: []*model.Plugin{autolink, github, jiraV2, jiraServer},
+		},
+		"name descending": {
+			filter:   &model.PluginFilter{Sort: model.SortByName, SortDirection: model.SortDescending},
+			expected: []*model.Plugin{jiraServer, jiraV2, github, autolink},
+		},
+		"id ascending, all versions": {
+			filter:   &model.PluginFilter{Sort: model.SortByID, ReturnAllVersions: true},
+			expected: []*model.Plugin{github, jiraV2, jiraV1, jiraServer, autolink},
+		},
+		"updated_at defaults to descending": {
+			filter:   &model.PluginFilter{Sort: model.SortByUpdatedAt, ReturnAllVersions: true},
+			expected: []*model.Plugin{jiraV2, autolink, github, jiraServer, jiraV1},
+		},
+		"updated_at ascending": {
+			filter:   &model.PluginFilter{Sort: model.SortByUpdatedAt, SortDirection: model.SortAscending},
+			expected: []*model.Plugin{jiraServer, github, autolink, jiraV2},
+		},
+		"relevance": {
+			filter:   &model.PluginFilter{Sort: model.SortByRelevance, Filter: "jira"},
+			expected: []*model.Plugin{jiraV2, jiraServer, autolink, github},
+		},
+		"searching defaults to relevance": {
+			filter:   &model.PluginFilter{Filter: "jira"},
+			expected: []*model.Plugin{jiraV2, jiraServer, autolink, github},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			testCase.filter.PerPage = model.AllPerPage
+
+			plugins, err := staticStore.GetPlugins(testCase.filter)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, plugins)
+		})
+
+		t.Run(name+", by cursor", func(t *testing.T) {
+			filter := *testCase.filter
+			filter.PerPage = 1
+
+			var plugins []*model.Plugin
+			for {
+				page, err := staticStore.GetPluginsPage(&filter)
+				require.NoError(t, err)
+				plugins = append(plugins, page.Plugins...)
+
+				if page.NextCursor == "" {
+					break
+				}
+				filter.Cursor = page.NextCursor
+			}
+			assert.Equal(t, testCase.expected, plugins)
+		})
+	}
+
+	t.Run("cursor for a different sort", func(t *testing.T) {
+		page, err := staticStore.GetPluginsPage(&model.PluginFilter{PerPage: 1})
+		require.NoError(t, err)
+		require.NotEmpty(t, page.NextCursor)
+
+		_, err = staticStore.GetPluginsPage(&model.PluginFilter{
+			PerPage: 1,
+			Sort:    model.SortByUpdatedAt,
+			Cursor:  page.NextCursor,
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestStaticGetPluginsSearch(t *testing.T) {
+	newPlugin := func(id, name, description string, keywords ...string) *model.Plugin {
+		return &model.Plugin{
+			Manifest: &mattermostModel.Manifest{
+				Id:          id,
+				Name:        name,
+				Version:     "1.0.0",
+				Description: description,
+			},
+			Keywords: keywords,
+		}
+	}
+
+	jenkins := newPlugin("jenkins", "Jenkins", "Jenkins plugin for Mattermost", "continuous integration")
+	zoom := newPlugin("zoom", "Zoom", "Zoom audio and video conferencing plugin for Mattermost.")
+	agenda := newPlugin("com.mattermost.agenda", "Agenda", "Plugin to handle meeting agendas for Mattermost channels.")
+	agenda.Localizations = model.Localizations{
+		"de": {Name: "Tagesordnung", Description: "Besprechungen in Kanälen vorbereiten."},
+		"ja": {Name: "議題", Description: "チャンネルの会議の議題を管理します。"},
+	}
+	beta := newPlugin("com.example.beta", "Example", "An example plugin.")
+	beta.ReleaseStage = model.Beta
+
+	logger := testlib.MakeLogger(t)
+	staticStore, err := NewStatic([]*model.Plugin{jenkins, zoom, agenda, beta}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+
+	// Labels are added to the returned copies.
+	labelledBeta := *beta
+	labelledBeta.AddLabels(model.DefaultLabelDefinitions)
+
+	testCases := map[string]struct {
+		filter   string
+		expected []*model.Plugin
+	}{
+		"stemming":                {"jenkin ci", []*model.Plugin{jenkins}},
+		"ranked by words matched": {"zoom meeting", []*model.Plugin{zoom, agenda}},
+		"typo":                    {"agneda", []*model.Plugin{agenda}},
+		"keywords":                {"integration", []*model.Plugin{jenkins}},
+		"labels":                  {"beta", []*model.Plugin{&labelledBeta}},
+		"localized name":          {"tagesordnung", []*model.Plugin{agenda}},
+		"localized description":   {"会議", []*model.Plugin{agenda}},
+		"no match":                {"gitlab", nil},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			plugins, err := staticStore.GetPlugins(&model.PluginFilter{
+				PerPage: model.AllPerPage,
+				Filter:  testCase.filter,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, plugins)
+		})
+	}
+
+	t.Run("configured labels", func(t *testing.T) {
+		approved := []*model.LabelDefinition{{
+			Label: model.Label{Name: "Approved by IT"},
+			When:  []*model.LabelCondition{{PluginIDs: []string{"com.example.*"}}},
+		}}
+		approvedStore, err := NewStatic([]*model.Plugin{jenkins, beta}, approved, logger)
+		require.NoError(t, err)
+
+		plugins, err := approvedStore.GetPlugins(&model.PluginFilter{
+			PerPage: model.AllPerPage,
+			Filter:  "approved",
+		})
+		require.NoError(t, err)
+		require.Len(t, plugins, 1)
+		assert.Equal(t, []model.Label{{Name: "Approved by IT"}}, plugins[0].Labels)
+
+		plugins, err = staticStore.GetPlugins(&model.PluginFilter{
+			PerPage: model.AllPerPage,
+			Filter:  "approved",
+		})
+		require.NoError(t, err)
+		assert.Empty(t, plugins)
+	})
+}
+
+func TestStaticGetPluginsFacets(t *testing.T) {
+	newPlugin := func(id string, authorType model.AuthorType, releaseStage model.ReleaseStage, hosting model.HostingType, labels ...model.Label) *model.Plugin {
+		return &model.Plugin{
+			Manifest: &mattermostModel.Manifest{
+				Id:      id,
+				Name:    id,
+				Version: "1.0.0",
+			},
+			AuthorType:   authorType,
+			ReleaseStage: releaseStage,
+			Hosting:      hosting,
+			Labels:       labels,
+		}
+	}
+
+	core := newPlugin("core", model.Mattermost, model.Production, "")
+	partner := newPlugin("partner", model.Partner, model.Production, "", model.PartnerLabel)
+	community := newPlugin("community", model.Community, model.Beta, model.OnPrem, model.CommunityLabel, model.BetaLabel)
+	experiment := newPlugin("experiment", model.Community, model.Experimental, "", model.CommunityLabel, model.ExperimentalLabel)
+
+	logger := testlib.MakeLogger(t)
+	staticStore, err := NewStatic([]*model.Plugin{core, partner, community, experiment}, model.DefaultLabelDefinitions, logger)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		filter   *model.PluginFilter
+		expected []*model.Plugin
+	}{
+		"no facets": {
+			filter:   &model.PluginFilter{},
+			expected: []*model.Plugin{community, core, experiment, partner},
+		},
+		"single author type": {
+			filter:   &model.PluginFilter{AuthorTypes: []model.AuthorType{model.Community}},
+			expected: []*model.Plugin{community, experiment},
+		},
+		"multiple author types": {
+			filter:   &model.PluginFilter{AuthorTypes: []model.AuthorType{model.Mattermost, model.Partner}},
+			expected: []*model.Plugin{core, partner},
+		},
+		"release stage": {
+			filter:   &model.PluginFilter{ReleaseStages: []model.ReleaseStage{model.Production}},
+			expected: []*model.Plugin{core, partner},
+		},
+		"hosting includes unrestricted plugins": {
+			filter:   &model.PluginFilter{Cloud: true, Hosting: []model.HostingType{model.Cloud}},
+			expected: []*model.Plugin{core, experiment, partner},
+		},
+		"label, case-insensitive": {
+			filter:   &model.PluginFilter{Labels: []string{"beta", "EXPERIMENTAL"}},
+			expected: []*model.Plugin{community, experiment},
+		},
+		"facets combine": {
+			filter: &model.PluginFilter{
+				AuthorTypes: []model.AuthorType{model.Community},
+				Hosting:     []model.HostingType{model.OnPrem},
+			},
+			expected: []*model.Plugin{community, experiment},
+		},
+		"no match": {
+			filter: &model.PluginFilter{
+				AuthorTypes:   []model.AuthorType{model.Partner},
+				ReleaseStages: []model.ReleaseStage{model.Beta},
+			},
+			expected: nil,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			testCase.filter.PerPage = model.AllPerPage
+
+			plugins, err := staticStore.GetPlugins(testCase.filter)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, plugins)
+		})
+	}
+
+	t.Run("counts", func(t *testing.T) {
+		withPlatforms := &model.Plugin{}
+		*withPlatforms = *core
+		withPlatforms.Platforms.LinuxAmd64.DownloadURL = "https://example.com/core-linux-amd64.tar.gz"
+		withPlatforms.Platforms.DarwinAmd64.DownloadURL = "https://example.com/core-darwin-amd64.tar.gz"
+
+		staticStore, err := NewStatic([]*model.Plugin{withPlatforms, partner, community, experiment}, model.DefaultLabelDefinitions, logger)
+		require.NoError(t, err)
+
+		page, err := staticStore.GetPluginsPage(&model.PluginFilter{
+			PerPage:     1,
+			Facets:      true,
+			AuthorTypes: []model.AuthorType{model.Community},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, page.Total)
+		require.NotNil(t, page.Facets)
+
+		// The author type facet ignores its own constraint, while every other facet honours it.
+		assert.Equal(t, map[model.AuthorType]int{model.Mattermost: 1, model.Partner: 1, model.Community: 2}, page.Facets.AuthorType)
+		assert.Equal(t, map[model.ReleaseStage]int{model.Beta: 1, model.Experimental: 1}, page.Facets.ReleaseStage)
+		assert.Equal(t, map[model.HostingType]int{model.OnPrem: 2, model.Cloud: 1}, page.Facets.Hosting)
+		assert.Equal(t, map[string]int{"Community": 2, "Beta": 1, "Experimental": 1}, page.Facets.Label)
+		assert.Empty(t, page.Facets.Platform)
+
+		page, err = staticStore.GetPluginsPage(&model.PluginFilter{
+			PerPage: 0,
+			Facets:  true,
+			Filter:  "core",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, page.Total)
+		assert.Equal(t, map[model.AuthorType]int{model.Mattermost: 1}, page.Facets.AuthorType)
+		assert.Equal(t, map[string]int{model.LinuxAmd64: 1, model.DarwinAmd64: 1}, page.Facets.Platform)
+	})
+
+	t.Run("counts not requested", func(t *testing.T) {
+		page, err := staticStore.GetPluginsPage(&model.PluginFilter{PerPage: 1})
+		require.NoError(t, err)
+		assert.Nil(t, page.Facets)
+	})
+}
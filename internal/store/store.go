@@ -5,4 +5,28 @@ import "github.com/mattermost/mattermost-marketplace/internal/model"
 // Store describes the interface to the backing store.
 type Store interface {
 	GetPlugins(filter *model.PluginFilter) ([]*model.Plugin, error)
+	GetPluginsPage(filter *model.PluginFilter) (*model.PluginsPage, error)
+}
+
+// Revisioner describes a store able to identify the current revision of its catalog.
+type Revisioner interface {
+	Revision() string
+}
+
+// CompatibilityExplainer describes a store able to explain which versions of a plugin match a
+// filter, and why.
+type CompatibilityExplainer interface {
+	GetPluginCompatibility(filter *model.PluginFilter) (*model.PluginCompatibility, error)
+}
+
+// WritableStore describes a store whose catalog may be changed, such as by an administrator.
+//
+// Plugins are identified by their manifest id and version. Adding a version already in the
+// catalog fails with model.ErrPluginExists, and updating or deleting a version not in the catalog
+// fails with model.ErrPluginNotFound.
+type WritableStore interface {
+	Store
+	AddPlugin(plugin *model.Plugin) error
+	UpdatePlugin(plugin *model.Plugin) error
+	DeletePlugin(pluginID, version string) error // Deletes every version if version is empty
 }
@@ -0,0 +1,42 @@
+package testlib
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// MakeBundle creates a gzipped plugin bundle containing the given files, keyed by their path
+// within the leading folder named after the plugin id.
+func MakeBundle(tb testing.TB, pluginID string, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		err := tarWriter.WriteHeader(&tar.Header{
+			Name: pluginID + "/" + path,
+			Mode: 0644,
+			Size: int64(len(files[path])),
+		})
+		require.NoError(tb, err)
+
+		_, err = tarWriter.Write([]byte(files[path]))
+		require.NoError(tb, err)
+	}
+
+	require.NoError(tb, tarWriter.Close())
+	require.NoError(tb, gzipWriter.Close())
+
+	return buf.Bytes()
+}
@@ -0,0 +1,114 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// Store describes the interface to the catalog being watched.
+type Store interface {
+	GetPlugins(filter *model.PluginFilter) ([]*model.Plugin, error)
+}
+
+// Watcher periodically snapshots a catalog, sending a payload whenever it changes.
+type Watcher struct {
+	store  Store
+	sender *Sender
+	logger logrus.FieldLogger
+
+	previous []*model.Plugin
+}
+
+// NewWatcher creates a watcher of the given store, announcing changes through the given sender.
+func NewWatcher(store Store, sender *Sender, logger logrus.FieldLogger) *Watcher {
+	return &Watcher{
+		store:  store,
+		sender: sender,
+		logger: logger,
+	}
+}
+
+// Run polls the store at the given interval until the context is cancelled. The catalog is first
+// snapshotted immediately, without announcing the plugins it already contains.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := w.Poll(ctx)
+		if err != nil {
+			w.logger.WithError(err).Error("Failed to announce catalog changes")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll snapshots the catalog, sending a payload describing any changes since the previous poll.
+// The first poll only records the catalog.
+//
+// The snapshot is retained even if delivery fails, such that a change is announced at most once.
+func (w *Watcher) Poll(ctx context.Context) error {
+	current, err := snapshot(w.store)
+	if err != nil {
+		return err
+	}
+
+	previous := w.previous
+	w.previous = current
+	if previous == nil {
+		return nil
+	}
+
+	change := model.DiffCatalogs(previous, current)
+	if change.IsEmpty() {
+		return nil
+	}
+
+	w.logger.WithFields(logrus.Fields{
+		"added":         len(change.Added),
+		"removed":       len(change.Removed),
+		"label_changes": len(change.LabelChanges),
+	}).Info("Catalog changed")
+
+	err = w.sender.Send(ctx, NewCatalogChangedPayload(change))
+	if err != nil {
+		return errors.Wrap(err, "failed to send webhook")
+	}
+
+	return nil
+}
+
+// snapshot returns every version of every plugin in the store, regardless of compatibility,
+// including yanked versions.
+//
+// Plugins restricted to a hosting type are only returned when querying for that hosting type, so
+// the catalog is queried both as an on-prem and as a cloud server.
+func snapshot(store Store) ([]*model.Plugin, error) {
+	plugins := []*model.Plugin{}
+	for _, cloud := range []bool{false, true} {
+		hostingPlugins, err := store.GetPlugins(&model.PluginFilter{
+			PerPage:           model.AllPerPage,
+			EnterprisePlugins: true,
+			Cloud:             cloud,
+			ReturnAllVersions: true,
+			IncludeYanked:     true,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to snapshot catalog")
+		}
+
+		plugins = append(plugins, hostingPlugins...)
+	}
+
+	return plugins, nil
+}
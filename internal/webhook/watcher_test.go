@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	mattermostModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/store"
+	"github.com/mattermost/mattermost-marketplace/internal/testlib"
+)
+
+func TestWatcher(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	newPlugin := func(id, version string) *model.Plugin {
+		return &model.Plugin{
+			Manifest: &mattermostModel.Manifest{Id: id, Name: id, Version: version},
+		}
+	}
+
+	demoV1 := newPlugin("demo", "1.0.0")
+	demoV2 := newPlugin("demo", "2.0.0")
+	cloudOnly := newPlugin("cloud", "1.0.0")
+	cloudOnly.Hosting = model.Cloud
+	enterprise := newPlugin("enterprise", "1.0.0")
+	enterprise.Enterprise = true
+
+	catalog := []*model.Plugin{demoV1, cloudOnly, enterprise}
+	reloadable, err := store.NewReloadable(func() (store.Store, error) {
+		return store.NewStatic(catalog, model.DefaultLabelDefinitions, logger)
+	})
+	require.NoError(t, err)
+
+	r := newReceiver(t)
+	watcher := NewWatcher(reloadable, newTestSender(t, r.URL), logger)
+
+	// The first poll only records the catalog.
+	require.NoError(t, watcher.Poll(context.Background()))
+	require.Empty(t, r.deliveries)
+
+	t.Run("unchanged catalog", func(t *testing.T) {
+		require.NoError(t, watcher.Poll(context.Background()))
+		require.Empty(t, r.deliveries)
+	})
+
+	t.Run("changed catalog", func(t *testing.T) {
+		betaCloud := *cloudOnly
+		betaCloud.ReleaseStage = model.Beta
+		catalog = []*model.Plugin{demoV1, demoV2, &betaCloud}
+		require.NoError(t, reloadable.Reload())
+
+		require.NoError(t, watcher.Poll(context.Background()))
+		require.Len(t, r.deliveries, 1)
+
+		var payload Payload
+		require.NoError(t, json.Unmarshal(r.bodies[0], &payload))
+		assert.Equal(t, EventCatalogChanged, payload.Event)
+
+		require.Len(t, payload.Added, 1)
+		assert.Equal(t, "demo", payload.Added[0].Manifest.Id)
+		assert.Equal(t, "2.0.0", payload.Added[0].Manifest.Version)
+		assert.Equal(t, []*model.PluginVersion{{PluginID: "enterprise", Version: "1.0.0"}}, payload.Removed)
+		assert.Equal(t, []*model.LabelChange{{
+			PluginVersion: model.PluginVersion{PluginID: "cloud", Version: "1.0.0"},
+			Added:         []string{"Beta"},
+			Removed:       []string{},
+		}}, payload.LabelChanges)
+
+		// The change is only announced once.
+		require.NoError(t, watcher.Poll(context.Background()))
+		require.Len(t, r.deliveries, 1)
+	})
+}
@@ -0,0 +1,199 @@
+// Package webhook notifies remote receivers of changes to the catalog of plugins.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	mathrand "math/rand"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+)
+
+// Headers sent with every delivery.
+const (
+	// HeaderEvent names the event being delivered.
+	HeaderEvent = "X-Marketplace-Event"
+	// HeaderDelivery uniquely identifies the payload, and is repeated when retrying a delivery.
+	HeaderDelivery = "X-Marketplace-Delivery"
+	// HeaderSignature carries the HMAC-SHA256 of the body, keyed by the shared secret, formatted
+	// as sha256=<hex>. It is omitted if no secret is configured.
+	HeaderSignature = "X-Marketplace-Signature"
+)
+
+// EventCatalogChanged is sent whenever versions are added to or removed from the catalog, or
+// their labels change.
+const EventCatalogChanged = "catalog.changed"
+
+// Payload is the JSON body of a delivery.
+type Payload struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	Timestamp time.Time `json:"timestamp"`
+	*model.CatalogChange
+}
+
+// NewCatalogChangedPayload creates a payload announcing the given change to the catalog.
+func NewCatalogChangedPayload(change *model.CatalogChange) *Payload {
+	return &Payload{
+		ID:            newDeliveryID(),
+		Event:         EventCatalogChanged,
+		Timestamp:     time.Now().UTC(),
+		CatalogChange: change,
+	}
+}
+
+// newDeliveryID generates a random identifier for a payload.
+func newDeliveryID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
+// Sign computes the signature of the given body, as sent in the HeaderSignature header.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the given signature of the body was computed using the secret. Receivers
+// should verify every delivery before trusting its payload.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Sender delivers payloads to a fixed set of webhook URLs.
+type Sender struct {
+	urls       []string
+	secret     string
+	httpClient *http.Client
+	logger     logrus.FieldLogger
+
+	// MaxAttempts is the number of times delivery to each URL is attempted before giving up.
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubling with each subsequent retry.
+	Backoff time.Duration
+}
+
+// NewSender creates a sender delivering to the given URLs, signing each payload with the given
+// secret unless empty.
+func NewSender(urls []string, secret string, logger logrus.FieldLogger) *Sender {
+	return &Sender{
+		urls:        urls,
+		secret:      secret,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		logger:      logger,
+		MaxAttempts: 5,
+		Backoff:     time.Second,
+	}
+}
+
+// Send delivers the given payload to every URL concurrently, retrying failed deliveries. An error
+// is returned if delivery to any URL ultimately failed.
+func (s *Sender) Send(ctx context.Context, payload *Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode payload")
+	}
+
+	var g errgroup.Group
+	for _, url := range s.urls {
+		url := url
+		g.Go(func() error {
+			err := s.deliver(ctx, url, payload, body)
+			if err != nil {
+				return errors.Wrapf(err, "failed to deliver to %s", url)
+			}
+
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
+
+// deliver posts the given body to a single URL, retrying with exponential backoff until it is
+// accepted, rejected outright or the attempts are exhausted.
+func (s *Sender) deliver(ctx context.Context, url string, payload *Payload, body []byte) error {
+	logger := s.logger.WithFields(logrus.Fields{
+		"url":      url,
+		"delivery": payload.ID,
+	})
+
+	var err error
+	for attempt := 1; attempt <= s.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			delay := s.Backoff << (attempt - 2)
+			delay += time.Duration(mathrand.Int63n(int64(delay)/2 + 1))
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		var retry bool
+		retry, err = s.post(ctx, url, payload, body)
+		if err == nil {
+			logger.WithField("attempt", attempt).Debug("Delivered webhook")
+			return nil
+		}
+		if !retry {
+			return err
+		}
+
+		logger.WithError(err).WithField("attempt", attempt).Warn("Failed to deliver webhook")
+	}
+
+	return errors.Wrapf(err, "giving up after %d attempts", s.MaxAttempts)
+}
+
+// post makes a single delivery attempt, returning whether a failed attempt should be retried.
+func (s *Sender) post(ctx context.Context, url string, payload *Payload, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Mattermost-Marketplace-Webhook")
+	req.Header.Set(HeaderEvent, payload.Event)
+	req.Header.Set(HeaderDelivery, payload.ID)
+	if s.secret != "" {
+		req.Header.Set(HeaderSignature, Sign(s.secret, body))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, errors.Wrap(err, "failed to send request")
+	}
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	// Retry server errors and throttling, but not a receiver rejecting the payload itself.
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout
+
+	return retry, fmt.Errorf("received status %d", resp.StatusCode)
+}
@@ -0,0 +1,147 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-marketplace/internal/model"
+	"github.com/mattermost/mattermost-marketplace/internal/testlib"
+)
+
+// receiver is a local webhook receiver recording the deliveries it accepts.
+type receiver struct {
+	*httptest.Server
+
+	lock       sync.Mutex
+	statuses   []int // The statuses with which to respond to successive requests, then 200
+	requests   int
+	deliveries []*http.Request
+	bodies     [][]byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	r := &receiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		r.lock.Lock()
+		defer r.lock.Unlock()
+
+		r.requests++
+		if len(r.statuses) > 0 {
+			status := r.statuses[0]
+			r.statuses = r.statuses[1:]
+			w.WriteHeader(status)
+			return
+		}
+
+		r.deliveries = append(r.deliveries, req)
+		r.bodies = append(r.bodies, body)
+	}))
+	t.Cleanup(r.Close)
+
+	return r
+}
+
+func newTestSender(t *testing.T, urls ...string) *Sender {
+	sender := NewSender(urls, "secret", testlib.MakeLogger(t))
+	sender.Backoff = time.Millisecond
+
+	return sender
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"catalog.changed"}`)
+	signature := Sign("secret", body)
+
+	assert.Regexp(t, "^sha256=[0-9a-f]{64}$", signature)
+	assert.True(t, Verify("secret", body, signature))
+	assert.False(t, Verify("other", body, signature))
+	assert.False(t, Verify("secret", []byte(`{}`), signature))
+}
+
+func TestSender(t *testing.T) {
+	payload := NewCatalogChangedPayload(&model.CatalogChange{
+		Added:        []*model.Plugin{},
+		Removed:      []*model.PluginVersion{{PluginID: "demo", Version: "1.0.0"}},
+		LabelChanges: []*model.LabelChange{},
+	})
+
+	t.Run("signed delivery to every url", func(t *testing.T) {
+		receiver1 := newReceiver(t)
+		receiver2 := newReceiver(t)
+
+		err := newTestSender(t, receiver1.URL, receiver2.URL).Send(context.Background(), payload)
+		require.NoError(t, err)
+
+		for _, r := range []*receiver{receiver1, receiver2} {
+			require.Len(t, r.deliveries, 1)
+			delivery := r.deliveries[0]
+			assert.Equal(t, "application/json", delivery.Header.Get("Content-Type"))
+			assert.Equal(t, EventCatalogChanged, delivery.Header.Get(HeaderEvent))
+			assert.Equal(t, payload.ID, delivery.Header.Get(HeaderDelivery))
+			assert.True(t, Verify("secret", r.bodies[0], delivery.Header.Get(HeaderSignature)))
+
+			var received Payload
+			require.NoError(t, json.Unmarshal(r.bodies[0], &received))
+			assert.Equal(t, payload.ID, received.ID)
+			assert.Equal(t, EventCatalogChanged, received.Event)
+			assert.Equal(t, payload.Removed, received.Removed)
+		}
+	})
+
+	t.Run("unsigned without secret", func(t *testing.T) {
+		r := newReceiver(t)
+
+		sender := NewSender([]string{r.URL}, "", testlib.MakeLogger(t))
+		require.NoError(t, sender.Send(context.Background(), payload))
+		require.Len(t, r.deliveries, 1)
+		assert.Empty(t, r.deliveries[0].Header.Get(HeaderSignature))
+	})
+
+	t.Run("retries server errors", func(t *testing.T) {
+		r := newReceiver(t, http.StatusInternalServerError, http.StatusTooManyRequests)
+
+		err := newTestSender(t, r.URL).Send(context.Background(), payload)
+		require.NoError(t, err)
+		assert.Equal(t, 3, r.requests)
+		assert.Len(t, r.deliveries, 1)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		r := newReceiver(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+
+		sender := newTestSender(t, r.URL)
+		sender.MaxAttempts = 2
+		err := sender.Send(context.Background(), payload)
+		require.Error(t, err)
+		assert.Equal(t, 2, r.requests)
+	})
+
+	t.Run("does not retry rejected payloads", func(t *testing.T) {
+		r := newReceiver(t, http.StatusBadRequest)
+
+		err := newTestSender(t, r.URL).Send(context.Background(), payload)
+		require.Error(t, err)
+		assert.Equal(t, 1, r.requests)
+	})
+
+	t.Run("one failing url does not prevent others", func(t *testing.T) {
+		failing := newReceiver(t, http.StatusBadRequest)
+		succeeding := newReceiver(t)
+
+		err := newTestSender(t, failing.URL, succeeding.URL).Send(context.Background(), payload)
+		require.Error(t, err)
+		assert.Len(t, succeeding.deliveries, 1)
+	})
+}